@@ -0,0 +1,234 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/traffic"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CanaryRollout struct {
+	Id              int64   `json:"id"`
+	KubeConfigId    int64   `json:"kubeConfigId"`
+	Cid             string  `json:"cid"`
+	Namespace       string  `json:"namespace"`
+	Service         string  `json:"service"`
+	VersionLabel    string  `json:"versionLabel"`
+	StableVersion   string  `json:"stableVersion"`
+	CanaryVersion   string  `json:"canaryVersion"`
+	VirtualService  string  `json:"virtualService"`
+	DestinationRule string  `json:"destinationRule"`
+	Steps           []int   `json:"steps"`
+	StepInterval    int64   `json:"stepInterval"`
+	MaxErrorRate    float64 `json:"maxErrorRate"`
+	MaxP99Latency   float64 `json:"maxP99Latency"`
+	CurrentStep     int     `json:"currentStep"`
+	CanaryWeight    int     `json:"canaryWeight"`
+	PreviousWeight  int     `json:"previousWeight"`
+	Status          string  `json:"status"`
+	Message         string  `json:"message"`
+	NextCheckTime   int64   `json:"nextCheckTime"`
+	CreateTime      int64   `json:"createTime"`
+	UpdateTime      int64   `json:"updateTime"`
+}
+
+// StartCanaryRequest versionLabel 只支持version(默认值), stepInterval 单位为秒, maxErrorRate 为0-1的比例, maxP99Latency 单位为毫秒, 阈值为0时不检查
+type StartCanaryRequest struct {
+	Id            int64   `json:"id" binding:"required"`
+	Namespace     string  `json:"namespace" binding:"required"`
+	Service       string  `json:"service" binding:"required"`
+	VersionLabel  string  `json:"versionLabel"`
+	StableVersion string  `json:"stableVersion" binding:"required"`
+	CanaryVersion string  `json:"canaryVersion" binding:"required"`
+	Steps         []int   `json:"steps" binding:"required"`
+	StepInterval  int64   `json:"stepInterval" binding:"required"`
+	MaxErrorRate  float64 `json:"maxErrorRate"`
+	MaxP99Latency float64 `json:"maxP99Latency"`
+}
+
+func newCanaryRollout(rollout *model.CanaryRollout) CanaryRollout {
+	return CanaryRollout{
+		Id:              rollout.Id,
+		KubeConfigId:    rollout.KubeConfigId,
+		Cid:             rollout.Cid,
+		Namespace:       rollout.Namespace,
+		Service:         rollout.Service,
+		VersionLabel:    rollout.VersionLabel,
+		StableVersion:   rollout.StableVersion,
+		CanaryVersion:   rollout.CanaryVersion,
+		VirtualService:  rollout.VirtualService,
+		DestinationRule: rollout.DestinationRule,
+		Steps:           traffic.ParseSteps(rollout.Steps),
+		StepInterval:    rollout.StepInterval,
+		MaxErrorRate:    rollout.MaxErrorRate,
+		MaxP99Latency:   rollout.MaxP99Latency,
+		CurrentStep:     rollout.CurrentStep,
+		CanaryWeight:    rollout.CanaryWeight,
+		PreviousWeight:  rollout.PreviousWeight,
+		Status:          rollout.Status,
+		Message:         rollout.Message,
+		NextCheckTime:   rollout.NextCheckTime,
+		CreateTime:      rollout.CreateTime,
+		UpdateTime:      rollout.UpdateTime,
+	}
+}
+
+// responseRolloutError 状态不允许的操作返回409, 发布不存在返回404, 其余为k8s或数据库的错误
+func responseRolloutError(ctx *gin.Context, err error) {
+	var statusErr *traffic.RolloutStatusError
+	switch {
+	case errors.As(err, &statusErr), errors.Is(err, traffic.ErrRolloutActive):
+		ResponseError(ctx, http.StatusConflict, err)
+	case errors.Is(err, model.CanaryRolloutNoExistErr):
+		ResponseError(ctx, http.StatusNotFound, err)
+	default:
+		ResponseKubeError(ctx, err)
+	}
+}
+
+// StartCanary
+// @Description 为服务创建两个版本的subset(按pod的version标签区分), 按steps逐步把流量切到金丝雀版本; 每一步观察stepInterval秒, 通过kiali检查金丝雀版本的错误率和p99延迟, 超过阈值时自动回滚到上一步的权重
+// @Summary  开始金丝雀发布
+// @Tags 	traffic
+// @Param	body		body		StartCanaryRequest		true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/canary/start [post]
+func StartCanary(ctx *gin.Context) {
+	req := StartCanaryRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	config := traffic.CanaryConfig{
+		Namespace:     req.Namespace,
+		Service:       req.Service,
+		VersionLabel:  req.VersionLabel,
+		StableVersion: req.StableVersion,
+		CanaryVersion: req.CanaryVersion,
+		Steps:         req.Steps,
+		StepInterval:  time.Duration(req.StepInterval) * time.Second,
+		MaxErrorRate:  req.MaxErrorRate,
+		MaxP99Latency: req.MaxP99Latency,
+	}
+	if err := config.Validate(); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(req.Id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	rollout, err := traffic.StartCanary(kubeConfig, config)
+	if err != nil {
+		responseRolloutError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, newCanaryRollout(rollout))
+}
+
+// ListCanaries
+// @Description 获取集群的金丝雀发布列表, 按创建时间倒序
+// @Summary  获取金丝雀发布列表
+// @Tags 	traffic
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"namespace"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/canary/list [get]
+func ListCanaries(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	rollouts, err := model.CanaryRolloutDB.ListCanaryRollout(id, ctx.Query("namespace"))
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	rolloutRsp := make([]CanaryRollout, 0)
+	for i := range *rollouts {
+		rolloutRsp = append(rolloutRsp, newCanaryRollout(&(*rollouts)[i]))
+	}
+	ResponseData(ctx, CodeSuccess, rolloutRsp)
+}
+
+// GetCanary
+// @Description 获取金丝雀发布的当前步骤、权重及最近一次检查的结果
+// @Summary  获取金丝雀发布
+// @Tags 	traffic
+// @Param	rolloutId	query		int64		true		"rolloutId"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/canary/get [get]
+func GetCanary(ctx *gin.Context) {
+	rolloutIdStr := ctx.Query("rolloutId")
+	rolloutId, err := strconv.ParseInt(rolloutIdStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	rollout, err := model.CanaryRolloutDB.GetCanaryRolloutById(rolloutId)
+	if err != nil {
+		responseRolloutError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, newCanaryRollout(rollout))
+}
+
+// PauseCanary
+// @Description 暂停运行中的金丝雀发布, 保持当前权重, 不再检查指标
+// @Summary  暂停金丝雀发布
+// @Tags 	traffic
+// @Param	rolloutId	query		int64		true		"rolloutId"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/canary/pause [post]
+func PauseCanary(ctx *gin.Context) {
+	changeCanary(ctx, traffic.PauseCanary)
+}
+
+// ResumeCanary
+// @Description 恢复暂停的金丝雀发布, 重新观察一个周期后推进到下一步
+// @Summary  恢复金丝雀发布
+// @Tags 	traffic
+// @Param	rolloutId	query		int64		true		"rolloutId"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/canary/resume [post]
+func ResumeCanary(ctx *gin.Context) {
+	changeCanary(ctx, traffic.ResumeCanary)
+}
+
+// AbortCanary
+// @Description 终止运行中或暂停的金丝雀发布, 所有流量切回稳定版本
+// @Summary  终止金丝雀发布
+// @Tags 	traffic
+// @Param	rolloutId	query		int64		true		"rolloutId"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/canary/abort [post]
+func AbortCanary(ctx *gin.Context) {
+	changeCanary(ctx, traffic.AbortCanary)
+}
+
+func changeCanary(ctx *gin.Context, change func(id int64) (*model.CanaryRollout, error)) {
+	rolloutIdStr := ctx.Query("rolloutId")
+	rolloutId, err := strconv.ParseInt(rolloutIdStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	rollout, err := change(rolloutId)
+	if err != nil {
+		responseRolloutError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, newCanaryRollout(rollout))
+}
@@ -6,4 +6,6 @@ const (
 	CodeDbError = 1
 
 	CodeKubeConnectError = 2
+
+	CodeKubeApiError = 3
 )
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	"github.com/gin-gonic/gin"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var destinationRuleHandler = &resourceHandler{
+	gvk:       istio.GVKDestinationRule,
+	newObject: func() runtime.Object { return &v1alpha3.DestinationRule{} },
+	client: istioResourceClient(func(cli *istio.IstioClient) *resourceClient {
+		destinationRule := istio.NewDestinationRule(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				destinationRules, err := destinationRule.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(destinationRules))
+				for _, dr := range destinationRules {
+					objects = append(objects, dr)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return destinationRule.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return destinationRule.Create(obj.(*v1alpha3.DestinationRule), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return destinationRule.Update(obj.(*v1alpha3.DestinationRule), dryRun)
+			},
+			delete: destinationRule.Delete,
+		}
+	}),
+}
+
+// ListDestinationRules
+// @Description 获取命名空间下的DestinationRule, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取DestinationRule列表
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/destinationrule/list [get]
+func ListDestinationRules(ctx *gin.Context) {
+	destinationRuleHandler.list(ctx)
+}
+
+// GetDestinationRule
+// @Description 获取DestinationRule, format为yaml时返回yaml字符串
+// @Summary  获取DestinationRule
+// @Tags 	istio
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/destinationrule/get [get]
+func GetDestinationRule(ctx *gin.Context) {
+	destinationRuleHandler.get(ctx)
+}
+
+// CreateDestinationRule
+// @Description 创建DestinationRule, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建DestinationRule
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"DestinationRule"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/destinationrule/create [post]
+func CreateDestinationRule(ctx *gin.Context) {
+	destinationRuleHandler.create(ctx)
+}
+
+// UpdateDestinationRule
+// @Description 更新DestinationRule, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新DestinationRule
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"DestinationRule"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/destinationrule/update [post]
+func UpdateDestinationRule(ctx *gin.Context) {
+	destinationRuleHandler.update(ctx)
+}
+
+// DeleteDestinationRule
+// @Description 删除DestinationRule, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除DestinationRule
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/destinationrule/delete [post]
+func DeleteDestinationRule(ctx *gin.Context) {
+	destinationRuleHandler.delete(ctx)
+}
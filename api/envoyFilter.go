@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	"github.com/gin-gonic/gin"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var envoyFilterHandler = &resourceHandler{
+	gvk:       istio.GVKEnvoyFilter,
+	newObject: func() runtime.Object { return &v1alpha3.EnvoyFilter{} },
+	client: istioResourceClient(func(cli *istio.IstioClient) *resourceClient {
+		envoyFilter := istio.NewEnvoyFilter(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				envoyFilters, err := envoyFilter.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(envoyFilters))
+				for _, ef := range envoyFilters {
+					objects = append(objects, ef)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return envoyFilter.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return envoyFilter.Create(obj.(*v1alpha3.EnvoyFilter), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return envoyFilter.Update(obj.(*v1alpha3.EnvoyFilter), dryRun)
+			},
+			delete: envoyFilter.Delete,
+		}
+	}),
+}
+
+// ListEnvoyFilters
+// @Description 获取命名空间下的EnvoyFilter, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取EnvoyFilter列表
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/envoyfilter/list [get]
+func ListEnvoyFilters(ctx *gin.Context) {
+	envoyFilterHandler.list(ctx)
+}
+
+// GetEnvoyFilter
+// @Description 获取EnvoyFilter, format为yaml时返回yaml字符串
+// @Summary  获取EnvoyFilter
+// @Tags 	istio
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/envoyfilter/get [get]
+func GetEnvoyFilter(ctx *gin.Context) {
+	envoyFilterHandler.get(ctx)
+}
+
+// CreateEnvoyFilter
+// @Description 创建EnvoyFilter, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建EnvoyFilter
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"EnvoyFilter"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/envoyfilter/create [post]
+func CreateEnvoyFilter(ctx *gin.Context) {
+	envoyFilterHandler.create(ctx)
+}
+
+// UpdateEnvoyFilter
+// @Description 更新EnvoyFilter, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新EnvoyFilter
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"EnvoyFilter"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/envoyfilter/update [post]
+func UpdateEnvoyFilter(ctx *gin.Context) {
+	envoyFilterHandler.update(ctx)
+}
+
+// DeleteEnvoyFilter
+// @Description 删除EnvoyFilter, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除EnvoyFilter
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/envoyfilter/delete [post]
+func DeleteEnvoyFilter(ctx *gin.Context) {
+	envoyFilterHandler.delete(ctx)
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	"github.com/shuxnhs/istio-dashboard/domain/traffic"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FaultExperiment struct {
+	Id                    int64            `json:"id"`
+	KubeConfigId          int64            `json:"kubeConfigId"`
+	Cid                   string           `json:"cid"`
+	Namespace             string           `json:"namespace"`
+	Service               string           `json:"service"`
+	VirtualService        string           `json:"virtualService"`
+	CreatedVirtualService bool             `json:"createdVirtualService"`
+	Match                 istio.RouteMatch `json:"match"`
+	Percentage            float64          `json:"percentage"`
+	Delay                 int64            `json:"delay"`
+	AbortStatus           int              `json:"abortStatus"`
+	Ttl                   int64            `json:"ttl"`
+	Status                string           `json:"status"`
+	Message               string           `json:"message"`
+	ExpireTime            int64            `json:"expireTime"`
+	CreateTime            int64            `json:"createTime"`
+	UpdateTime            int64            `json:"updateTime"`
+}
+
+// StartFaultRequest match 为空时作用于服务的所有请求, percentage 为0-100, delay 单位为毫秒, ttl 单位为秒
+type StartFaultRequest struct {
+	Id          int64            `json:"id" binding:"required"`
+	Namespace   string           `json:"namespace" binding:"required"`
+	Service     string           `json:"service" binding:"required"`
+	Match       istio.RouteMatch `json:"match"`
+	Percentage  float64          `json:"percentage" binding:"required"`
+	Delay       int64            `json:"delay"`
+	AbortStatus int              `json:"abortStatus"`
+	Ttl         int64            `json:"ttl" binding:"required"`
+}
+
+func newFaultExperiment(experiment *model.FaultExperiment) FaultExperiment {
+	return FaultExperiment{
+		Id:                    experiment.Id,
+		KubeConfigId:          experiment.KubeConfigId,
+		Cid:                   experiment.Cid,
+		Namespace:             experiment.Namespace,
+		Service:               experiment.Service,
+		VirtualService:        experiment.VirtualService,
+		CreatedVirtualService: experiment.CreatedVirtualService,
+		Match:                 traffic.ParseRouteMatch(experiment.RouteMatch),
+		Percentage:            experiment.Percentage,
+		Delay:                 experiment.Delay,
+		AbortStatus:           experiment.AbortStatus,
+		Ttl:                   experiment.Ttl,
+		Status:                experiment.Status,
+		Message:               experiment.Message,
+		ExpireTime:            experiment.ExpireTime,
+		CreateTime:            experiment.CreateTime,
+		UpdateTime:            experiment.UpdateTime,
+	}
+}
+
+// responseExperimentError 状态不允许的操作返回409, 实验不存在返回404, 其余为k8s或数据库的错误
+func responseExperimentError(ctx *gin.Context, err error) {
+	var statusErr *traffic.ExperimentStatusError
+	switch {
+	case errors.As(err, &statusErr), errors.Is(err, traffic.ErrExperimentActive):
+		ResponseError(ctx, http.StatusConflict, err)
+	case errors.Is(err, model.FaultExperimentNoExistErr):
+		ResponseError(ctx, http.StatusNotFound, err)
+	default:
+		ResponseKubeError(ctx, err)
+	}
+}
+
+// StartFault
+// @Description 对服务满足条件的请求按比例注入延迟或中断, 故障路由复制原本处理这些请求的路由, 到期后自动删除; 同一个服务同时只能有一个实验
+// @Summary  开始故障注入实验
+// @Tags 	traffic
+// @Param	body		body		StartFaultRequest		true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/fault/start [post]
+func StartFault(ctx *gin.Context) {
+	req := StartFaultRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	config := traffic.FaultConfig{
+		Namespace:   req.Namespace,
+		Service:     req.Service,
+		Match:       req.Match,
+		Percentage:  req.Percentage,
+		Delay:       time.Duration(req.Delay) * time.Millisecond,
+		AbortStatus: req.AbortStatus,
+		TTL:         time.Duration(req.Ttl) * time.Second,
+	}
+	if err := config.Validate(); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(req.Id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	experiment, err := traffic.StartFaultExperiment(kubeConfig, config)
+	if err != nil {
+		responseExperimentError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, newFaultExperiment(experiment))
+}
+
+// ListFaults
+// @Description 获取集群的故障注入实验列表, 按创建时间倒序
+// @Summary  获取故障注入实验列表
+// @Tags 	traffic
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"namespace"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/fault/list [get]
+func ListFaults(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	experiments, err := model.FaultExperimentDB.ListFaultExperiment(id, ctx.Query("namespace"))
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	experimentRsp := make([]FaultExperiment, 0)
+	for i := range *experiments {
+		experimentRsp = append(experimentRsp, newFaultExperiment(&(*experiments)[i]))
+	}
+	ResponseData(ctx, CodeSuccess, experimentRsp)
+}
+
+// GetFault
+// @Description 获取故障注入实验, message为最近一次操作的结果
+// @Summary  获取故障注入实验
+// @Tags 	traffic
+// @Param	experimentId	query		int64		true		"experimentId"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/fault/get [get]
+func GetFault(ctx *gin.Context) {
+	experimentIdStr := ctx.Query("experimentId")
+	experimentId, err := strconv.ParseInt(experimentIdStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	experiment, err := model.FaultExperimentDB.GetFaultExperimentById(experimentId)
+	if err != nil {
+		responseExperimentError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, newFaultExperiment(experiment))
+}
+
+// StopFault
+// @Description 提前结束运行中的故障注入实验, 删除注入的故障
+// @Summary  结束故障注入实验
+// @Tags 	traffic
+// @Param	experimentId	query		int64		true		"experimentId"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/fault/stop [post]
+func StopFault(ctx *gin.Context) {
+	experimentIdStr := ctx.Query("experimentId")
+	experimentId, err := strconv.ParseInt(experimentIdStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	experiment, err := traffic.StopFaultExperiment(experimentId)
+	if err != nil {
+		responseExperimentError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, newFaultExperiment(experiment))
+}
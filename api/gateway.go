@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	"github.com/gin-gonic/gin"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var gatewayHandler = &resourceHandler{
+	gvk:       istio.GVKGateway,
+	newObject: func() runtime.Object { return &v1alpha3.Gateway{} },
+	client: istioResourceClient(func(cli *istio.IstioClient) *resourceClient {
+		gateway := istio.NewGateway(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				gateways, err := gateway.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(gateways))
+				for _, gw := range gateways {
+					objects = append(objects, gw)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return gateway.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return gateway.Create(obj.(*v1alpha3.Gateway), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return gateway.Update(obj.(*v1alpha3.Gateway), dryRun)
+			},
+			delete: gateway.Delete,
+		}
+	}),
+}
+
+// ListGateways
+// @Description 获取命名空间下的Gateway, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取Gateway列表
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/gateway/list [get]
+func ListGateways(ctx *gin.Context) {
+	gatewayHandler.list(ctx)
+}
+
+// GetGateway
+// @Description 获取Gateway, format为yaml时返回yaml字符串
+// @Summary  获取Gateway
+// @Tags 	istio
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/gateway/get [get]
+func GetGateway(ctx *gin.Context) {
+	gatewayHandler.get(ctx)
+}
+
+// CreateGateway
+// @Description 创建Gateway, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建Gateway
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"Gateway"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/gateway/create [post]
+func CreateGateway(ctx *gin.Context) {
+	gatewayHandler.create(ctx)
+}
+
+// UpdateGateway
+// @Description 更新Gateway, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新Gateway
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"Gateway"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/gateway/update [post]
+func UpdateGateway(ctx *gin.Context) {
+	gatewayHandler.update(ctx)
+}
+
+// DeleteGateway
+// @Description 删除Gateway, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除Gateway
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/gateway/delete [post]
+func DeleteGateway(ctx *gin.Context) {
+	gatewayHandler.delete(ctx)
+}
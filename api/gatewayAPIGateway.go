@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/gatewayapi"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var gatewayAPIGatewayHandler = &resourceHandler{
+	gvk:       gatewayapi.GVKGateway,
+	newObject: func() runtime.Object { return &v1alpha2.Gateway{} },
+	client: gatewayAPIResourceClient(func(cli *gatewayapi.GatewayAPIClient) *resourceClient {
+		gateway := gatewayapi.NewGateway(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				gateways, err := gateway.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(gateways))
+				for _, gw := range gateways {
+					objects = append(objects, gw)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return gateway.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return gateway.Create(obj.(*v1alpha2.Gateway), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return gateway.Update(obj.(*v1alpha2.Gateway), dryRun)
+			},
+			delete: gateway.Delete,
+		}
+	}),
+}
+
+// ListGatewayAPIGateways
+// @Description 获取命名空间下的Gateway, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取Gateway列表
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gateway/list [get]
+func ListGatewayAPIGateways(ctx *gin.Context) {
+	gatewayAPIGatewayHandler.list(ctx)
+}
+
+// GetGatewayAPIGateway
+// @Description 获取Gateway, format为yaml时返回yaml字符串
+// @Summary  获取Gateway
+// @Tags 	gatewayapi
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gateway/get [get]
+func GetGatewayAPIGateway(ctx *gin.Context) {
+	gatewayAPIGatewayHandler.get(ctx)
+}
+
+// CreateGatewayAPIGateway
+// @Description 创建Gateway, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建Gateway
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"Gateway"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gateway/create [post]
+func CreateGatewayAPIGateway(ctx *gin.Context) {
+	gatewayAPIGatewayHandler.create(ctx)
+}
+
+// UpdateGatewayAPIGateway
+// @Description 更新Gateway, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新Gateway
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"Gateway"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gateway/update [post]
+func UpdateGatewayAPIGateway(ctx *gin.Context) {
+	gatewayAPIGatewayHandler.update(ctx)
+}
+
+// DeleteGatewayAPIGateway
+// @Description 删除Gateway, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除Gateway
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gateway/delete [post]
+func DeleteGatewayAPIGateway(ctx *gin.Context) {
+	gatewayAPIGatewayHandler.delete(ctx)
+}
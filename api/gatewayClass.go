@@ -0,0 +1,107 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/gatewayapi"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var gatewayClassHandler = &resourceHandler{
+	gvk:           gatewayapi.GVKGatewayClass,
+	clusterScoped: true,
+	newObject:     func() runtime.Object { return &v1alpha2.GatewayClass{} },
+	client: gatewayAPIResourceClient(func(cli *gatewayapi.GatewayAPIClient) *resourceClient {
+		gatewayClass := gatewayapi.NewGatewayClass(cli)
+		return &resourceClient{
+			list: func(_ string, label map[string]string) ([]runtime.Object, error) {
+				gatewayClasses, err := gatewayClass.List(label)
+				objects := make([]runtime.Object, 0, len(gatewayClasses))
+				for _, class := range gatewayClasses {
+					objects = append(objects, class)
+				}
+				return objects, err
+			},
+			get: func(_, name string) (runtime.Object, error) {
+				return gatewayClass.Get(name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return gatewayClass.Create(obj.(*v1alpha2.GatewayClass), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return gatewayClass.Update(obj.(*v1alpha2.GatewayClass), dryRun)
+			},
+			delete: func(_, name, resourceVersion string, dryRun bool) error {
+				return gatewayClass.Delete(name, resourceVersion, dryRun)
+			},
+		}
+	}),
+}
+
+// ListGatewayClasses
+// @Description 获取GatewayClass, GatewayClass是集群级别的资源, 可按labelSelector过滤
+// @Summary  获取GatewayClass列表
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gatewayclass/list [get]
+func ListGatewayClasses(ctx *gin.Context) {
+	gatewayClassHandler.list(ctx)
+}
+
+// GetGatewayClass
+// @Description 获取GatewayClass, format为yaml时返回yaml字符串
+// @Summary  获取GatewayClass
+// @Tags 	gatewayapi
+// @Param	id			query		int64		true		"id"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gatewayclass/get [get]
+func GetGatewayClass(ctx *gin.Context) {
+	gatewayClassHandler.get(ctx)
+}
+
+// CreateGatewayClass
+// @Description 创建GatewayClass, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建GatewayClass
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"GatewayClass"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gatewayclass/create [post]
+func CreateGatewayClass(ctx *gin.Context) {
+	gatewayClassHandler.create(ctx)
+}
+
+// UpdateGatewayClass
+// @Description 更新GatewayClass, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新GatewayClass
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"GatewayClass"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gatewayclass/update [post]
+func UpdateGatewayClass(ctx *gin.Context) {
+	gatewayClassHandler.update(ctx)
+}
+
+// DeleteGatewayClass
+// @Description 删除GatewayClass, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除GatewayClass
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/gatewayclass/delete [post]
+func DeleteGatewayClass(ctx *gin.Context) {
+	gatewayClassHandler.delete(ctx)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shuxnhs/istio-dashboard/domain/gatewayapi"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getGatewayAPIClient 根据query中的id获取集群共享的网关API客户端, 失败时已经返回了响应
+func getGatewayAPIClient(ctx *gin.Context) *gatewayapi.GatewayAPIClient {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return nil
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return nil
+	}
+
+	gatewayAPIClient := gatewayapi.GetGatewayAPIClient(kubeConfig)
+	if gatewayAPIClient == nil {
+		ResponseData(ctx, CodeKubeConnectError, nil)
+		return nil
+	}
+	return gatewayAPIClient
+}
+
+// gatewayAPIResourceClient 获取客户端后用newClient创建domain/gatewayapi中资源的方法, 作为 resourceHandler 的client
+func gatewayAPIResourceClient(newClient func(cli *gatewayapi.GatewayAPIClient) *resourceClient) func(ctx *gin.Context) *resourceClient {
+	return func(ctx *gin.Context) *resourceClient {
+		gatewayAPIClient := getGatewayAPIClient(ctx)
+		if gatewayAPIClient == nil {
+			return nil
+		}
+		return newClient(gatewayAPIClient)
+	}
+}
+
+// GetAttachmentStatus
+// @Description 获取路由在每个parentRef上的挂载状态(Accepted/ResolvedRefs), 以及网关每个监听器挂载的路由数量; 不传namespace时查询所有命名空间
+// @Summary  获取路由挂载状态
+// @Tags 	gatewayapi
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"namespace"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/attachment [get]
+func GetAttachmentStatus(ctx *gin.Context) {
+	gatewayAPIClient := getGatewayAPIClient(ctx)
+	if gatewayAPIClient == nil {
+		return
+	}
+
+	namespace := ctx.DefaultQuery("namespace", metav1.NamespaceAll)
+	status, err := gatewayAPIClient.GetAttachmentStatus(namespace)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, status)
+}
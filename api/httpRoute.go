@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/gatewayapi"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var httpRouteHandler = &resourceHandler{
+	gvk:       gatewayapi.GVKHTTPRoute,
+	newObject: func() runtime.Object { return &v1alpha2.HTTPRoute{} },
+	client: gatewayAPIResourceClient(func(cli *gatewayapi.GatewayAPIClient) *resourceClient {
+		httpRoute := gatewayapi.NewHTTPRoute(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				httpRoutes, err := httpRoute.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(httpRoutes))
+				for _, route := range httpRoutes {
+					objects = append(objects, route)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return httpRoute.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return httpRoute.Create(obj.(*v1alpha2.HTTPRoute), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return httpRoute.Update(obj.(*v1alpha2.HTTPRoute), dryRun)
+			},
+			delete: httpRoute.Delete,
+		}
+	}),
+}
+
+// ListHTTPRoutes
+// @Description 获取命名空间下的HTTPRoute, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取HTTPRoute列表
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/httproute/list [get]
+func ListHTTPRoutes(ctx *gin.Context) {
+	httpRouteHandler.list(ctx)
+}
+
+// GetHTTPRoute
+// @Description 获取HTTPRoute, format为yaml时返回yaml字符串
+// @Summary  获取HTTPRoute
+// @Tags 	gatewayapi
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/httproute/get [get]
+func GetHTTPRoute(ctx *gin.Context) {
+	httpRouteHandler.get(ctx)
+}
+
+// CreateHTTPRoute
+// @Description 创建HTTPRoute, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建HTTPRoute
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"HTTPRoute"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/httproute/create [post]
+func CreateHTTPRoute(ctx *gin.Context) {
+	httpRouteHandler.create(ctx)
+}
+
+// UpdateHTTPRoute
+// @Description 更新HTTPRoute, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新HTTPRoute
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"HTTPRoute"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/httproute/update [post]
+func UpdateHTTPRoute(ctx *gin.Context) {
+	httpRouteHandler.update(ctx)
+}
+
+// DeleteHTTPRoute
+// @Description 删除HTTPRoute, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除HTTPRoute
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/httproute/delete [post]
+func DeleteHTTPRoute(ctx *gin.Context) {
+	httpRouteHandler.delete(ctx)
+}
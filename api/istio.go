@@ -0,0 +1,143 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// getIstioClient 根据query中的id获取集群共享的istio客户端, 失败时已经返回了响应
+func getIstioClient(ctx *gin.Context) *istio.IstioClient {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return nil
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return nil
+	}
+
+	istioClient := istio.GetIstioClient(kubeConfig)
+	if istioClient == nil {
+		ResponseData(ctx, CodeKubeConnectError, nil)
+		return nil
+	}
+	return istioClient
+}
+
+// istioResourceClient 获取客户端后用newClient创建domain/istio中资源的方法, 作为 resourceHandler 的client
+func istioResourceClient(newClient func(cli *istio.IstioClient) *resourceClient) func(ctx *gin.Context) *resourceClient {
+	return func(ctx *gin.Context) *resourceClient {
+		istioClient := getIstioClient(ctx)
+		if istioClient == nil {
+			return nil
+		}
+		return newClient(istioClient)
+	}
+}
+
+func isDryRun(ctx *gin.Context) bool {
+	dryRun, _ := strconv.ParseBool(ctx.Query("dryRun"))
+	return dryRun
+}
+
+// listOptions allNamespaces为true时查询所有命名空间, labelSelector格式为 app=foo,version=v1
+func listOptions(ctx *gin.Context) (string, map[string]string, error) {
+	label, err := labels.ConvertSelectorToLabelsMap(ctx.Query("labelSelector"))
+	if err != nil {
+		return "", nil, err
+	}
+	if allNamespaces, _ := strconv.ParseBool(ctx.Query("allNamespaces")); allNamespaces {
+		return metav1.NamespaceAll, label, nil
+	}
+	namespace := ctx.Query("namespace")
+	if namespace == "" {
+		return "", nil, errors.New("namespace is required")
+	}
+	return namespace, label, nil
+}
+
+// decodeResource 解析请求体中yaml或json格式的资源, 没有指定命名空间时使用query中的namespace
+func decodeResource(ctx *gin.Context, gvk schema.GroupVersionKind, obj runtime.Object) error {
+	data, err := ctx.GetRawData()
+	if err != nil {
+		return err
+	}
+	return decodeResourceData(ctx, data, gvk, obj)
+}
+
+func decodeResourceData(ctx *gin.Context, data []byte, gvk schema.GroupVersionKind, obj runtime.Object) error {
+	if err := kube.DecodeResource(data, gvk, obj); err != nil {
+		return err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if accessor.GetNamespace() == "" {
+		accessor.SetNamespace(ctx.Query("namespace"))
+	}
+	if accessor.GetNamespace() == "" {
+		return errors.New("namespace is required")
+	}
+	return nil
+}
+
+// ListResourceVersions
+// @Description 获取每种istio资源在集群中提供的版本, 以及与集群协商后实际使用的版本
+// @Summary  获取istio资源的版本
+// @Tags 	istio
+// @Param	id		query		int64		true		"id"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/version [get]
+func ListResourceVersions(ctx *gin.Context) {
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+	ResponseData(ctx, CodeSuccess, istioClient.GetResourceVersions())
+}
+
+// Analyze
+// @Description 使用istioctl analyze的分析器检查informer缓存中的istio及k8s资源, 依赖Secret的分析器不会执行; 不传namespace时返回所有命名空间的消息
+// @Summary  分析istio配置
+// @Tags 	istio
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"namespace"
+// @Param	level		query		string		false		"返回消息的最低级别 Info|Warning|Error, 默认Info"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/analyze [get]
+func Analyze(ctx *gin.Context) {
+	level := ctx.DefaultQuery("level", istio.AnalysisLevelInfo)
+	if !istio.IsAnalysisLevel(level) {
+		ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("unsupported level: %s", level))
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	messages, err := istioClient.Analyze(ctx.Query("namespace"), level)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, messages)
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	"github.com/shuxnhs/istio-dashboard/domain/traffic"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMirrorInterval = 300
+
+// GetMirror
+// @Description 获取服务的VirtualService中每条路由到该服务的http路由的流量镜像
+// @Summary  获取流量镜像
+// @Tags 	traffic
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	service		query		string		true		"service"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/mirror/get [get]
+func GetMirror(ctx *gin.Context) {
+	namespace, service := ctx.Query("namespace"), ctx.Query("service")
+	if namespace == "" || service == "" {
+		ResponseError(ctx, http.StatusBadRequest, errors.New("namespace and service are required"))
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	policy, err := istioClient.GetMirror(namespace, service)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, policy)
+}
+
+// SetMirror
+// @Description 把服务的请求按比例镜像到影子subset或其他host, host为空时镜像到服务自身的subset; route为空时修改所有只路由到该服务的http路由, 以patch的方式修改, 不会替换其他配置
+// @Summary  设置流量镜像
+// @Tags 	traffic
+// @Param	id			query		int64				true		"id"
+// @Param	dryRun		query		bool				false		"dryRun"
+// @Param	body		body		istio.MirrorConfig	true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/mirror/set [post]
+func SetMirror(ctx *gin.Context) {
+	config := &istio.MirrorConfig{}
+	if err := ctx.ShouldBindJSON(config); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := config.Validate(); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	policy, err := istioClient.SetMirror(config, isDryRun(ctx))
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, policy)
+}
+
+// RemoveMirror
+// @Description 删除服务路由上的流量镜像, route为空时删除所有路由到该服务的http路由上的镜像
+// @Summary  删除流量镜像
+// @Tags 	traffic
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	service		query		string		true		"service"
+// @Param	route		query		string		false		"route"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/mirror/remove [post]
+func RemoveMirror(ctx *gin.Context) {
+	namespace, service := ctx.Query("namespace"), ctx.Query("service")
+	if namespace == "" || service == "" {
+		ResponseError(ctx, http.StatusBadRequest, errors.New("namespace and service are required"))
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	policy, err := istioClient.RemoveMirror(namespace, service, ctx.Query("route"), isDryRun(ctx))
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, policy)
+}
+
+// CompareMirror
+// @Description 通过kiali的服务指标对比主流量和镜像流量的请求量、成功率和p99延迟, 用于验证重写后的版本; route为空时使用第一条有镜像的路由
+// @Summary  对比镜像流量
+// @Tags 	traffic
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	service		query		string		true		"service"
+// @Param	route		query		string		false		"route"
+// @Param	interval	query		int64		false		"interval in seconds, default 300"
+// @Success 200 {object} Result  "ok"
+// @Router /traffic/mirror/compare [get]
+func CompareMirror(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	namespace, service := ctx.Query("namespace"), ctx.Query("service")
+	if namespace == "" || service == "" {
+		ResponseError(ctx, http.StatusBadRequest, errors.New("namespace and service are required"))
+		return
+	}
+	interval := int64(defaultMirrorInterval)
+	if intervalStr := ctx.Query("interval"); intervalStr != "" {
+		if interval, err = strconv.ParseInt(intervalStr, 10, 64); err != nil {
+			ResponseError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if interval <= 0 || interval > traffic.MaxMirrorInterval {
+		ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("interval must be in (0, %d]", traffic.MaxMirrorInterval))
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	comparison, err := traffic.CompareMirror(kubeConfig, namespace, service, ctx.Query("route"), interval)
+	if err != nil {
+		if errors.Is(err, traffic.ErrNoMirror) {
+			ResponseError(ctx, http.StatusNotFound, err)
+			return
+		}
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, comparison)
+}
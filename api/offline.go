@@ -0,0 +1,187 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/config"
+	"github.com/shuxnhs/istio-dashboard/domain/sidecar"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead multipart 中除文件外的边界和表单字段
+const multipartOverhead = 1 << 20
+
+// uploadBody 记录读取的字节数, 读取失败时用来判断是否是 MaxBytesReader 的大小限制
+type uploadBody struct {
+	io.ReadCloser
+	read  int64
+	limit int64
+}
+
+func (b *uploadBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *uploadBody) exceeded() bool {
+	return b.read >= b.limit
+}
+
+type OfflineDump struct {
+	Id          int64  `json:"id"`
+	FileName    string `json:"fileName"`
+	Namespace   string `json:"namespace"`
+	Pod         string `json:"pod"`
+	Description string `json:"description"`
+	Size        int64  `json:"size"`
+	CreateTime  int64  `json:"createTime"`
+}
+
+func newOfflineDump(dump *model.OfflineDump) OfflineDump {
+	return OfflineDump{
+		Id:          dump.Id,
+		FileName:    dump.FileName,
+		Namespace:   dump.Namespace,
+		Pod:         dump.Pod,
+		Description: dump.Description,
+		Size:        dump.Size,
+		CreateTime:  dump.CreateTime,
+	}
+}
+
+// UploadOfflineDump
+// @Description 上传envoy的config_dump(json)或 istioctl bug-report 的tar.gz包, bug-report中的每个边车保存为一条记录; 文件或解压后的config_dump总大小超过 MaxUploadSize 时返回413
+// @Summary  上传config_dump用于离线分析
+// @Tags 	offline
+// @Accept	multipart/form-data
+// @Param	file			formData	file		true		"config_dump或bug-report"
+// @Param	description		formData	string		false		"description"
+// @Success 200 {object} Result  "ok"
+// @Router /offline/upload [post]
+func UploadOfflineDump(ctx *gin.Context) {
+	limit := config.Config.MaxUploadSize
+	errTooLarge := fmt.Errorf("file exceeds the upload size limit of %d bytes", limit)
+	if ctx.Request.ContentLength > limit+multipartOverhead {
+		ResponseError(ctx, http.StatusRequestEntityTooLarge, errTooLarge)
+		return
+	}
+	body := &uploadBody{ReadCloser: http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit+multipartOverhead), limit: limit + multipartOverhead}
+	ctx.Request.Body = body
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		if body.exceeded() {
+			ResponseError(ctx, http.StatusRequestEntityTooLarge, errTooLarge)
+			return
+		}
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if fileHeader.Size > limit {
+		ResponseError(ctx, http.StatusRequestEntityTooLarge, errTooLarge)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, limit))
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	offlineDumps, err := sidecar.ParseOfflineConfigDump(data, limit)
+	if err != nil {
+		if errors.Is(err, sidecar.ErrConfigDumpTooLarge) {
+			ResponseError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	dumps := make([]model.OfflineDump, 0, len(offlineDumps))
+	for _, offlineDump := range offlineDumps {
+		compressed, err := sidecar.CompressConfigDump(offlineDump.Config)
+		if err != nil {
+			ResponseError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		dumps = append(dumps, model.OfflineDump{
+			FileName:    fileHeader.Filename,
+			Namespace:   offlineDump.Namespace,
+			Pod:         offlineDump.Pod,
+			Description: ctx.PostForm("description"),
+			ConfigDump:  compressed,
+			Size:        int64(len(offlineDump.Config)),
+			CreateTime:  time.Now().Unix(),
+		})
+	}
+	if err = model.OfflineDumpDB.CreateOfflineDumps(dumps); err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	dumpRsp := make([]OfflineDump, 0, len(dumps))
+	for i := range dumps {
+		dumpRsp = append(dumpRsp, newOfflineDump(&dumps[i]))
+	}
+	ResponseData(ctx, CodeSuccess, dumpRsp)
+}
+
+// ListOfflineDumps
+// @Description 获取上传的config_dump列表
+// @Summary  获取上传的config_dump列表
+// @Tags 	offline
+// @Success 200 {object} Result  "ok"
+// @Router /offline/list [get]
+func ListOfflineDumps(ctx *gin.Context) {
+	dumps, err := model.OfflineDumpDB.ListOfflineDump()
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	dumpRsp := make([]OfflineDump, 0)
+	for i := range *dumps {
+		dumpRsp = append(dumpRsp, newOfflineDump(&(*dumps)[i]))
+	}
+	ResponseData(ctx, CodeSuccess, dumpRsp)
+}
+
+// GetOfflineViews
+// @Description 获取上传的config_dump的CDS/LDS/RDS/EDS, EDS需要config_dump包含端点(config_dump?include_eds)
+// @Summary  获取上传的config_dump的xDS视图
+// @Tags 	offline
+// @Param	id			query		int64		true		"id"
+// @Success 200 {object} Result  "ok"
+// @Router /offline/view [get]
+func GetOfflineViews(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	dump, err := model.OfflineDumpDB.GetOfflineDumpById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	configDump, err := sidecar.DecompressConfigDump(dump.ConfigDump)
+	if err != nil {
+		ResponseError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, sidecar.ConfigDumpToViews(configDump))
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/gatewayapi"
+
+	"github.com/gin-gonic/gin"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/yaml"
+)
+
+var referenceGrantHandler = &resourceHandler{
+	gvk:       gatewayapi.GVKReferenceGrant,
+	newObject: func() runtime.Object { return &v1alpha2.ReferencePolicy{} },
+	decode: func(ctx *gin.Context) (runtime.Object, error) {
+		return decodeReferenceGrant(ctx)
+	},
+	client: gatewayAPIResourceClient(func(cli *gatewayapi.GatewayAPIClient) *resourceClient {
+		referenceGrant := gatewayapi.NewReferenceGrant(cli)
+		return &resourceClient{
+			gvk: referenceGrant.GVK(),
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				referenceGrants, err := referenceGrant.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(referenceGrants))
+				for _, grant := range referenceGrants {
+					objects = append(objects, grant)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return referenceGrant.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return referenceGrant.Create(obj.(*v1alpha2.ReferencePolicy), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return referenceGrant.Update(obj.(*v1alpha2.ReferencePolicy), dryRun)
+			},
+			delete: referenceGrant.Delete,
+		}
+	}),
+}
+
+// decodeReferenceGrant ReferenceGrant 和 ReferencePolicy 结构相同, 请求体可以使用任意一种kind, 由集群提供的资源决定实际的kind
+func decodeReferenceGrant(ctx *gin.Context) (*v1alpha2.ReferencePolicy, error) {
+	data, err := ctx.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+	typeMeta := &metav1.TypeMeta{}
+	if err = yaml.Unmarshal(data, typeMeta); err != nil {
+		return nil, err
+	}
+	gvk := gatewayapi.GVKReferenceGrant
+	if typeMeta.Kind == gatewayapi.GVKReferencePolicy.Kind {
+		gvk = gatewayapi.GVKReferencePolicy
+	}
+	referenceGrant := &v1alpha2.ReferencePolicy{}
+	if err = decodeResourceData(ctx, data, gvk, referenceGrant); err != nil {
+		return nil, err
+	}
+	return referenceGrant, nil
+}
+
+// ListReferenceGrants
+// @Description 获取命名空间下的ReferenceGrant, 集群只提供ReferencePolicy时返回ReferencePolicy, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取ReferenceGrant列表
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/referencegrant/list [get]
+func ListReferenceGrants(ctx *gin.Context) {
+	referenceGrantHandler.list(ctx)
+}
+
+// GetReferenceGrant
+// @Description 获取ReferenceGrant, format为yaml时返回yaml字符串
+// @Summary  获取ReferenceGrant
+// @Tags 	gatewayapi
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/referencegrant/get [get]
+func GetReferenceGrant(ctx *gin.Context) {
+	referenceGrantHandler.get(ctx)
+}
+
+// CreateReferenceGrant
+// @Description 创建ReferenceGrant, 请求体为yaml或json, kind可以是ReferenceGrant或ReferencePolicy, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建ReferenceGrant
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"ReferenceGrant"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/referencegrant/create [post]
+func CreateReferenceGrant(ctx *gin.Context) {
+	referenceGrantHandler.create(ctx)
+}
+
+// UpdateReferenceGrant
+// @Description 更新ReferenceGrant, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新ReferenceGrant
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"ReferenceGrant"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/referencegrant/update [post]
+func UpdateReferenceGrant(ctx *gin.Context) {
+	referenceGrantHandler.update(ctx)
+}
+
+// DeleteReferenceGrant
+// @Description 删除ReferenceGrant, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除ReferenceGrant
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/referencegrant/delete [post]
+func DeleteReferenceGrant(ctx *gin.Context) {
+	referenceGrantHandler.delete(ctx)
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+	"github.com/shuxnhs/istio-dashboard/domain/sidecar"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetResilience
+// @Description 获取服务的连接池、异常检测(来自DestinationRule)和每条路由的重试、超时(来自VirtualService)
+// @Summary  获取服务弹性策略
+// @Tags 	istio
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	service		query		string		true		"service"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/resilience/get [get]
+func GetResilience(ctx *gin.Context) {
+	namespace, service := ctx.Query("namespace"), ctx.Query("service")
+	if namespace == "" || service == "" {
+		ResponseError(ctx, http.StatusBadRequest, errors.New("namespace and service are required"))
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	policy, err := istioClient.GetResiliencePolicy(namespace, service)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, policy)
+}
+
+// SetResilience
+// @Description 以patch的方式修改服务的弹性策略, 只修改请求中设置的字段, remove中的策略会被删除; 返回修改后的策略, 可以用 /istio/resilience/sync 确认边车已经生效
+// @Summary  修改服务弹性策略
+// @Tags 	istio
+// @Param	id			query		int64					true		"id"
+// @Param	dryRun		query		bool					false		"dryRun"
+// @Param	body		body		istio.ResiliencePatch	true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/resilience/set [post]
+func SetResilience(ctx *gin.Context) {
+	patch := &istio.ResiliencePatch{}
+	if err := ctx.ShouldBindJSON(patch); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := patch.Validate(); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	policy, err := istioClient.SetResiliencePolicy(patch, isDryRun(ctx))
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, policy)
+}
+
+// CheckResilienceSync
+// @Description 对比边车CDS/RDS中服务的集群和路由与当前弹性策略是否一致, pod一般选择调用该服务的客户端
+// @Summary  检查弹性策略是否生效
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"service namespace"
+// @Param	service			query		string		true		"service"
+// @Param	podNamespace	query		string		true		"pod namespace"
+// @Param	pod				query		string		true		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/resilience/sync [get]
+func CheckResilienceSync(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	namespace, service := ctx.Query("namespace"), ctx.Query("service")
+	if namespace == "" || service == "" || ctx.Query("podNamespace") == "" || ctx.Query("pod") == "" {
+		ResponseError(ctx, http.StatusBadRequest, errors.New("namespace, service, podNamespace and pod are required"))
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	istioClient := istio.GetIstioClient(kubeConfig)
+	if istioClient == nil {
+		ResponseData(ctx, CodeKubeConnectError, nil)
+		return
+	}
+	policy, err := istioClient.GetResiliencePolicy(namespace, service)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+
+	sync, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		CheckResilience(ctx.Query("podNamespace"), ctx.Query("pod"), policy)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, sync)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourceHandler 资源的增删改查接口, 各资源只有GVK、对象类型和domain中的wrapper不同, wrapper的方法通过 resourceClient 调用
+type resourceHandler struct {
+	gvk           schema.GroupVersionKind
+	clusterScoped bool
+	newObject     func() runtime.Object
+	// decode 请求体不能按gvk直接解析时设置
+	decode func(ctx *gin.Context) (runtime.Object, error)
+	// client 获取集群客户端失败时返回nil, 此时已经返回了响应
+	client func(ctx *gin.Context) *resourceClient
+}
+
+// resourceClient domain中资源wrapper的增删改查方法, 集群级别的资源忽略namespace参数
+type resourceClient struct {
+	// gvk wrapper 的类型与集群提供的版本有关时设置(如 ReferenceGrant), 为空时使用 resourceHandler 的gvk
+	gvk    schema.GroupVersionKind
+	list   func(namespace string, label map[string]string) ([]runtime.Object, error)
+	get    func(namespace, name string) (runtime.Object, error)
+	create func(obj runtime.Object, dryRun bool) (runtime.Object, error)
+	update func(obj runtime.Object, dryRun bool) (runtime.Object, error)
+	delete func(namespace, name, resourceVersion string, dryRun bool) error
+}
+
+func (h *resourceHandler) list(ctx *gin.Context) {
+	namespace, label, err := h.listOptions(ctx)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	client := h.client(ctx)
+	if client == nil {
+		return
+	}
+
+	list, err := client.list(namespace, label)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	gvk := h.resourceGVK(client)
+	items := make([]interface{}, 0, len(list))
+	for _, obj := range list {
+		item, _ := kube.FormatResource(obj, gvk, kube.FormatJSON)
+		items = append(items, item)
+	}
+	ResponseData(ctx, CodeSuccess, items)
+}
+
+func (h *resourceHandler) get(ctx *gin.Context) {
+	client := h.client(ctx)
+	if client == nil {
+		return
+	}
+
+	obj, err := client.get(h.namespace(ctx), ctx.Query("name"))
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	data, err := kube.FormatResource(obj, h.resourceGVK(client), ctx.Query("format"))
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, data)
+}
+
+func (h *resourceHandler) create(ctx *gin.Context) {
+	obj, err := h.decodeBody(ctx)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	client := h.client(ctx)
+	if client == nil {
+		return
+	}
+	h.write(ctx, client, client.create, obj)
+}
+
+func (h *resourceHandler) update(ctx *gin.Context) {
+	obj, err := h.decodeBody(ctx)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if accessor.GetResourceVersion() == "" {
+		ResponseError(ctx, http.StatusBadRequest, errors.New("metadata.resourceVersion is required"))
+		return
+	}
+	client := h.client(ctx)
+	if client == nil {
+		return
+	}
+	h.write(ctx, client, client.update, obj)
+}
+
+// write 创建或更新资源, 返回apiserver中的结果
+func (h *resourceHandler) write(ctx *gin.Context, client *resourceClient,
+	write func(obj runtime.Object, dryRun bool) (runtime.Object, error), obj runtime.Object) {
+	written, err := write(obj, isDryRun(ctx))
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	data, _ := kube.FormatResource(written, h.resourceGVK(client), kube.FormatJSON)
+	ResponseData(ctx, CodeSuccess, data)
+}
+
+func (h *resourceHandler) delete(ctx *gin.Context) {
+	client := h.client(ctx)
+	if client == nil {
+		return
+	}
+
+	if err := client.delete(h.namespace(ctx), ctx.Query("name"), ctx.Query("resourceVersion"), isDryRun(ctx)); err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseOK(ctx)
+}
+
+// listOptions 集群级别的资源只按labelSelector过滤
+func (h *resourceHandler) listOptions(ctx *gin.Context) (string, map[string]string, error) {
+	if !h.clusterScoped {
+		return listOptions(ctx)
+	}
+	label, err := labels.ConvertSelectorToLabelsMap(ctx.Query("labelSelector"))
+	return "", label, err
+}
+
+// namespace 集群级别的资源没有命名空间
+func (h *resourceHandler) namespace(ctx *gin.Context) string {
+	if h.clusterScoped {
+		return ""
+	}
+	return ctx.Query("namespace")
+}
+
+// decodeBody 集群级别的资源没有命名空间, 不能使用 decodeResource
+func (h *resourceHandler) decodeBody(ctx *gin.Context) (runtime.Object, error) {
+	if h.decode != nil {
+		return h.decode(ctx)
+	}
+	obj := h.newObject()
+	if !h.clusterScoped {
+		return obj, decodeResource(ctx, h.gvk, obj)
+	}
+	data, err := ctx.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+	if err = kube.DecodeResource(data, h.gvk, obj); err != nil {
+		return nil, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	accessor.SetNamespace("")
+	return obj, nil
+}
+
+func (h *resourceHandler) resourceGVK(client *resourceClient) schema.GroupVersionKind {
+	if !client.gvk.Empty() {
+		return client.gvk
+	}
+	return h.gvk
+}
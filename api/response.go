@@ -1,9 +1,12 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type Result struct {
@@ -13,6 +16,14 @@ type Result struct {
 	Data    interface{} `json:"data"`
 }
 
+// KubeError k8s接口返回的错误, causes中为校验失败的字段
+type KubeError struct {
+	Code    int32                `json:"code"`
+	Reason  string               `json:"reason"`
+	Message string               `json:"message"`
+	Causes  []metav1.StatusCause `json:"causes"`
+}
+
 func NewResponse(code, errcode int, message string, data interface{}) *Result {
 	return &Result{
 		Code:    code,
@@ -45,3 +56,23 @@ func ResponseData(ctx *gin.Context, errCode int, data interface{}) {
 func Response(ctx *gin.Context, code, errCode int, message string, data interface{}) {
 	NewResponse(code, errCode, message, data).Resp(ctx)
 }
+
+// ResponseKubeError k8s接口返回的错误(校验失败、版本冲突、webhook拒绝等)按Status返回, 其余为连接错误
+func ResponseKubeError(ctx *gin.Context, err error) {
+	var apiStatus apierrors.APIStatus
+	if !errors.As(err, &apiStatus) {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	status := apiStatus.Status()
+	kubeErr := KubeError{
+		Code:    status.Code,
+		Reason:  string(status.Reason),
+		Message: status.Message,
+		Causes:  make([]metav1.StatusCause, 0),
+	}
+	if status.Details != nil {
+		kubeErr.Causes = append(kubeErr.Causes, status.Details.Causes...)
+	}
+	Response(ctx, http.StatusOK, CodeKubeApiError, status.Message, kubeErr)
+}
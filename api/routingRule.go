@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewRoutingRule
+// @Description 按请求头、cookie、查询参数或来源标签把请求路由到服务的subset, 规则合并到服务的VirtualService中, 放在第一条会匹配到这些请求的路由之前; 返回合并前后的yaml差异, 不会修改集群
+// @Summary  预览路由规则
+// @Tags 	istio
+// @Param	id			query		int64				true		"id"
+// @Param	body		body		istio.RoutingRule	true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/routingrule/preview [post]
+func PreviewRoutingRule(ctx *gin.Context) {
+	rule := &istio.RoutingRule{}
+	if err := ctx.ShouldBindJSON(rule); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := rule.Validate(); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	preview, err := istioClient.PreviewRoutingRule(rule)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, preview)
+}
+
+// ApplyRoutingRule
+// @Description 应用路由规则, resourceVersion为预览时返回的版本, 期间VirtualService被修改过时返回Conflict, 需要重新预览
+// @Summary  应用路由规则
+// @Tags 	istio
+// @Param	id				query		int64				true		"id"
+// @Param	resourceVersion	query		string				false		"resourceVersion"
+// @Param	dryRun			query		bool				false		"dryRun"
+// @Param	body			body		istio.RoutingRule	true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/routingrule/apply [post]
+func ApplyRoutingRule(ctx *gin.Context) {
+	rule := &istio.RoutingRule{}
+	if err := ctx.ShouldBindJSON(rule); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := rule.Validate(); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	istioClient := getIstioClient(ctx)
+	if istioClient == nil {
+		return
+	}
+
+	preview, err := istioClient.ApplyRoutingRule(rule, ctx.Query("resourceVersion"), isDryRun(ctx))
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, preview)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	"github.com/gin-gonic/gin"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var serviceEntryHandler = &resourceHandler{
+	gvk:       istio.GVKServiceEntry,
+	newObject: func() runtime.Object { return &v1alpha3.ServiceEntry{} },
+	client: istioResourceClient(func(cli *istio.IstioClient) *resourceClient {
+		serviceEntry := istio.NewServiceEntry(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				serviceEntries, err := serviceEntry.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(serviceEntries))
+				for _, se := range serviceEntries {
+					objects = append(objects, se)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return serviceEntry.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return serviceEntry.Create(obj.(*v1alpha3.ServiceEntry), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return serviceEntry.Update(obj.(*v1alpha3.ServiceEntry), dryRun)
+			},
+			delete: serviceEntry.Delete,
+		}
+	}),
+}
+
+// ListServiceEntries
+// @Description 获取命名空间下的ServiceEntry, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取ServiceEntry列表
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/serviceentry/list [get]
+func ListServiceEntries(ctx *gin.Context) {
+	serviceEntryHandler.list(ctx)
+}
+
+// GetServiceEntry
+// @Description 获取ServiceEntry, format为yaml时返回yaml字符串
+// @Summary  获取ServiceEntry
+// @Tags 	istio
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/serviceentry/get [get]
+func GetServiceEntry(ctx *gin.Context) {
+	serviceEntryHandler.get(ctx)
+}
+
+// CreateServiceEntry
+// @Description 创建ServiceEntry, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建ServiceEntry
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"ServiceEntry"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/serviceentry/create [post]
+func CreateServiceEntry(ctx *gin.Context) {
+	serviceEntryHandler.create(ctx)
+}
+
+// UpdateServiceEntry
+// @Description 更新ServiceEntry, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新ServiceEntry
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"ServiceEntry"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/serviceentry/update [post]
+func UpdateServiceEntry(ctx *gin.Context) {
+	serviceEntryHandler.update(ctx)
+}
+
+// DeleteServiceEntry
+// @Description 删除ServiceEntry, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除ServiceEntry
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/serviceentry/delete [post]
+func DeleteServiceEntry(ctx *gin.Context) {
+	serviceEntryHandler.delete(ctx)
+}
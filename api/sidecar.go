@@ -1,9 +1,13 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
+	"time"
 
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
 	"github.com/shuxnhs/istio-dashboard/domain/kube"
 	"github.com/shuxnhs/istio-dashboard/domain/sidecar"
 	"github.com/shuxnhs/istio-dashboard/model"
@@ -34,13 +38,54 @@ func Check(ctx *gin.Context) {
 		return
 	}
 
-	sidecar.NewSidecar(kube.GetConfigStoreKubeConfig(kubeConfig)).
+	check, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
 		Check(ctx.Query("namespace"), ctx.Query("pod"))
 	if err != nil {
 		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
 		return
 	}
-	ResponseData(ctx, CodeSuccess, nil)
+	ResponseData(ctx, CodeSuccess, check)
+}
+
+// ListProxyStatus
+// @Description 获取网格内所有边车的配置同步状态
+// @Summary  获取网格内所有边车的配置同步状态
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"namespace"
+// @Param	stale		query		bool		false		"只返回未同步的边车"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/status [get]
+func ListProxyStatus(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	staleOnly := false
+	if staleStr := ctx.Query("stale"); staleStr != "" {
+		staleOnly, err = strconv.ParseBool(staleStr)
+		if err != nil {
+			ResponseError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	status, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetProxyStatus(ctx.Query("namespace"), staleOnly)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, status)
 }
 
 // ListEDS
@@ -66,7 +111,7 @@ func ListEDS(ctx *gin.Context) {
 		return
 	}
 
-	eds, err := sidecar.NewSidecar(kube.GetConfigStoreKubeConfig(kubeConfig)).
+	eds, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
 		GetEDS(ctx.Query("namespace"), ctx.Query("pod"))
 	if err != nil {
 		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
@@ -75,6 +120,70 @@ func ListEDS(ctx *gin.Context) {
 	ResponseData(ctx, CodeSuccess, eds)
 }
 
+// ListEDSLocality
+// @Description 按优先级和地域汇总边车每个集群的端点数、健康端点数、权重占比和请求统计
+// @Summary  获取边车EDS的地域分布
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/eds/locality [get]
+func ListEDSLocality(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	locality, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetEDSLocality(ctx.Query("namespace"), ctx.Query("pod"))
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, locality)
+}
+
+// ListEndpointWorkloads
+// @Description 获取边车EDS端点对应的pod、节点、可用区和工作负载, 并标记envoy与k8s不一致的端点
+// @Summary  获取边车EDS端点对应的工作负载
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/eds/workload [get]
+func ListEndpointWorkloads(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	workloads, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetEndpointWorkloads(ctx.Query("namespace"), ctx.Query("pod"))
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, workloads)
+}
+
 // ListCDS
 // @Description 获取边车的CDS(集群配置)
 // @Summary  获取边车的CDS(集群配置)
@@ -98,7 +207,7 @@ func ListCDS(ctx *gin.Context) {
 		return
 	}
 
-	cds, err := sidecar.NewSidecar(kube.GetConfigStoreKubeConfig(kubeConfig)).
+	cds, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
 		GetCDS(ctx.Query("namespace"), ctx.Query("pod"))
 	if err != nil {
 		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
@@ -130,7 +239,7 @@ func ListLDS(ctx *gin.Context) {
 		return
 	}
 
-	cds, err := sidecar.NewSidecar(kube.GetConfigStoreKubeConfig(kubeConfig)).
+	cds, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
 		GetLDS(ctx.Query("namespace"), ctx.Query("pod"))
 	if err != nil {
 		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
@@ -162,7 +271,7 @@ func ListRDS(ctx *gin.Context) {
 		return
 	}
 
-	rds, err := sidecar.NewSidecar(kube.GetConfigStoreKubeConfig(kubeConfig)).
+	rds, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
 		GetRDS(ctx.Query("namespace"), ctx.Query("pod"))
 	if err != nil {
 		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
@@ -170,3 +279,469 @@ func ListRDS(ctx *gin.Context) {
 	}
 	ResponseData(ctx, CodeSuccess, rds)
 }
+
+// ListSDS
+// @Description 获取边车的SDS(证书)
+// @Summary  获取边车的SDS(证书)
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/sds/list [get]
+func ListSDS(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	sds, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetSDS(ctx.Query("namespace"), ctx.Query("pod"))
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, sds)
+}
+
+// GetBootstrap
+// @Description 获取边车的启动配置
+// @Summary  获取边车的启动配置
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/bootstrap [get]
+func GetBootstrap(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	bootstrap, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetBootstrap(ctx.Query("namespace"), ctx.Query("pod"))
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, bootstrap)
+}
+
+// GetLogLevel
+// @Description 获取边车的日志级别
+// @Summary  获取边车的日志级别
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/log [get]
+func GetLogLevel(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	logLevel, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetLogLevel(ctx.Query("namespace"), ctx.Query("pod"))
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, logLevel)
+}
+
+type SetLogLevelRequest struct {
+	Id          int64             `json:"id" binding:"required"`
+	Namespace   string            `json:"namespace" binding:"required"`
+	Pod         string            `json:"pod" binding:"required"`
+	Level       string            `json:"level"`
+	Loggers     map[string]string `json:"loggers"`
+	RevertAfter int64             `json:"revertAfter"`
+}
+
+// SetLogLevel
+// @Description 修改边车的日志级别, level修改所有logger, loggers修改指定logger, revertAfter(秒)大于0时到期自动恢复, 服务重启后仍会恢复, 误差不超过10秒
+// @Summary  修改边车的日志级别
+// @Tags 	sidecar
+// @Param	body		body		SetLogLevelRequest		true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/log [post]
+func SetLogLevel(ctx *gin.Context) {
+	req := SetLogLevelRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := sidecar.ValidateLogLevels(req.Level, req.Loggers); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(req.Id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	logLevel, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		SetLogLevel(req.Namespace, req.Pod, req.Level, req.Loggers, time.Duration(req.RevertAfter)*time.Second)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, logLevel)
+}
+
+// ListStats
+// @Description 获取边车的指标并按集群和监听器分组, 默认解析prometheus格式; format为json时解析envoy的json输出(不区分counter和gauge), 为prometheus时返回envoy的原始输出
+// @Summary  获取边车的指标
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Param	format		query		string		false		"json|prometheus"
+// @Param	filter		query		string		false		"正则表达式"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/stats [get]
+func ListStats(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	format := ctx.Query("format")
+	if format != "" && format != sidecar.StatsFormatJSON && format != sidecar.StatsFormatPrometheus {
+		ResponseError(ctx, http.StatusBadRequest, fmt.Errorf("unsupported format: %s", format))
+		return
+	}
+	filter := ctx.Query("filter")
+	if _, err := regexp.Compile(filter); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	stats, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetStats(ctx.Query("namespace"), ctx.Query("pod"), format, filter)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, stats)
+}
+
+// GetCDSDetail
+// @Description 获取边车单个集群的完整配置, 包括熔断、异常检测、TLS等
+// @Summary  获取边车单个集群的完整配置
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Param	name		query		string		true		"集群名称"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/cds/detail [get]
+func GetCDSDetail(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	format, err := detailFormat(ctx)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	detail, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetClusterDetail(ctx.Query("namespace"), ctx.Query("pod"), ctx.Query("name"), format)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, detail)
+}
+
+// GetLDSDetail
+// @Description 获取边车单个监听器的完整配置, 包括所有过滤器链和过滤器
+// @Summary  获取边车单个监听器的完整配置
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Param	address		query		string		true		"address:port或监听器名称"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/lds/detail [get]
+func GetLDSDetail(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	format, err := detailFormat(ctx)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	detail, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetListenerDetail(ctx.Query("namespace"), ctx.Query("pod"), ctx.Query("address"), format)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, detail)
+}
+
+// GetRDSDetail
+// @Description 获取边车单个路由的完整配置, 包括header匹配、重试、超时、权重等
+// @Summary  获取边车单个路由的完整配置
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Param	name		query		string		true		"路由名称"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/rds/detail [get]
+func GetRDSDetail(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	format, err := detailFormat(ctx)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	detail, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetRouteDetail(ctx.Query("namespace"), ctx.Query("pod"), ctx.Query("name"), format)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, detail)
+}
+
+func detailFormat(ctx *gin.Context) (string, error) {
+	format := ctx.DefaultQuery("format", sidecar.DetailFormatJSON)
+	if format != sidecar.DetailFormatJSON && format != sidecar.DetailFormatYAML {
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+	return format, nil
+}
+
+// ListConfigOrigins
+// @Description 获取边车的集群、监听器、过滤器和路由分别由哪个istio资源生成(VirtualService/DestinationRule/Gateway/EnvoyFilter/HTTPRoute等)
+// @Summary  获取边车配置的来源
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	pod			query		string		true		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/origin [get]
+func ListConfigOrigins(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	istioClient := istio.GetIstioClient(kubeConfig)
+	if istioClient == nil {
+		ResponseData(ctx, CodeKubeConnectError, nil)
+		return
+	}
+	namespace := ctx.Query("namespace")
+	envoyFilter := istio.NewEnvoyFilter(istioClient)
+	envoyFilters, err := envoyFilter.List(namespace, nil)
+	if err != nil {
+		ResponseKubeError(ctx, err)
+		return
+	}
+	if namespace != istio.IstioNamespace {
+		rootEnvoyFilters, err := envoyFilter.List(istio.IstioNamespace, nil)
+		if err != nil {
+			ResponseKubeError(ctx, err)
+			return
+		}
+		envoyFilters = append(envoyFilters, rootEnvoyFilters...)
+	}
+
+	origins, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetConfigOrigins(namespace, ctx.Query("pod"), envoyFilters)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	sidecar.LinkConfigOrigins(kubeConfig.Id, origins)
+	ResponseData(ctx, CodeSuccess, origins)
+}
+
+// DiffSidecar
+// @Description 比较两个边车的集群、监听器、路由和端点配置, 两个边车可以在不同的集群, targetId为空时与id相同
+// @Summary  比较两个边车的配置
+// @Tags 	sidecar
+// @Param	id					query		int64		true		"id"
+// @Param	namespace			query		string		true		"namespace"
+// @Param	pod					query		string		true		"pod"
+// @Param	targetId			query		int64		false		"targetId"
+// @Param	targetNamespace		query		string		true		"targetNamespace"
+// @Param	targetPod			query		string		true		"targetPod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/diff [get]
+func DiffSidecar(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	targetId := id
+	if targetIdStr := ctx.Query("targetId"); targetIdStr != "" {
+		targetId, err = strconv.ParseInt(targetIdStr, 10, 64)
+		if err != nil {
+			ResponseError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	targetKubeConfig, err := model.KubeConfigDB.GetKubeConfigById(targetId)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	diff, err := sidecar.DiffSidecar(
+		sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)),
+		ctx.Query("namespace"), ctx.Query("pod"),
+		sidecar.NewSidecar(targetKubeConfig.Cid, kube.GetConfigStoreKubeConfig(targetKubeConfig)),
+		ctx.Query("targetNamespace"), ctx.Query("targetPod"))
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, diff)
+}
+
+type SimulateRequest struct {
+	Id            int64             `json:"id" binding:"required"`
+	Namespace     string            `json:"namespace" binding:"required"`
+	Pod           string            `json:"pod" binding:"required"`
+	Host          string            `json:"host" binding:"required"`
+	Port          uint32            `json:"port" binding:"required"`
+	DestinationIP string            `json:"destinationIp"`
+	Path          string            `json:"path"`
+	Method        string            `json:"method"`
+	Headers       map[string]string `json:"headers"`
+	TLS           bool              `json:"tls"`
+	SourceLabels  map[string]string `json:"sourceLabels"`
+}
+
+// Simulate
+// @Description 模拟从边车发出的请求, 返回经过的监听器、过滤器链、路由配置、虚拟主机、路由、集群和端点, 以及每一步对应的istio资源
+// @Summary  模拟请求的路由
+// @Tags 	sidecar
+// @Param	body		body		SimulateRequest		true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/simulate [post]
+func Simulate(ctx *gin.Context) {
+	req := SimulateRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(req.Id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	result, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		Simulate(req.Namespace, req.Pod, sidecar.SimulateRequest{
+			Host:          req.Host,
+			Port:          req.Port,
+			DestinationIP: req.DestinationIP,
+			Path:          req.Path,
+			Method:        req.Method,
+			Headers:       req.Headers,
+			TLS:           req.TLS,
+			SourceLabels:  req.SourceLabels,
+		})
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	result.LinkSources(kubeConfig.Id)
+	ResponseData(ctx, CodeSuccess, result)
+}
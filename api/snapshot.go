@@ -0,0 +1,186 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+	"github.com/shuxnhs/istio-dashboard/domain/sidecar"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Snapshot struct {
+	Id           int64  `json:"id"`
+	KubeConfigId int64  `json:"kubeConfigId"`
+	Cid          string `json:"cid"`
+	Namespace    string `json:"namespace"`
+	Pod          string `json:"pod"`
+	Description  string `json:"description"`
+	Size         int64  `json:"size"`
+	CreateTime   int64  `json:"createTime"`
+}
+
+type CreateSnapshotRequest struct {
+	Id          int64  `json:"id" binding:"required"`
+	Namespace   string `json:"namespace" binding:"required"`
+	Pod         string `json:"pod" binding:"required"`
+	Description string `json:"description"`
+}
+
+func newSnapshot(snapshot *model.ConfigSnapshot) Snapshot {
+	return Snapshot{
+		Id:           snapshot.Id,
+		KubeConfigId: snapshot.KubeConfigId,
+		Cid:          snapshot.Cid,
+		Namespace:    snapshot.Namespace,
+		Pod:          snapshot.Pod,
+		Description:  snapshot.Description,
+		Size:         snapshot.Size,
+		CreateTime:   snapshot.CreateTime,
+	}
+}
+
+// CreateSnapshot
+// @Description 保存边车当前的config_dump快照, 用于事后对比
+// @Summary  保存边车配置快照
+// @Tags 	sidecar
+// @Param	body		body		CreateSnapshotRequest		true		"body"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/snapshot/create [post]
+func CreateSnapshot(ctx *gin.Context) {
+	req := CreateSnapshotRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(req.Id)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+
+	config, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		GetRawConfigDump(req.Namespace, req.Pod)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	compressed, err := sidecar.CompressConfigDump(config)
+	if err != nil {
+		ResponseError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	snapshot := &model.ConfigSnapshot{
+		KubeConfigId: kubeConfig.Id,
+		Cid:          kubeConfig.Cid,
+		Namespace:    req.Namespace,
+		Pod:          req.Pod,
+		Description:  req.Description,
+		ConfigDump:   compressed,
+		Size:         int64(len(config)),
+		CreateTime:   time.Now().Unix(),
+	}
+	if err = model.ConfigSnapshotDB.CreateConfigSnapshot(snapshot); err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, newSnapshot(snapshot))
+}
+
+// ListSnapshots
+// @Description 获取边车配置快照列表, 按创建时间倒序
+// @Summary  获取边车配置快照列表
+// @Tags 	sidecar
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"namespace"
+// @Param	pod			query		string		false		"pod"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/snapshot/list [get]
+func ListSnapshots(ctx *gin.Context) {
+	idStr := ctx.Query("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	snapshots, err := model.ConfigSnapshotDB.ListConfigSnapshot(id, ctx.Query("namespace"), ctx.Query("pod"))
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	snapshotRsp := make([]Snapshot, 0)
+	for i := range *snapshots {
+		snapshotRsp = append(snapshotRsp, newSnapshot(&(*snapshots)[i]))
+	}
+	ResponseData(ctx, CodeSuccess, snapshotRsp)
+}
+
+// DiffSnapshot
+// @Description 比较快照与另一个快照, targetSnapshotId为空时与快照所属边车当前的配置比较
+// @Summary  比较边车配置快照
+// @Tags 	sidecar
+// @Param	snapshotId			query		int64		true		"snapshotId"
+// @Param	targetSnapshotId	query		int64		false		"targetSnapshotId"
+// @Success 200 {object} Result  "ok"
+// @Router /sidecar/snapshot/diff [get]
+func DiffSnapshot(ctx *gin.Context) {
+	snapshotIdStr := ctx.Query("snapshotId")
+	snapshotId, err := strconv.ParseInt(snapshotIdStr, 10, 64)
+	if err != nil {
+		ResponseError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	var targetSnapshotId int64
+	if targetSnapshotIdStr := ctx.Query("targetSnapshotId"); targetSnapshotIdStr != "" {
+		targetSnapshotId, err = strconv.ParseInt(targetSnapshotIdStr, 10, 64)
+		if err != nil {
+			ResponseError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	snapshot, err := model.ConfigSnapshotDB.GetConfigSnapshotById(snapshotId)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	from, err := sidecar.DecompressConfigDump(snapshot.ConfigDump)
+	if err != nil {
+		ResponseError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if targetSnapshotId != 0 {
+		targetSnapshot, err := model.ConfigSnapshotDB.GetConfigSnapshotById(targetSnapshotId)
+		if err != nil {
+			ResponseData(ctx, CodeDbError, nil)
+			return
+		}
+		to, err := sidecar.DecompressConfigDump(targetSnapshot.ConfigDump)
+		if err != nil {
+			ResponseError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		ResponseData(ctx, CodeSuccess, sidecar.DiffConfigDump(from, to))
+		return
+	}
+
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(snapshot.KubeConfigId)
+	if err != nil {
+		ResponseData(ctx, CodeDbError, nil)
+		return
+	}
+	diff, err := sidecar.NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig)).
+		DiffSnapshot(from, snapshot.Namespace, snapshot.Pod)
+	if err != nil {
+		Response(ctx, http.StatusOK, CodeKubeConnectError, err.Error(), nil)
+		return
+	}
+	ResponseData(ctx, CodeSuccess, diff)
+}
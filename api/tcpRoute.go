@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/gatewayapi"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var tcpRouteHandler = &resourceHandler{
+	gvk:       gatewayapi.GVKTCPRoute,
+	newObject: func() runtime.Object { return &v1alpha2.TCPRoute{} },
+	client: gatewayAPIResourceClient(func(cli *gatewayapi.GatewayAPIClient) *resourceClient {
+		tcpRoute := gatewayapi.NewTCPRoute(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				tcpRoutes, err := tcpRoute.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(tcpRoutes))
+				for _, route := range tcpRoutes {
+					objects = append(objects, route)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return tcpRoute.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return tcpRoute.Create(obj.(*v1alpha2.TCPRoute), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return tcpRoute.Update(obj.(*v1alpha2.TCPRoute), dryRun)
+			},
+			delete: tcpRoute.Delete,
+		}
+	}),
+}
+
+// ListTCPRoutes
+// @Description 获取命名空间下的TCPRoute, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取TCPRoute列表
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tcproute/list [get]
+func ListTCPRoutes(ctx *gin.Context) {
+	tcpRouteHandler.list(ctx)
+}
+
+// GetTCPRoute
+// @Description 获取TCPRoute, format为yaml时返回yaml字符串
+// @Summary  获取TCPRoute
+// @Tags 	gatewayapi
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tcproute/get [get]
+func GetTCPRoute(ctx *gin.Context) {
+	tcpRouteHandler.get(ctx)
+}
+
+// CreateTCPRoute
+// @Description 创建TCPRoute, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建TCPRoute
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"TCPRoute"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tcproute/create [post]
+func CreateTCPRoute(ctx *gin.Context) {
+	tcpRouteHandler.create(ctx)
+}
+
+// UpdateTCPRoute
+// @Description 更新TCPRoute, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新TCPRoute
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"TCPRoute"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tcproute/update [post]
+func UpdateTCPRoute(ctx *gin.Context) {
+	tcpRouteHandler.update(ctx)
+}
+
+// DeleteTCPRoute
+// @Description 删除TCPRoute, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除TCPRoute
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tcproute/delete [post]
+func DeleteTCPRoute(ctx *gin.Context) {
+	tcpRouteHandler.delete(ctx)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/gatewayapi"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+var tlsRouteHandler = &resourceHandler{
+	gvk:       gatewayapi.GVKTLSRoute,
+	newObject: func() runtime.Object { return &v1alpha2.TLSRoute{} },
+	client: gatewayAPIResourceClient(func(cli *gatewayapi.GatewayAPIClient) *resourceClient {
+		tlsRoute := gatewayapi.NewTLSRoute(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				tlsRoutes, err := tlsRoute.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(tlsRoutes))
+				for _, route := range tlsRoutes {
+					objects = append(objects, route)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return tlsRoute.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return tlsRoute.Create(obj.(*v1alpha2.TLSRoute), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return tlsRoute.Update(obj.(*v1alpha2.TLSRoute), dryRun)
+			},
+			delete: tlsRoute.Delete,
+		}
+	}),
+}
+
+// ListTLSRoutes
+// @Description 获取命名空间下的TLSRoute, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取TLSRoute列表
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tlsroute/list [get]
+func ListTLSRoutes(ctx *gin.Context) {
+	tlsRouteHandler.list(ctx)
+}
+
+// GetTLSRoute
+// @Description 获取TLSRoute, format为yaml时返回yaml字符串
+// @Summary  获取TLSRoute
+// @Tags 	gatewayapi
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tlsroute/get [get]
+func GetTLSRoute(ctx *gin.Context) {
+	tlsRouteHandler.get(ctx)
+}
+
+// CreateTLSRoute
+// @Description 创建TLSRoute, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建TLSRoute
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"TLSRoute"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tlsroute/create [post]
+func CreateTLSRoute(ctx *gin.Context) {
+	tlsRouteHandler.create(ctx)
+}
+
+// UpdateTLSRoute
+// @Description 更新TLSRoute, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新TLSRoute
+// @Tags 	gatewayapi
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"TLSRoute"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tlsroute/update [post]
+func UpdateTLSRoute(ctx *gin.Context) {
+	tlsRouteHandler.update(ctx)
+}
+
+// DeleteTLSRoute
+// @Description 删除TLSRoute, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除TLSRoute
+// @Tags 	gatewayapi
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /gatewayapi/tlsroute/delete [post]
+func DeleteTLSRoute(ctx *gin.Context) {
+	tlsRouteHandler.delete(ctx)
+}
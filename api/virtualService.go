@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	"github.com/gin-gonic/gin"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var virtualServiceHandler = &resourceHandler{
+	gvk:       istio.GVKVirtualService,
+	newObject: func() runtime.Object { return &v1alpha3.VirtualService{} },
+	client: istioResourceClient(func(cli *istio.IstioClient) *resourceClient {
+		virtualService := istio.NewVirtualService(cli)
+		return &resourceClient{
+			list: func(namespace string, label map[string]string) ([]runtime.Object, error) {
+				virtualServices, err := virtualService.List(namespace, label)
+				objects := make([]runtime.Object, 0, len(virtualServices))
+				for _, vs := range virtualServices {
+					objects = append(objects, vs)
+				}
+				return objects, err
+			},
+			get: func(namespace, name string) (runtime.Object, error) {
+				return virtualService.Get(namespace, name)
+			},
+			create: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return virtualService.Create(obj.(*v1alpha3.VirtualService), dryRun)
+			},
+			update: func(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+				return virtualService.Update(obj.(*v1alpha3.VirtualService), dryRun)
+			},
+			delete: virtualService.Delete,
+		}
+	}),
+}
+
+// ListVirtualServices
+// @Description 获取命名空间下的VirtualService, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤
+// @Summary  获取VirtualService列表
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		false		"namespace"
+// @Param	allNamespaces	query		bool		false		"allNamespaces"
+// @Param	labelSelector	query		string		false		"app=foo,version=v1"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/virtualservice/list [get]
+func ListVirtualServices(ctx *gin.Context) {
+	virtualServiceHandler.list(ctx)
+}
+
+// GetVirtualService
+// @Description 获取VirtualService, format为yaml时返回yaml字符串
+// @Summary  获取VirtualService
+// @Tags 	istio
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		true		"namespace"
+// @Param	name		query		string		true		"name"
+// @Param	format		query		string		false		"json|yaml"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/virtualservice/get [get]
+func GetVirtualService(ctx *gin.Context) {
+	virtualServiceHandler.get(ctx)
+}
+
+// CreateVirtualService
+// @Description 创建VirtualService, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建
+// @Summary  创建VirtualService
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"VirtualService"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/virtualservice/create [post]
+func CreateVirtualService(ctx *gin.Context) {
+	virtualServiceHandler.create(ctx)
+}
+
+// UpdateVirtualService
+// @Description 更新VirtualService, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict
+// @Summary  更新VirtualService
+// @Tags 	istio
+// @Accept	json,application/x-yaml
+// @Param	id			query		int64		true		"id"
+// @Param	namespace	query		string		false		"资源中没有指定命名空间时使用"
+// @Param	dryRun		query		bool		false		"dryRun"
+// @Param	body		body		string		true		"VirtualService"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/virtualservice/update [post]
+func UpdateVirtualService(ctx *gin.Context) {
+	virtualServiceHandler.update(ctx)
+}
+
+// DeleteVirtualService
+// @Description 删除VirtualService, resourceVersion不为空时只有版本一致才会删除
+// @Summary  删除VirtualService
+// @Tags 	istio
+// @Param	id				query		int64		true		"id"
+// @Param	namespace		query		string		true		"namespace"
+// @Param	name			query		string		true		"name"
+// @Param	resourceVersion	query		string		false		"resourceVersion"
+// @Param	dryRun			query		bool		false		"dryRun"
+// @Success 200 {object} Result  "ok"
+// @Router /istio/virtualservice/delete [post]
+func DeleteVirtualService(ctx *gin.Context) {
+	virtualServiceHandler.delete(ctx)
+}
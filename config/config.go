@@ -18,6 +18,8 @@ type GlobalConfig struct {
 
 type WebConfig struct {
 	ListenPort int `yaml:"ListenPort" env:"LISTEN_PORT" envDefault:"9655"`
+	// MaxUploadSize 上传文件以及bug-report中解压后所有config_dump的总大小上限, 单位为字节
+	MaxUploadSize int64 `yaml:"MaxUploadSize" env:"MAX_UPLOAD_SIZE" envDefault:"67108864"`
 }
 
 type LogConfig struct {
@@ -28,4 +28,115 @@ CREATE TABLE `kube_config` (
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
 
+-- ----------------------------
+-- Table structure for config_snapshot
+-- ----------------------------
+DROP TABLE IF EXISTS `config_snapshot`;
+CREATE TABLE `config_snapshot` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `kube_config_id` int(10) unsigned NOT NULL COMMENT 'kube_config的id',
+  `cid` varchar(255) NOT NULL COMMENT '集群id',
+  `namespace` varchar(255) NOT NULL COMMENT '命名空间',
+  `pod` varchar(255) NOT NULL COMMENT 'pod名称',
+  `description` varchar(1024) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '描述',
+  `config_dump` longblob NOT NULL COMMENT 'gzip压缩的config_dump',
+  `size` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '压缩前的大小',
+  `create_time` int(11) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_pod` (`kube_config_id`, `namespace`, `pod`, `create_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ----------------------------
+-- Table structure for offline_dump
+-- ----------------------------
+DROP TABLE IF EXISTS `offline_dump`;
+CREATE TABLE `offline_dump` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `file_name` varchar(255) NOT NULL DEFAULT '' COMMENT '上传的文件名',
+  `namespace` varchar(255) NOT NULL DEFAULT '' COMMENT '命名空间, 来自bug-report时有值',
+  `pod` varchar(255) NOT NULL DEFAULT '' COMMENT 'pod名称, 来自bug-report时有值',
+  `description` varchar(1024) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '描述',
+  `config_dump` longblob NOT NULL COMMENT 'gzip压缩的config_dump',
+  `size` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '压缩前的大小',
+  `create_time` int(11) NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ----------------------------
+-- Table structure for canary_rollout
+-- ----------------------------
+DROP TABLE IF EXISTS `canary_rollout`;
+CREATE TABLE `canary_rollout` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `kube_config_id` int(10) unsigned NOT NULL COMMENT 'kube_config的id',
+  `cid` varchar(255) NOT NULL COMMENT '集群id',
+  `namespace` varchar(255) NOT NULL COMMENT '命名空间',
+  `service` varchar(255) NOT NULL COMMENT '服务名称',
+  `version_label` varchar(255) NOT NULL DEFAULT 'version' COMMENT '区分版本的pod标签',
+  `stable_version` varchar(255) NOT NULL COMMENT '稳定版本, 同时作为subset名称',
+  `canary_version` varchar(255) NOT NULL COMMENT '金丝雀版本, 同时作为subset名称',
+  `virtual_service` varchar(255) NOT NULL DEFAULT '' COMMENT '调整权重的VirtualService',
+  `destination_rule` varchar(255) NOT NULL DEFAULT '' COMMENT '定义subset的DestinationRule',
+  `steps` varchar(255) NOT NULL COMMENT '逗号分隔的金丝雀版本权重, 如 5,25,50,100',
+  `step_interval` int(10) unsigned NOT NULL COMMENT '每一步观察的时间, 单位秒',
+  `max_error_rate` double NOT NULL DEFAULT '0' COMMENT '错误率阈值, 0-1, 0表示不检查',
+  `max_p99_latency` double NOT NULL DEFAULT '0' COMMENT 'p99延迟阈值, 单位毫秒, 0表示不检查',
+  `current_step` int(10) NOT NULL DEFAULT '0' COMMENT '当前步骤的下标',
+  `canary_weight` int(10) NOT NULL DEFAULT '0' COMMENT '当前金丝雀版本的权重',
+  `previous_weight` int(10) NOT NULL DEFAULT '0' COMMENT '上一步金丝雀版本的权重, 回滚时使用',
+  `status` varchar(32) NOT NULL COMMENT '状态 running/paused/succeeded/rolledback/aborted',
+  `message` varchar(1024) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '最近一次检查的结果',
+  `next_check_time` int(11) NOT NULL DEFAULT '0' COMMENT '下一次检查的时间',
+  `create_time` int(11) NOT NULL,
+  `update_time` int(11) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_service` (`kube_config_id`, `namespace`, `service`),
+  KEY `idx_status` (`status`, `next_check_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ----------------------------
+-- Table structure for fault_experiment
+-- ----------------------------
+DROP TABLE IF EXISTS `fault_experiment`;
+CREATE TABLE `fault_experiment` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `kube_config_id` int(10) unsigned NOT NULL COMMENT 'kube_config的id',
+  `cid` varchar(255) NOT NULL COMMENT '集群id',
+  `namespace` varchar(255) NOT NULL COMMENT '命名空间',
+  `service` varchar(255) NOT NULL COMMENT '服务名称',
+  `virtual_service` varchar(255) NOT NULL COMMENT '注入故障的VirtualService',
+  `created_virtual_service` tinyint(1) NOT NULL DEFAULT '0' COMMENT 'VirtualService是否由实验创建, 结束时一起删除',
+  `route_match` text NOT NULL COMMENT 'json格式的匹配条件, 为空时作用于所有请求',
+  `percentage` double NOT NULL COMMENT '注入故障的请求比例, 0-100',
+  `delay` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '延迟, 单位毫秒, 0表示不注入',
+  `abort_status` int(10) unsigned NOT NULL DEFAULT '0' COMMENT '中断返回的http状态码, 0表示不注入',
+  `ttl` int(10) unsigned NOT NULL COMMENT '持续时间, 单位秒',
+  `status` varchar(32) NOT NULL COMMENT '状态 running/expired/stopped/failed',
+  `message` varchar(1024) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT '' COMMENT '最近一次操作的结果',
+  `expire_time` int(11) NOT NULL COMMENT '到期时间, 到期后自动删除故障',
+  `create_time` int(11) NOT NULL,
+  `update_time` int(11) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_service` (`kube_config_id`, `namespace`, `service`),
+  KEY `idx_status` (`status`, `expire_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- ----------------------------
+-- Table structure for log_level_revert
+-- ----------------------------
+DROP TABLE IF EXISTS `log_level_revert`;
+CREATE TABLE `log_level_revert` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT COMMENT '自增id',
+  `cid` varchar(255) NOT NULL COMMENT '集群id',
+  `namespace` varchar(255) NOT NULL COMMENT '命名空间',
+  `pod` varchar(255) NOT NULL COMMENT 'pod名称',
+  `levels` text NOT NULL COMMENT 'json格式的修改前的日志级别',
+  `revert_time` int(11) NOT NULL COMMENT '恢复时间',
+  `create_time` int(11) NOT NULL,
+  `update_time` int(11) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_pod` (`cid`, `namespace`, `pod`),
+  KEY `idx_revert_time` (`revert_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
 SET FOREIGN_KEY_CHECKS = 1;
@@ -28,20 +28,26 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/kube/namespace/list": {
+        "/gatewayapi/attachment": {
             "get": {
-                "description": "获取所有命名空间",
+                "description": "获取路由在每个parentRef上的挂载状态(Accepted/ResolvedRefs), 以及网关每个监听器挂载的路由数量; 不传namespace时查询所有命名空间",
                 "tags": [
-                    "kube"
+                    "gatewayapi"
                 ],
-                "summary": "获取所有命名空间",
+                "summary": "获取路由挂载状态",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID",
+                        "description": "id",
                         "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -54,13 +60,47 @@ var doc = `{
                 }
             }
         },
-        "/project/list": {
-            "get": {
-                "description": "获取所有的网格",
+        "/gatewayapi/gateway/create": {
+            "post": {
+                "description": "创建Gateway, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
                 "tags": [
-                    "project"
+                    "gatewayapi"
+                ],
+                "summary": "创建Gateway",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Gateway",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
                 ],
-                "summary": "获取所有网格",
                 "responses": {
                     "200": {
                         "description": "ok",
@@ -71,13 +111,13 @@ var doc = `{
                 }
             }
         },
-        "/sidecar/cds/list": {
-            "get": {
-                "description": "获取边车的CDS(集群配置)",
+        "/gatewayapi/gateway/delete": {
+            "post": {
+                "description": "删除Gateway, resourceVersion不为空时只有版本一致才会删除",
                 "tags": [
-                    "sidecar"
+                    "gatewayapi"
                 ],
-                "summary": "获取边车的CDS(集群配置)",
+                "summary": "删除Gateway",
                 "parameters": [
                     {
                         "type": "integer",
@@ -95,10 +135,22 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "pod",
-                        "name": "pod",
+                        "description": "name",
+                        "name": "name",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -111,13 +163,13 @@ var doc = `{
                 }
             }
         },
-        "/sidecar/check": {
+        "/gatewayapi/gateway/get": {
             "get": {
-                "description": "检查边车配置是否同步",
+                "description": "获取Gateway, format为yaml时返回yaml字符串",
                 "tags": [
-                    "sidecar"
+                    "gatewayapi"
                 ],
-                "summary": "检查边车配置是否同步",
+                "summary": "获取Gateway",
                 "parameters": [
                     {
                         "type": "integer",
@@ -135,10 +187,16 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "pod",
-                        "name": "pod",
+                        "description": "name",
+                        "name": "name",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -151,13 +209,13 @@ var doc = `{
                 }
             }
         },
-        "/sidecar/eds/list": {
+        "/gatewayapi/gateway/list": {
             "get": {
-                "description": "获取边车的EDS(端点配置)",
+                "description": "获取命名空间下的Gateway, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
                 "tags": [
-                    "sidecar"
+                    "gatewayapi"
                 ],
-                "summary": "获取边车的EDS(端点配置)",
+                "summary": "获取Gateway列表",
                 "parameters": [
                     {
                         "type": "integer",
@@ -170,15 +228,115 @@ var doc = `{
                         "type": "string",
                         "description": "namespace",
                         "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/gateway/update": {
+            "post": {
+                "description": "更新Gateway, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "更新Gateway",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "pod",
-                        "name": "pod",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Gateway",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/gatewayclass/create": {
+            "post": {
+                "description": "创建GatewayClass, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "创建GatewayClass",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "GatewayClass",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 ],
                 "responses": {
@@ -191,13 +349,13 @@ var doc = `{
                 }
             }
         },
-        "/sidecar/lds/list": {
-            "get": {
-                "description": "获取边车的LDS(所有监听端口)",
+        "/gatewayapi/gatewayclass/delete": {
+            "post": {
+                "description": "删除GatewayClass, resourceVersion不为空时只有版本一致才会删除",
                 "tags": [
-                    "sidecar"
+                    "gatewayapi"
                 ],
-                "summary": "获取边车的LDS(所有监听端口)",
+                "summary": "删除GatewayClass",
                 "parameters": [
                     {
                         "type": "integer",
@@ -208,17 +366,61 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "namespace",
-                        "name": "namespace",
+                        "description": "name",
+                        "name": "name",
                         "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "pod",
-                        "name": "pod",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/gatewayclass/get": {
+            "get": {
+                "description": "获取GatewayClass, format为yaml时返回yaml字符串",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取GatewayClass",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -231,13 +433,141 @@ var doc = `{
                 }
             }
         },
-        "/sidecar/rds/list": {
+        "/gatewayapi/gatewayclass/list": {
             "get": {
-                "description": "获取边车的RDS(路由)",
+                "description": "获取GatewayClass, GatewayClass是集群级别的资源, 可按labelSelector过滤",
                 "tags": [
-                    "sidecar"
+                    "gatewayapi"
                 ],
-                "summary": "获取边车的RDS(路由)",
+                "summary": "获取GatewayClass列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/gatewayclass/update": {
+            "post": {
+                "description": "更新GatewayClass, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "更新GatewayClass",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "GatewayClass",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/httproute/create": {
+            "post": {
+                "description": "创建HTTPRoute, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "创建HTTPRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "HTTPRoute",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/httproute/delete": {
+            "post": {
+                "description": "删除HTTPRoute, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "删除HTTPRoute",
                 "parameters": [
                     {
                         "type": "integer",
@@ -255,10 +585,22 @@ var doc = `{
                     },
                     {
                         "type": "string",
-                        "description": "pod",
-                        "name": "pod",
+                        "description": "name",
+                        "name": "name",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -270,9 +612,3888 @@ var doc = `{
                     }
                 }
             }
-        }
+        },
+        "/gatewayapi/httproute/get": {
+            "get": {
+                "description": "获取HTTPRoute, format为yaml时返回yaml字符串",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取HTTPRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/httproute/list": {
+            "get": {
+                "description": "获取命名空间下的HTTPRoute, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取HTTPRoute列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/httproute/update": {
+            "post": {
+                "description": "更新HTTPRoute, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "更新HTTPRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "HTTPRoute",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/referencegrant/create": {
+            "post": {
+                "description": "创建ReferenceGrant, 请求体为yaml或json, kind可以是ReferenceGrant或ReferencePolicy, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "创建ReferenceGrant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "ReferenceGrant",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/referencegrant/delete": {
+            "post": {
+                "description": "删除ReferenceGrant, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "删除ReferenceGrant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/referencegrant/get": {
+            "get": {
+                "description": "获取ReferenceGrant, format为yaml时返回yaml字符串",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取ReferenceGrant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/referencegrant/list": {
+            "get": {
+                "description": "获取命名空间下的ReferenceGrant, 集群只提供ReferencePolicy时返回ReferencePolicy, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取ReferenceGrant列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/referencegrant/update": {
+            "post": {
+                "description": "更新ReferenceGrant, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "更新ReferenceGrant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "ReferenceGrant",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tcproute/create": {
+            "post": {
+                "description": "创建TCPRoute, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "创建TCPRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "TCPRoute",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tcproute/delete": {
+            "post": {
+                "description": "删除TCPRoute, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "删除TCPRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tcproute/get": {
+            "get": {
+                "description": "获取TCPRoute, format为yaml时返回yaml字符串",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取TCPRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tcproute/list": {
+            "get": {
+                "description": "获取命名空间下的TCPRoute, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取TCPRoute列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tcproute/update": {
+            "post": {
+                "description": "更新TCPRoute, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "更新TCPRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "TCPRoute",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tlsroute/create": {
+            "post": {
+                "description": "创建TLSRoute, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "创建TLSRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "TLSRoute",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tlsroute/delete": {
+            "post": {
+                "description": "删除TLSRoute, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "删除TLSRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tlsroute/get": {
+            "get": {
+                "description": "获取TLSRoute, format为yaml时返回yaml字符串",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取TLSRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tlsroute/list": {
+            "get": {
+                "description": "获取命名空间下的TLSRoute, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "获取TLSRoute列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/gatewayapi/tlsroute/update": {
+            "post": {
+                "description": "更新TLSRoute, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "gatewayapi"
+                ],
+                "summary": "更新TLSRoute",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "TLSRoute",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/analyze": {
+            "get": {
+                "description": "使用istioctl analyze的分析器检查informer缓存中的istio及k8s资源, 依赖Secret的分析器不会执行; 不传namespace时返回所有命名空间的消息",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "分析istio配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "返回消息的最低级别 Info|Warning|Error, 默认Info",
+                        "name": "level",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/destinationrule/create": {
+            "post": {
+                "description": "创建DestinationRule, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "创建DestinationRule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "DestinationRule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/destinationrule/delete": {
+            "post": {
+                "description": "删除DestinationRule, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "删除DestinationRule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/destinationrule/get": {
+            "get": {
+                "description": "获取DestinationRule, format为yaml时返回yaml字符串",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取DestinationRule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/destinationrule/list": {
+            "get": {
+                "description": "获取命名空间下的DestinationRule, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取DestinationRule列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/destinationrule/update": {
+            "post": {
+                "description": "更新DestinationRule, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "更新DestinationRule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "DestinationRule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/envoyfilter/create": {
+            "post": {
+                "description": "创建EnvoyFilter, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "创建EnvoyFilter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "EnvoyFilter",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/envoyfilter/delete": {
+            "post": {
+                "description": "删除EnvoyFilter, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "删除EnvoyFilter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/envoyfilter/get": {
+            "get": {
+                "description": "获取EnvoyFilter, format为yaml时返回yaml字符串",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取EnvoyFilter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/envoyfilter/list": {
+            "get": {
+                "description": "获取命名空间下的EnvoyFilter, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取EnvoyFilter列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/envoyfilter/update": {
+            "post": {
+                "description": "更新EnvoyFilter, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "更新EnvoyFilter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "EnvoyFilter",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/gateway/create": {
+            "post": {
+                "description": "创建Gateway, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "创建Gateway",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Gateway",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/gateway/delete": {
+            "post": {
+                "description": "删除Gateway, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "删除Gateway",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/gateway/get": {
+            "get": {
+                "description": "获取Gateway, format为yaml时返回yaml字符串",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取Gateway",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/gateway/list": {
+            "get": {
+                "description": "获取命名空间下的Gateway, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取Gateway列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/gateway/update": {
+            "post": {
+                "description": "更新Gateway, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "更新Gateway",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Gateway",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/resilience/get": {
+            "get": {
+                "description": "获取服务的连接池、异常检测(来自DestinationRule)和每条路由的重试、超时(来自VirtualService)",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取服务弹性策略",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service",
+                        "name": "service",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/resilience/set": {
+            "post": {
+                "description": "以patch的方式修改服务的弹性策略, 只修改请求中设置的字段, remove中的策略会被删除; 返回修改后的策略, 可以用 /istio/resilience/sync 确认边车已经生效",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "修改服务弹性策略",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/istio.ResiliencePatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/resilience/sync": {
+            "get": {
+                "description": "对比边车CDS/RDS中服务的集群和路由与当前弹性策略是否一致, pod一般选择调用该服务的客户端",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "检查弹性策略是否生效",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service",
+                        "name": "service",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod namespace",
+                        "name": "podNamespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/routingrule/apply": {
+            "post": {
+                "description": "应用路由规则, resourceVersion为预览时返回的版本, 期间VirtualService被修改过时返回Conflict, 需要重新预览",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "应用路由规则",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/istio.RoutingRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/routingrule/preview": {
+            "post": {
+                "description": "按请求头、cookie、查询参数或来源标签把请求路由到服务的subset, 规则合并到服务的VirtualService中, 放在第一条会匹配到这些请求的路由之前; 返回合并前后的yaml差异, 不会修改集群",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "预览路由规则",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/istio.RoutingRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/serviceentry/create": {
+            "post": {
+                "description": "创建ServiceEntry, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "创建ServiceEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "ServiceEntry",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/serviceentry/delete": {
+            "post": {
+                "description": "删除ServiceEntry, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "删除ServiceEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/serviceentry/get": {
+            "get": {
+                "description": "获取ServiceEntry, format为yaml时返回yaml字符串",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取ServiceEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/serviceentry/list": {
+            "get": {
+                "description": "获取命名空间下的ServiceEntry, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取ServiceEntry列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/serviceentry/update": {
+            "post": {
+                "description": "更新ServiceEntry, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "更新ServiceEntry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "ServiceEntry",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/version": {
+            "get": {
+                "description": "获取每种istio资源在集群中提供的版本, 以及与集群协商后实际使用的版本",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取istio资源的版本",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/virtualservice/create": {
+            "post": {
+                "description": "创建VirtualService, 请求体为yaml或json, dryRun为true时只做服务端校验不会真正创建",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "创建VirtualService",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "VirtualService",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/virtualservice/delete": {
+            "post": {
+                "description": "删除VirtualService, resourceVersion不为空时只有版本一致才会删除",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "删除VirtualService",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "resourceVersion",
+                        "name": "resourceVersion",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/virtualservice/get": {
+            "get": {
+                "description": "获取VirtualService, format为yaml时返回yaml字符串",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取VirtualService",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/virtualservice/list": {
+            "get": {
+                "description": "获取命名空间下的VirtualService, allNamespaces为true时获取所有命名空间, 可按labelSelector过滤",
+                "tags": [
+                    "istio"
+                ],
+                "summary": "获取VirtualService列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "allNamespaces",
+                        "name": "allNamespaces",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "app=foo,version=v1",
+                        "name": "labelSelector",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/istio/virtualservice/update": {
+            "post": {
+                "description": "更新VirtualService, 请求体中必须带上读取时的metadata.resourceVersion, 期间被修改过时返回Conflict",
+                "consumes": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "istio"
+                ],
+                "summary": "更新VirtualService",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源中没有指定命名空间时使用",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "VirtualService",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/kube/namespace/list": {
+            "get": {
+                "description": "获取所有命名空间",
+                "tags": [
+                    "kube"
+                ],
+                "summary": "获取所有命名空间",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/offline/list": {
+            "get": {
+                "description": "获取上传的config_dump列表",
+                "tags": [
+                    "offline"
+                ],
+                "summary": "获取上传的config_dump列表",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/offline/upload": {
+            "post": {
+                "description": "上传envoy的config_dump(json)或 istioctl bug-report 的tar.gz包, bug-report中的每个边车保存为一条记录; 文件或解压后的config_dump总大小超过 MaxUploadSize 时返回413",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "offline"
+                ],
+                "summary": "上传config_dump用于离线分析",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "config_dump或bug-report",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "description",
+                        "name": "description",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/offline/view": {
+            "get": {
+                "description": "获取上传的config_dump的CDS/LDS/RDS/EDS, EDS需要config_dump包含端点(config_dump?include_eds)",
+                "tags": [
+                    "offline"
+                ],
+                "summary": "获取上传的config_dump的xDS视图",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/project/list": {
+            "get": {
+                "description": "获取所有的网格",
+                "tags": [
+                    "project"
+                ],
+                "summary": "获取所有网格",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/bootstrap": {
+            "get": {
+                "description": "获取边车的启动配置",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的启动配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/cds/detail": {
+            "get": {
+                "description": "获取边车单个集群的完整配置, 包括熔断、异常检测、TLS等",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车单个集群的完整配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "集群名称",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/cds/list": {
+            "get": {
+                "description": "获取边车的CDS(集群配置)",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的CDS(集群配置)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/check": {
+            "get": {
+                "description": "检查边车配置是否同步",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "检查边车配置是否同步",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/diff": {
+            "get": {
+                "description": "比较两个边车的集群、监听器、路由和端点配置, 两个边车可以在不同的集群, targetId为空时与id相同",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "比较两个边车的配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "targetId",
+                        "name": "targetId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "targetNamespace",
+                        "name": "targetNamespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "targetPod",
+                        "name": "targetPod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/eds/list": {
+            "get": {
+                "description": "获取边车的EDS(端点配置)",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的EDS(端点配置)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/eds/locality": {
+            "get": {
+                "description": "按优先级和地域汇总边车每个集群的端点数、健康端点数、权重占比和请求统计",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车EDS的地域分布",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/eds/workload": {
+            "get": {
+                "description": "获取边车EDS端点对应的pod、节点、可用区和工作负载, 并标记envoy与k8s不一致的端点",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车EDS端点对应的工作负载",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/lds/detail": {
+            "get": {
+                "description": "获取边车单个监听器的完整配置, 包括所有过滤器链和过滤器",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车单个监听器的完整配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "address:port或监听器名称",
+                        "name": "address",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/lds/list": {
+            "get": {
+                "description": "获取边车的LDS(所有监听端口)",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的LDS(所有监听端口)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/log": {
+            "get": {
+                "description": "获取边车的日志级别",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的日志级别",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "修改边车的日志级别, level修改所有logger, loggers修改指定logger, revertAfter(秒)大于0时到期自动恢复, 服务重启后仍会恢复, 误差不超过10秒",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "修改边车的日志级别",
+                "parameters": [
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SetLogLevelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/origin": {
+            "get": {
+                "description": "获取边车的集群、监听器、过滤器和路由分别由哪个istio资源生成(VirtualService/DestinationRule/Gateway/EnvoyFilter/HTTPRoute等)",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车配置的来源",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/rds/detail": {
+            "get": {
+                "description": "获取边车单个路由的完整配置, 包括header匹配、重试、超时、权重等",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车单个路由的完整配置",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "路由名称",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/rds/list": {
+            "get": {
+                "description": "获取边车的RDS(路由)",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的RDS(路由)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/sds/list": {
+            "get": {
+                "description": "获取边车的SDS(证书)",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的SDS(证书)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/simulate": {
+            "post": {
+                "description": "模拟从边车发出的请求, 返回经过的监听器、过滤器链、路由配置、虚拟主机、路由、集群和端点, 以及每一步对应的istio资源",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "模拟请求的路由",
+                "parameters": [
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SimulateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/snapshot/create": {
+            "post": {
+                "description": "保存边车当前的config_dump快照, 用于事后对比",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "保存边车配置快照",
+                "parameters": [
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateSnapshotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/snapshot/diff": {
+            "get": {
+                "description": "比较快照与另一个快照, targetSnapshotId为空时与快照所属边车当前的配置比较",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "比较边车配置快照",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "snapshotId",
+                        "name": "snapshotId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "targetSnapshotId",
+                        "name": "targetSnapshotId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/snapshot/list": {
+            "get": {
+                "description": "获取边车配置快照列表, 按创建时间倒序",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车配置快照列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/stats": {
+            "get": {
+                "description": "获取边车的指标并按集群和监听器分组, 默认解析prometheus格式; format为json时解析envoy的json输出(不区分counter和gauge), 为prometheus时返回envoy的原始输出",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取边车的指标",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "pod",
+                        "name": "pod",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json|prometheus",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "正则表达式",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/sidecar/status": {
+            "get": {
+                "description": "获取网格内所有边车的配置同步状态",
+                "tags": [
+                    "sidecar"
+                ],
+                "summary": "获取网格内所有边车的配置同步状态",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只返回未同步的边车",
+                        "name": "stale",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/canary/abort": {
+            "post": {
+                "description": "终止运行中或暂停的金丝雀发布, 所有流量切回稳定版本",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "终止金丝雀发布",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "rolloutId",
+                        "name": "rolloutId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/canary/get": {
+            "get": {
+                "description": "获取金丝雀发布的当前步骤、权重及最近一次检查的结果",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "获取金丝雀发布",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "rolloutId",
+                        "name": "rolloutId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/canary/list": {
+            "get": {
+                "description": "获取集群的金丝雀发布列表, 按创建时间倒序",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "获取金丝雀发布列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/canary/pause": {
+            "post": {
+                "description": "暂停运行中的金丝雀发布, 保持当前权重, 不再检查指标",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "暂停金丝雀发布",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "rolloutId",
+                        "name": "rolloutId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/canary/resume": {
+            "post": {
+                "description": "恢复暂停的金丝雀发布, 重新观察一个周期后推进到下一步",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "恢复金丝雀发布",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "rolloutId",
+                        "name": "rolloutId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/canary/start": {
+            "post": {
+                "description": "为服务创建两个版本的subset(按pod的version标签区分), 按steps逐步把流量切到金丝雀版本; 每一步观察stepInterval秒, 通过kiali检查金丝雀版本的错误率和p99延迟, 超过阈值时自动回滚到上一步的权重",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "开始金丝雀发布",
+                "parameters": [
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StartCanaryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/fault/get": {
+            "get": {
+                "description": "获取故障注入实验, message为最近一次操作的结果",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "获取故障注入实验",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "experimentId",
+                        "name": "experimentId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/fault/list": {
+            "get": {
+                "description": "获取集群的故障注入实验列表, 按创建时间倒序",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "获取故障注入实验列表",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/fault/start": {
+            "post": {
+                "description": "对服务满足条件的请求按比例注入延迟或中断, 故障路由复制原本处理这些请求的路由, 到期后自动删除; 同一个服务同时只能有一个实验",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "开始故障注入实验",
+                "parameters": [
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StartFaultRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/fault/stop": {
+            "post": {
+                "description": "提前结束运行中的故障注入实验, 删除注入的故障",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "结束故障注入实验",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "experimentId",
+                        "name": "experimentId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/mirror/compare": {
+            "get": {
+                "description": "通过kiali的服务指标对比主流量和镜像流量的请求量、成功率和p99延迟, 用于验证重写后的版本; route为空时使用第一条有镜像的路由",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "对比镜像流量",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service",
+                        "name": "service",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "route",
+                        "name": "route",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "interval in seconds, default 300",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/mirror/get": {
+            "get": {
+                "description": "获取服务的VirtualService中每条路由到该服务的http路由的流量镜像",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "获取流量镜像",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service",
+                        "name": "service",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/mirror/remove": {
+            "post": {
+                "description": "删除服务路由上的流量镜像, route为空时删除所有路由到该服务的http路由上的镜像",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "删除流量镜像",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "namespace",
+                        "name": "namespace",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "service",
+                        "name": "service",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "route",
+                        "name": "route",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        },
+        "/traffic/mirror/set": {
+            "post": {
+                "description": "把服务的请求按比例镜像到影子subset或其他host, host为空时镜像到服务自身的subset; route为空时修改所有只路由到该服务的http路由, 以patch的方式修改, 不会替换其他配置",
+                "tags": [
+                    "traffic"
+                ],
+                "summary": "设置流量镜像",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dryRun",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/istio.MirrorConfig"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "$ref": "#/definitions/api.Result"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "api.CreateSnapshotRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "namespace",
+                "pod"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "pod": {
+                    "type": "string"
+                }
+            }
+        },
         "api.Result": {
             "type": "object",
             "properties": {
@@ -289,6 +4510,401 @@ var doc = `{
                     "type": "string"
                 }
             }
+        },
+        "api.SetLogLevelRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "namespace",
+                "pod"
+            ],
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "string"
+                },
+                "loggers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "pod": {
+                    "type": "string"
+                },
+                "revertAfter": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SimulateRequest": {
+            "type": "object",
+            "required": [
+                "host",
+                "id",
+                "namespace",
+                "pod",
+                "port"
+            ],
+            "properties": {
+                "destinationIp": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "host": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "pod": {
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "sourceLabels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "tls": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.StartCanaryRequest": {
+            "type": "object",
+            "required": [
+                "canaryVersion",
+                "id",
+                "namespace",
+                "service",
+                "stableVersion",
+                "stepInterval",
+                "steps"
+            ],
+            "properties": {
+                "canaryVersion": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maxErrorRate": {
+                    "type": "number"
+                },
+                "maxP99Latency": {
+                    "type": "number"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "stableVersion": {
+                    "type": "string"
+                },
+                "stepInterval": {
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "versionLabel": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.StartFaultRequest": {
+            "type": "object",
+            "required": [
+                "id",
+                "namespace",
+                "percentage",
+                "service",
+                "ttl"
+            ],
+            "properties": {
+                "abortStatus": {
+                    "type": "integer"
+                },
+                "delay": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "match": {
+                    "type": "object",
+                    "$ref": "#/definitions/istio.RouteMatch"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "percentage": {
+                    "type": "number"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "ttl": {
+                    "type": "integer"
+                }
+            }
+        },
+        "istio.ConnectionPool": {
+            "type": "object",
+            "properties": {
+                "http": {
+                    "type": "object",
+                    "$ref": "#/definitions/istio.HTTPSettings"
+                },
+                "tcp": {
+                    "type": "object",
+                    "$ref": "#/definitions/istio.TCPSettings"
+                }
+            }
+        },
+        "istio.HTTPSettings": {
+            "type": "object",
+            "properties": {
+                "http1MaxPendingRequests": {
+                    "type": "integer"
+                },
+                "http2MaxRequests": {
+                    "type": "integer"
+                },
+                "idleTimeout": {
+                    "type": "string"
+                },
+                "maxRequestsPerConnection": {
+                    "type": "integer"
+                },
+                "maxRetries": {
+                    "type": "integer"
+                }
+            }
+        },
+        "istio.MirrorConfig": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "percentage": {
+                    "type": "number"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "subset": {
+                    "type": "string"
+                }
+            }
+        },
+        "istio.OutlierDetection": {
+            "type": "object",
+            "properties": {
+                "baseEjectionTime": {
+                    "type": "string"
+                },
+                "consecutive5xxErrors": {
+                    "type": "integer"
+                },
+                "consecutiveGatewayErrors": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "maxEjectionPercent": {
+                    "type": "integer"
+                },
+                "minHealthPercent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "istio.ResiliencePatch": {
+            "type": "object",
+            "properties": {
+                "connectionPool": {
+                    "type": "object",
+                    "$ref": "#/definitions/istio.ConnectionPool"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "outlierDetection": {
+                    "type": "object",
+                    "$ref": "#/definitions/istio.OutlierDetection"
+                },
+                "remove": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "retries": {
+                    "type": "object",
+                    "$ref": "#/definitions/istio.RetryPolicy"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "service": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "string"
+                }
+            }
+        },
+        "istio.RetryPolicy": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "perTryTimeout": {
+                    "type": "string"
+                },
+                "retryOn": {
+                    "type": "string"
+                }
+            }
+        },
+        "istio.RouteMatch": {
+            "type": "object",
+            "properties": {
+                "cookies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/istio.StringMatch"
+                    }
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/istio.StringMatch"
+                    }
+                },
+                "queryParams": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/istio.StringMatch"
+                    }
+                },
+                "sourceLabels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "istio.RoutingRule": {
+            "type": "object",
+            "properties": {
+                "cookies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/istio.StringMatch"
+                    }
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/istio.StringMatch"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "namespace": {
+                    "type": "string"
+                },
+                "port": {
+                    "type": "integer"
+                },
+                "queryParams": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/istio.StringMatch"
+                    }
+                },
+                "service": {
+                    "type": "string"
+                },
+                "sourceLabels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "subset": {
+                    "type": "string"
+                }
+            }
+        },
+        "istio.StringMatch": {
+            "type": "object",
+            "properties": {
+                "exact": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "regex": {
+                    "type": "string"
+                }
+            }
+        },
+        "istio.TCPSettings": {
+            "type": "object",
+            "properties": {
+                "connectTimeout": {
+                    "type": "string"
+                },
+                "maxConnections": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,258 @@
+package gatewayapi
+
+import (
+	"sort"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+const (
+	// AttachmentAccepted Accepted 和 ResolvedRefs 都为 True
+	AttachmentAccepted = "Accepted"
+	// AttachmentRejected 网关拒绝了路由, 如监听器不允许该命名空间或路由类型、hostname不匹配
+	AttachmentRejected = "Rejected"
+	// AttachmentUnresolvedRefs 路由已挂载, 但有后端无法解析, 如服务不存在或缺少ReferenceGrant
+	AttachmentUnresolvedRefs = "UnresolvedRefs"
+	// AttachmentPending 没有控制器处理该parentRef, 如网关不存在或gatewayClass没有对应的控制器
+	AttachmentPending = "Pending"
+)
+
+type Condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// Stale 条件对应的不是资源的最新版本, 控制器可能还没有处理最新的修改
+	Stale bool `json:"stale"`
+}
+
+type ParentReference struct {
+	Group       string `json:"group"`
+	Kind        string `json:"kind"`
+	Namespace   string `json:"namespace"`
+	Name        string `json:"name"`
+	SectionName string `json:"sectionName"`
+	Link        string `json:"link"`
+}
+
+// RouteAttachment 路由在每个parentRef上的挂载状态
+type RouteAttachment struct {
+	Kind           string          `json:"kind"`
+	Namespace      string          `json:"namespace"`
+	Name           string          `json:"name"`
+	Link           string          `json:"link"`
+	Hostnames      []string        `json:"hostnames"`
+	Parent         ParentReference `json:"parent"`
+	ControllerName string          `json:"controllerName"`
+	Status         string          `json:"status"`
+	Accepted       *Condition      `json:"accepted"`
+	ResolvedRefs   *Condition      `json:"resolvedRefs"`
+}
+
+// ListenerAttachment 网关监听器挂载的路由数量及状态
+type ListenerAttachment struct {
+	Namespace      string      `json:"namespace"`
+	Gateway        string      `json:"gateway"`
+	Link           string      `json:"link"`
+	Listener       string      `json:"listener"`
+	Hostname       string      `json:"hostname"`
+	Port           int32       `json:"port"`
+	Protocol       string      `json:"protocol"`
+	AttachedRoutes int32       `json:"attachedRoutes"`
+	Conditions     []Condition `json:"conditions"`
+}
+
+type AttachmentStatus struct {
+	Routes    []RouteAttachment    `json:"routes"`
+	Listeners []ListenerAttachment `json:"listeners"`
+}
+
+// GetAttachmentStatus 汇总路由的 Accepted/ResolvedRefs 条件和网关监听器挂载的路由, namespace 为空时查询所有命名空间
+func (c *GatewayAPIClient) GetAttachmentStatus(namespace string) (*AttachmentStatus, error) {
+	status := &AttachmentStatus{
+		Routes:    make([]RouteAttachment, 0),
+		Listeners: make([]ListenerAttachment, 0),
+	}
+
+	httpRoutes, err := NewHTTPRoute(c).List(namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range httpRoutes {
+		status.Routes = append(status.Routes, routeAttachments(c.kubeConfigId, GVKHTTPRoute.Kind, route.ObjectMeta,
+			route.Spec.Hostnames, route.Spec.ParentRefs, route.Status.Parents)...)
+	}
+	// TCPRoute 和 TLSRoute 属于实验性的资源, 集群中可能没有安装
+	if c.IsServed(ResourceNameTCPRoute) {
+		tcpRoutes, err := NewTCPRoute(c).List(namespace, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range tcpRoutes {
+			status.Routes = append(status.Routes, routeAttachments(c.kubeConfigId, GVKTCPRoute.Kind, route.ObjectMeta,
+				nil, route.Spec.ParentRefs, route.Status.Parents)...)
+		}
+	}
+	if c.IsServed(ResourceNameTLSRoute) {
+		tlsRoutes, err := NewTLSRoute(c).List(namespace, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range tlsRoutes {
+			status.Routes = append(status.Routes, routeAttachments(c.kubeConfigId, GVKTLSRoute.Kind, route.ObjectMeta,
+				route.Spec.Hostnames, route.Spec.ParentRefs, route.Status.Parents)...)
+		}
+	}
+	sort.SliceStable(status.Routes, func(i, j int) bool {
+		a, b := status.Routes[i], status.Routes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	gateways, err := NewGateway(c).List(namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		if gateways[i].Namespace != gateways[j].Namespace {
+			return gateways[i].Namespace < gateways[j].Namespace
+		}
+		return gateways[i].Name < gateways[j].Name
+	})
+	for _, gateway := range gateways {
+		status.Listeners = append(status.Listeners, listenerAttachments(c.kubeConfigId, gateway)...)
+	}
+	return status, nil
+}
+
+func routeAttachments(kubeConfigId int64, kind string, route metav1.ObjectMeta, hostnames []v1alpha2.Hostname,
+	parentRefs []v1alpha2.ParentReference, parents []v1alpha2.RouteParentStatus) []RouteAttachment {
+	hosts := make([]string, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		hosts = append(hosts, string(hostname))
+	}
+
+	attachments := make([]RouteAttachment, 0, len(parentRefs))
+	for _, ref := range parentRefs {
+		parent := newParentReference(kubeConfigId, ref, route.Namespace)
+		attachment := RouteAttachment{
+			Kind:      kind,
+			Namespace: route.Namespace,
+			Name:      route.Name,
+			Link:      istio.ResourceLink(kubeConfigId, v1alpha2.GroupName, kind, route.Namespace, route.Name),
+			Hostnames: hosts,
+			Parent:    parent,
+			Status:    AttachmentPending,
+		}
+		found := false
+		for _, parentStatus := range parents {
+			if newParentReference(kubeConfigId, parentStatus.ParentRef, route.Namespace) != parent {
+				continue
+			}
+			found = true
+			item := attachment
+			item.ControllerName = string(parentStatus.ControllerName)
+			item.Accepted = findCondition(parentStatus.Conditions, string(v1alpha2.ConditionRouteAccepted), route.Generation)
+			item.ResolvedRefs = findCondition(parentStatus.Conditions, string(v1alpha2.ConditionRouteResolvedRefs), route.Generation)
+			item.Status = attachmentStatus(item.Accepted, item.ResolvedRefs)
+			attachments = append(attachments, item)
+		}
+		if !found {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments
+}
+
+func attachmentStatus(accepted, resolvedRefs *Condition) string {
+	switch {
+	case accepted == nil:
+		return AttachmentPending
+	case accepted.Status != string(metav1.ConditionTrue):
+		return AttachmentRejected
+	case resolvedRefs != nil && resolvedRefs.Status != string(metav1.ConditionTrue):
+		return AttachmentUnresolvedRefs
+	}
+	return AttachmentAccepted
+}
+
+// newParentReference 补全parentRef的默认值, 用于与status中的parentRef比较
+func newParentReference(kubeConfigId int64, ref v1alpha2.ParentReference, routeNamespace string) ParentReference {
+	parent := ParentReference{
+		Group:     v1alpha2.GroupName,
+		Kind:      "Gateway",
+		Namespace: routeNamespace,
+		Name:      string(ref.Name),
+	}
+	if ref.Group != nil && *ref.Group != "" {
+		parent.Group = string(*ref.Group)
+	}
+	if ref.Kind != nil {
+		parent.Kind = string(*ref.Kind)
+	}
+	if ref.Namespace != nil {
+		parent.Namespace = string(*ref.Namespace)
+	}
+	if ref.SectionName != nil {
+		parent.SectionName = string(*ref.SectionName)
+	}
+	parent.Link = istio.ResourceLink(kubeConfigId, parent.Group, parent.Kind, parent.Namespace, parent.Name)
+	return parent
+}
+
+func listenerAttachments(kubeConfigId int64, gateway *v1alpha2.Gateway) []ListenerAttachment {
+	listeners := make([]ListenerAttachment, 0, len(gateway.Spec.Listeners))
+	for _, listener := range gateway.Spec.Listeners {
+		attachment := ListenerAttachment{
+			Namespace:  gateway.Namespace,
+			Gateway:    gateway.Name,
+			Link:       istio.ResourceLink(kubeConfigId, v1alpha2.GroupName, GVKGateway.Kind, gateway.Namespace, gateway.Name),
+			Listener:   string(listener.Name),
+			Port:       int32(listener.Port),
+			Protocol:   string(listener.Protocol),
+			Conditions: make([]Condition, 0),
+		}
+		if listener.Hostname != nil {
+			attachment.Hostname = string(*listener.Hostname)
+		}
+		for _, listenerStatus := range gateway.Status.Listeners {
+			if listenerStatus.Name != listener.Name {
+				continue
+			}
+			attachment.AttachedRoutes = listenerStatus.AttachedRoutes
+			for _, condition := range listenerStatus.Conditions {
+				attachment.Conditions = append(attachment.Conditions, newCondition(condition, gateway.Generation))
+			}
+		}
+		listeners = append(listeners, attachment)
+	}
+	return listeners
+}
+
+func findCondition(conditions []metav1.Condition, conditionType string, generation int64) *Condition {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	if condition == nil {
+		return nil
+	}
+	c := newCondition(*condition, generation)
+	return &c
+}
+
+func newCondition(condition metav1.Condition, generation int64) Condition {
+	return Condition{
+		Type:    condition.Type,
+		Status:  string(condition.Status),
+		Reason:  condition.Reason,
+		Message: condition.Message,
+		Stale:   condition.ObservedGeneration != 0 && condition.ObservedGeneration < generation,
+	}
+}
@@ -0,0 +1,171 @@
+package gatewayapi
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"istio.io/pkg/log"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
+	"sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
+	"sigs.k8s.io/gateway-api/pkg/client/informers/externalversions"
+)
+
+const (
+	defaultGatewayAPIResyncPeriod = 300 * time.Second
+
+	ResourceNameGatewayClass    = "gatewayclasses"
+	ResourceNameGateway         = "gateways"
+	ResourceNameHTTPRoute       = "httproutes"
+	ResourceNameTCPRoute        = "tcproutes"
+	ResourceNameTLSRoute        = "tlsroutes"
+	ResourceNameReferenceGrant  = "referencegrants"
+	ResourceNameReferencePolicy = "referencepolicies"
+)
+
+var domainLog = log.RegisterScope("gatewayapi-domain", "gatewayapi-domain debugging", 0)
+
+// supportedVersions 按优先级排列, 各版本的结构兼容, 都可以解析为 v1alpha2 的类型
+var supportedVersions = []string{"v1", "v1beta1", "v1alpha2"}
+
+var (
+	GVKGatewayClass   = v1alpha2.SchemeGroupVersion.WithKind("GatewayClass")
+	GVKGateway        = v1alpha2.SchemeGroupVersion.WithKind("Gateway")
+	GVKHTTPRoute      = v1alpha2.SchemeGroupVersion.WithKind("HTTPRoute")
+	GVKTCPRoute       = v1alpha2.SchemeGroupVersion.WithKind("TCPRoute")
+	GVKTLSRoute       = v1alpha2.SchemeGroupVersion.WithKind("TLSRoute")
+	GVKReferenceGrant = v1alpha2.SchemeGroupVersion.WithKind("ReferenceGrant")
+	// GVKReferencePolicy ReferenceGrant 在 v0.5 之前的名字
+	GVKReferencePolicy = v1alpha2.SchemeGroupVersion.WithKind("ReferencePolicy")
+)
+
+// KindToGatewayAPIResourceSlice 集群提供 v1alpha2 版本时才会启动informer
+var KindToGatewayAPIResourceSlice = []schema.GroupVersionResource{
+	v1alpha2.SchemeGroupVersion.WithResource(ResourceNameGatewayClass),
+	v1alpha2.SchemeGroupVersion.WithResource(ResourceNameGateway),
+	v1alpha2.SchemeGroupVersion.WithResource(ResourceNameHTTPRoute),
+	v1alpha2.SchemeGroupVersion.WithResource(ResourceNameTCPRoute),
+	v1alpha2.SchemeGroupVersion.WithResource(ResourceNameTLSRoute),
+	v1alpha2.SchemeGroupVersion.WithResource(ResourceNameReferencePolicy),
+}
+
+type GatewayAPIClient struct {
+	kubeConfigId  int64
+	stopChan      chan struct{}
+	restConfig    *rest.Config
+	versions      kube.APIVersions
+	restClientsMu sync.Mutex
+	restClients   map[schema.GroupVersion]rest.Interface
+	*versioned.Clientset
+	externalversions.SharedInformerFactory
+}
+
+func NewGatewayAPIClient(kubeConfig *model.KubeConfig) *GatewayAPIClient {
+	config := kube.GetConfigStoreKubeConfig(kubeConfig)
+	clientSet, err := versioned.NewForConfig(config)
+	if err != nil {
+		domainLog.Errorf("new gateway api client err: %s, config: %#v", err, config)
+		return nil
+	}
+	cli := &GatewayAPIClient{
+		kubeConfigId:          kubeConfig.Id,
+		stopChan:              make(chan struct{}),
+		restConfig:            config,
+		restClients:           make(map[schema.GroupVersion]rest.Interface),
+		Clientset:             clientSet,
+		SharedInformerFactory: externalversions.NewSharedInformerFactory(clientSet, defaultGatewayAPIResyncPeriod),
+	}
+	cli.versions, err = kube.DiscoverAPIVersions(kubeConfig.Cid, clientSet.Discovery(), []string{v1alpha2.GroupName})
+	if err != nil {
+		domainLog.Errorf("discover gateway api versions err: %s", err)
+		cli.versions = make(kube.APIVersions)
+		for _, gvr := range KindToGatewayAPIResourceSlice {
+			cli.versions[gvr.GroupResource()] = []string{gvr.Version}
+		}
+	}
+	for _, gvr := range KindToGatewayAPIResourceSlice {
+		// 没有安装网关API的CRD, 或者集群不再提供 v1alpha2 时不启动informer
+		if !cli.versions.IsServed(gvr) {
+			continue
+		}
+		genericInformer, err := cli.SharedInformerFactory.ForResource(gvr)
+		if err != nil {
+			domainLog.Errorf("new sharedInformerFactory for resource %#v, err: %s", gvr, err)
+			return cli
+		}
+		go genericInformer.Informer().Run(cli.stopChan)
+	}
+	cli.SharedInformerFactory.Start(cli.stopChan)
+	return cli
+}
+
+// Close 停止informer
+func (c *GatewayAPIClient) Close() {
+	close(c.stopChan)
+}
+
+type cachedGatewayAPIClient struct {
+	fingerprint string
+	client      *GatewayAPIClient
+}
+
+var (
+	clientCacheMu sync.Mutex
+	clientCache   = make(map[int64]*cachedGatewayAPIClient)
+)
+
+// GetGatewayAPIClient 每个集群共享一个客户端, informer只启动一次; 集群的连接配置修改后关闭旧的客户端重新创建, 调用方不能关闭返回的客户端
+func GetGatewayAPIClient(kubeConfig *model.KubeConfig) *GatewayAPIClient {
+	fingerprint := kube.ConfigFingerprint(kubeConfig)
+	clientCacheMu.Lock()
+	defer clientCacheMu.Unlock()
+	if cached, ok := clientCache[kubeConfig.Id]; ok {
+		if cached.fingerprint == fingerprint {
+			return cached.client
+		}
+		cached.client.Close()
+		delete(clientCache, kubeConfig.Id)
+	}
+	client := NewGatewayAPIClient(kubeConfig)
+	if client == nil {
+		return nil
+	}
+	clientCache[kubeConfig.Id] = &cachedGatewayAPIClient{fingerprint: fingerprint, client: client}
+	return client
+}
+
+// IsServed 集群是否安装了该资源的CRD
+func (c *GatewayAPIClient) IsServed(resource string) bool {
+	_, err := c.versions.Negotiate(v1alpha2.Resource(resource), supportedVersions)
+	return err == nil
+}
+
+// resource 实际请求的版本由集群提供的版本决定
+func (c *GatewayAPIClient) resource(resource string) *kube.ResourceClient {
+	gvr := v1alpha2.SchemeGroupVersion.WithResource(resource)
+	negotiated, err := c.versions.Negotiate(gvr.GroupResource(), supportedVersions)
+	if err != nil {
+		return kube.NewResourceClientError(gvr, err)
+	}
+
+	c.restClientsMu.Lock()
+	defer c.restClientsMu.Unlock()
+	gv := negotiated.GroupVersion()
+	cli, ok := c.restClients[gv]
+	if !ok {
+		config := rest.CopyConfig(c.restConfig)
+		config.GroupVersion = &gv
+		cli = kube.NewRestClient(config)
+		if cli == nil {
+			return kube.NewResourceClientError(negotiated, fmt.Errorf("new rest client for %s failed", gv))
+		}
+		c.restClients[gv] = cli
+	}
+	return kube.NewResourceClient(cli, negotiated, scheme.Scheme)
+}
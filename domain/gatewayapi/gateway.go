@@ -0,0 +1,74 @@
+package gatewayapi
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	informer "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
+)
+
+type Gateway struct {
+	*GatewayAPIClient
+}
+
+func NewGateway(cli *GatewayAPIClient) *Gateway {
+	return &Gateway{cli}
+}
+
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (g *Gateway) List(namespace string, label map[string]string) ([]*v1alpha2.Gateway, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	gatewayList, err := g.GetGatewayLister().Gateways(namespace).List(selector)
+	if err != nil || len(gatewayList) == 0 {
+		list := &v1alpha2.GatewayList{}
+		if err := g.resource(ResourceNameGateway).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		gatewayList = make([]*v1alpha2.Gateway, 0, len(list.Items))
+		for i := range list.Items {
+			gatewayList = append(gatewayList, &list.Items[i])
+		}
+	}
+	return gatewayList, nil
+}
+
+func (g *Gateway) Get(namespace, gatewayName string) (*v1alpha2.Gateway, error) {
+	gateway, err := g.GetGatewayLister().Gateways(namespace).Get(gatewayName)
+	if err != nil || gateway == nil {
+		gateway = &v1alpha2.Gateway{}
+		if err := g.resource(ResourceNameGateway).Get(namespace, gatewayName, gateway); err != nil {
+			return nil, err
+		}
+	}
+	return gateway, nil
+}
+
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (g *Gateway) Create(gateway *v1alpha2.Gateway, dryRun bool) (*v1alpha2.Gateway, error) {
+	created := &v1alpha2.Gateway{}
+	if err := g.resource(ResourceNameGateway).Create(gateway, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update gateway 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (g *Gateway) Update(gateway *v1alpha2.Gateway, dryRun bool) (*v1alpha2.Gateway, error) {
+	updated := &v1alpha2.Gateway{}
+	if err := g.resource(ResourceNameGateway).Update(gateway, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (g *Gateway) Delete(namespace, gatewayName, resourceVersion string, dryRun bool) error {
+	return g.resource(ResourceNameGateway).Delete(namespace, gatewayName, resourceVersion, dryRun)
+}
+
+func (g *Gateway) GetGatewayLister() informer.GatewayLister {
+	return g.SharedInformerFactory.Gateway().V1alpha2().Gateways().Lister()
+}
@@ -0,0 +1,75 @@
+package gatewayapi
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	informer "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
+)
+
+// GatewayClass 集群级别的资源, 没有命名空间
+type GatewayClass struct {
+	*GatewayAPIClient
+}
+
+func NewGatewayClass(cli *GatewayAPIClient) *GatewayClass {
+	return &GatewayClass{cli}
+}
+
+func (g *GatewayClass) List(label map[string]string) ([]*v1alpha2.GatewayClass, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	gatewayClassList, err := g.GetGatewayClassLister().List(selector)
+	if err != nil || len(gatewayClassList) == 0 {
+		list := &v1alpha2.GatewayClassList{}
+		if err := g.resource(ResourceNameGatewayClass).List(metav1.NamespaceNone, selector, list); err != nil {
+			return nil, err
+		}
+		gatewayClassList = make([]*v1alpha2.GatewayClass, 0, len(list.Items))
+		for i := range list.Items {
+			gatewayClassList = append(gatewayClassList, &list.Items[i])
+		}
+	}
+	return gatewayClassList, nil
+}
+
+func (g *GatewayClass) Get(gatewayClassName string) (*v1alpha2.GatewayClass, error) {
+	gatewayClass, err := g.GetGatewayClassLister().Get(gatewayClassName)
+	if err != nil || gatewayClass == nil {
+		gatewayClass = &v1alpha2.GatewayClass{}
+		if err := g.resource(ResourceNameGatewayClass).Get(metav1.NamespaceNone, gatewayClassName, gatewayClass); err != nil {
+			return nil, err
+		}
+	}
+	return gatewayClass, nil
+}
+
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (g *GatewayClass) Create(gatewayClass *v1alpha2.GatewayClass, dryRun bool) (*v1alpha2.GatewayClass, error) {
+	created := &v1alpha2.GatewayClass{}
+	if err := g.resource(ResourceNameGatewayClass).Create(gatewayClass, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update gatewayClass 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (g *GatewayClass) Update(gatewayClass *v1alpha2.GatewayClass, dryRun bool) (*v1alpha2.GatewayClass, error) {
+	updated := &v1alpha2.GatewayClass{}
+	if err := g.resource(ResourceNameGatewayClass).Update(gatewayClass, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (g *GatewayClass) Delete(gatewayClassName, resourceVersion string, dryRun bool) error {
+	return g.resource(ResourceNameGatewayClass).Delete(metav1.NamespaceNone, gatewayClassName, resourceVersion, dryRun)
+}
+
+func (g *GatewayClass) GetGatewayClassLister() informer.GatewayClassLister {
+	return g.SharedInformerFactory.Gateway().V1alpha2().GatewayClasses().Lister()
+}
@@ -0,0 +1,74 @@
+package gatewayapi
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	informer "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
+)
+
+type HTTPRoute struct {
+	*GatewayAPIClient
+}
+
+func NewHTTPRoute(cli *GatewayAPIClient) *HTTPRoute {
+	return &HTTPRoute{cli}
+}
+
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (h *HTTPRoute) List(namespace string, label map[string]string) ([]*v1alpha2.HTTPRoute, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	httpRouteList, err := h.GetHTTPRouteLister().HTTPRoutes(namespace).List(selector)
+	if err != nil || len(httpRouteList) == 0 {
+		list := &v1alpha2.HTTPRouteList{}
+		if err := h.resource(ResourceNameHTTPRoute).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		httpRouteList = make([]*v1alpha2.HTTPRoute, 0, len(list.Items))
+		for i := range list.Items {
+			httpRouteList = append(httpRouteList, &list.Items[i])
+		}
+	}
+	return httpRouteList, nil
+}
+
+func (h *HTTPRoute) Get(namespace, httpRouteName string) (*v1alpha2.HTTPRoute, error) {
+	httpRoute, err := h.GetHTTPRouteLister().HTTPRoutes(namespace).Get(httpRouteName)
+	if err != nil || httpRoute == nil {
+		httpRoute = &v1alpha2.HTTPRoute{}
+		if err := h.resource(ResourceNameHTTPRoute).Get(namespace, httpRouteName, httpRoute); err != nil {
+			return nil, err
+		}
+	}
+	return httpRoute, nil
+}
+
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (h *HTTPRoute) Create(httpRoute *v1alpha2.HTTPRoute, dryRun bool) (*v1alpha2.HTTPRoute, error) {
+	created := &v1alpha2.HTTPRoute{}
+	if err := h.resource(ResourceNameHTTPRoute).Create(httpRoute, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update httpRoute 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (h *HTTPRoute) Update(httpRoute *v1alpha2.HTTPRoute, dryRun bool) (*v1alpha2.HTTPRoute, error) {
+	updated := &v1alpha2.HTTPRoute{}
+	if err := h.resource(ResourceNameHTTPRoute).Update(httpRoute, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (h *HTTPRoute) Delete(namespace, httpRouteName, resourceVersion string, dryRun bool) error {
+	return h.resource(ResourceNameHTTPRoute).Delete(namespace, httpRouteName, resourceVersion, dryRun)
+}
+
+func (h *HTTPRoute) GetHTTPRouteLister() informer.HTTPRouteLister {
+	return h.SharedInformerFactory.Gateway().V1alpha2().HTTPRoutes().Lister()
+}
@@ -0,0 +1,106 @@
+package gatewayapi
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	informer "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
+)
+
+// ReferenceGrant v0.5 之前名为 ReferencePolicy, 两者结构相同;
+// 集群提供 referencegrants 时优先使用, 否则使用 referencepolicies
+type ReferenceGrant struct {
+	*GatewayAPIClient
+}
+
+func NewReferenceGrant(cli *GatewayAPIClient) *ReferenceGrant {
+	return &ReferenceGrant{cli}
+}
+
+// GVK 集群实际使用的资源类型
+func (r *ReferenceGrant) GVK() schema.GroupVersionKind {
+	if r.IsServed(ResourceNameReferenceGrant) {
+		return GVKReferenceGrant
+	}
+	return GVKReferencePolicy
+}
+
+// referenceResource 只有使用 referencepolicies 时才能从informer中查询
+func (r *ReferenceGrant) referenceResource() (*kube.ResourceClient, bool) {
+	if r.IsServed(ResourceNameReferenceGrant) {
+		return r.resource(ResourceNameReferenceGrant).WithKind(GVKReferenceGrant.Kind), false
+	}
+	return r.resource(ResourceNameReferencePolicy), true
+}
+
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (r *ReferenceGrant) List(namespace string, label map[string]string) ([]*v1alpha2.ReferencePolicy, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	resource, useLister := r.referenceResource()
+	if useLister {
+		referenceGrantList, err := r.GetReferencePolicyLister().ReferencePolicies(namespace).List(selector)
+		if err == nil && len(referenceGrantList) > 0 {
+			return referenceGrantList, nil
+		}
+	}
+	list := &v1alpha2.ReferencePolicyList{}
+	if err := resource.List(namespace, selector, list); err != nil {
+		return nil, err
+	}
+	referenceGrantList := make([]*v1alpha2.ReferencePolicy, 0, len(list.Items))
+	for i := range list.Items {
+		referenceGrantList = append(referenceGrantList, &list.Items[i])
+	}
+	return referenceGrantList, nil
+}
+
+func (r *ReferenceGrant) Get(namespace, referenceGrantName string) (*v1alpha2.ReferencePolicy, error) {
+	resource, useLister := r.referenceResource()
+	if useLister {
+		referenceGrant, err := r.GetReferencePolicyLister().ReferencePolicies(namespace).Get(referenceGrantName)
+		if err == nil && referenceGrant != nil {
+			return referenceGrant, nil
+		}
+	}
+	referenceGrant := &v1alpha2.ReferencePolicy{}
+	if err := resource.Get(namespace, referenceGrantName, referenceGrant); err != nil {
+		return nil, err
+	}
+	return referenceGrant, nil
+}
+
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (r *ReferenceGrant) Create(referenceGrant *v1alpha2.ReferencePolicy, dryRun bool) (*v1alpha2.ReferencePolicy, error) {
+	resource, _ := r.referenceResource()
+	created := &v1alpha2.ReferencePolicy{}
+	if err := resource.Create(referenceGrant, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update referenceGrant 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (r *ReferenceGrant) Update(referenceGrant *v1alpha2.ReferencePolicy, dryRun bool) (*v1alpha2.ReferencePolicy, error) {
+	resource, _ := r.referenceResource()
+	updated := &v1alpha2.ReferencePolicy{}
+	if err := resource.Update(referenceGrant, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (r *ReferenceGrant) Delete(namespace, referenceGrantName, resourceVersion string, dryRun bool) error {
+	resource, _ := r.referenceResource()
+	return resource.Delete(namespace, referenceGrantName, resourceVersion, dryRun)
+}
+
+func (r *ReferenceGrant) GetReferencePolicyLister() informer.ReferencePolicyLister {
+	return r.SharedInformerFactory.Gateway().V1alpha2().ReferencePolicies().Lister()
+}
@@ -0,0 +1,74 @@
+package gatewayapi
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	informer "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
+)
+
+type TCPRoute struct {
+	*GatewayAPIClient
+}
+
+func NewTCPRoute(cli *GatewayAPIClient) *TCPRoute {
+	return &TCPRoute{cli}
+}
+
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (t *TCPRoute) List(namespace string, label map[string]string) ([]*v1alpha2.TCPRoute, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	tcpRouteList, err := t.GetTCPRouteLister().TCPRoutes(namespace).List(selector)
+	if err != nil || len(tcpRouteList) == 0 {
+		list := &v1alpha2.TCPRouteList{}
+		if err := t.resource(ResourceNameTCPRoute).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		tcpRouteList = make([]*v1alpha2.TCPRoute, 0, len(list.Items))
+		for i := range list.Items {
+			tcpRouteList = append(tcpRouteList, &list.Items[i])
+		}
+	}
+	return tcpRouteList, nil
+}
+
+func (t *TCPRoute) Get(namespace, tcpRouteName string) (*v1alpha2.TCPRoute, error) {
+	tcpRoute, err := t.GetTCPRouteLister().TCPRoutes(namespace).Get(tcpRouteName)
+	if err != nil || tcpRoute == nil {
+		tcpRoute = &v1alpha2.TCPRoute{}
+		if err := t.resource(ResourceNameTCPRoute).Get(namespace, tcpRouteName, tcpRoute); err != nil {
+			return nil, err
+		}
+	}
+	return tcpRoute, nil
+}
+
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (t *TCPRoute) Create(tcpRoute *v1alpha2.TCPRoute, dryRun bool) (*v1alpha2.TCPRoute, error) {
+	created := &v1alpha2.TCPRoute{}
+	if err := t.resource(ResourceNameTCPRoute).Create(tcpRoute, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update tcpRoute 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (t *TCPRoute) Update(tcpRoute *v1alpha2.TCPRoute, dryRun bool) (*v1alpha2.TCPRoute, error) {
+	updated := &v1alpha2.TCPRoute{}
+	if err := t.resource(ResourceNameTCPRoute).Update(tcpRoute, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (t *TCPRoute) Delete(namespace, tcpRouteName, resourceVersion string, dryRun bool) error {
+	return t.resource(ResourceNameTCPRoute).Delete(namespace, tcpRouteName, resourceVersion, dryRun)
+}
+
+func (t *TCPRoute) GetTCPRouteLister() informer.TCPRouteLister {
+	return t.SharedInformerFactory.Gateway().V1alpha2().TCPRoutes().Lister()
+}
@@ -0,0 +1,74 @@
+package gatewayapi
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	informer "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1alpha2"
+)
+
+type TLSRoute struct {
+	*GatewayAPIClient
+}
+
+func NewTLSRoute(cli *GatewayAPIClient) *TLSRoute {
+	return &TLSRoute{cli}
+}
+
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (t *TLSRoute) List(namespace string, label map[string]string) ([]*v1alpha2.TLSRoute, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	tlsRouteList, err := t.GetTLSRouteLister().TLSRoutes(namespace).List(selector)
+	if err != nil || len(tlsRouteList) == 0 {
+		list := &v1alpha2.TLSRouteList{}
+		if err := t.resource(ResourceNameTLSRoute).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		tlsRouteList = make([]*v1alpha2.TLSRoute, 0, len(list.Items))
+		for i := range list.Items {
+			tlsRouteList = append(tlsRouteList, &list.Items[i])
+		}
+	}
+	return tlsRouteList, nil
+}
+
+func (t *TLSRoute) Get(namespace, tlsRouteName string) (*v1alpha2.TLSRoute, error) {
+	tlsRoute, err := t.GetTLSRouteLister().TLSRoutes(namespace).Get(tlsRouteName)
+	if err != nil || tlsRoute == nil {
+		tlsRoute = &v1alpha2.TLSRoute{}
+		if err := t.resource(ResourceNameTLSRoute).Get(namespace, tlsRouteName, tlsRoute); err != nil {
+			return nil, err
+		}
+	}
+	return tlsRoute, nil
+}
+
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (t *TLSRoute) Create(tlsRoute *v1alpha2.TLSRoute, dryRun bool) (*v1alpha2.TLSRoute, error) {
+	created := &v1alpha2.TLSRoute{}
+	if err := t.resource(ResourceNameTLSRoute).Create(tlsRoute, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update tlsRoute 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (t *TLSRoute) Update(tlsRoute *v1alpha2.TLSRoute, dryRun bool) (*v1alpha2.TLSRoute, error) {
+	updated := &v1alpha2.TLSRoute{}
+	if err := t.resource(ResourceNameTLSRoute).Update(tlsRoute, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (t *TLSRoute) Delete(namespace, tlsRouteName, resourceVersion string, dryRun bool) error {
+	return t.resource(ResourceNameTLSRoute).Delete(namespace, tlsRouteName, resourceVersion, dryRun)
+}
+
+func (t *TLSRoute) GetTLSRouteLister() informer.TLSRouteLister {
+	return t.SharedInformerFactory.Gateway().V1alpha2().TLSRoutes().Lister()
+}
@@ -0,0 +1,235 @@
+package istio
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"istio.io/istio/pilot/pkg/config/kube/crdclient"
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/analysis/analyzers"
+	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/analysis/local"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+)
+
+const (
+	AnalysisLevelError   = "Error"
+	AnalysisLevelWarning = "Warning"
+	AnalysisLevelInfo    = "Info"
+
+	defaultKubernetesDomain = "svc.cluster.local"
+	defaultDomainSuffix     = "cluster.local"
+	meshGateway             = "mesh"
+	analysisTimeout         = 30 * time.Second
+)
+
+var analysisLevels = map[string]diag.Level{
+	AnalysisLevelInfo:    diag.Info,
+	AnalysisLevelWarning: diag.Warning,
+	AnalysisLevelError:   diag.Error,
+}
+
+// AnalysisMessage istioctl analyze 输出的消息, 编号及文档见 https://istio.io/latest/docs/reference/config/analysis/
+type AnalysisMessage struct {
+	Code             string `json:"code"`
+	Level            string `json:"level"`
+	Message          string `json:"message"`
+	Kind             string `json:"kind"`
+	Namespace        string `json:"namespace"`
+	ResourceName     string `json:"resourceName"`
+	Link             string `json:"link"`
+	DocumentationURL string `json:"documentationUrl"`
+}
+
+// IsAnalysisLevel 是否为合法的级别
+func IsAnalysisLevel(level string) bool {
+	_, ok := analysisLevels[level]
+	return ok
+}
+
+// Analyze 使用istioctl analyze的分析器检查缓存中的资源, namespace 不为空时只返回该命名空间下资源的消息, level 为返回消息的最低级别
+func (i *IstioClient) Analyze(namespace, level string) ([]AnalysisMessage, error) {
+	store, err := i.newAnalysisStore()
+	if err != nil {
+		return nil, err
+	}
+	// 分析器的输入资源不在store中时会被跳过, 每次分析都需要新的分析器
+	sa := local.NewSourceAnalyzer(analyzers.AllCombined(), resource.Namespace(namespace), resource.Namespace(IstioNamespace), nil, true, analysisTimeout)
+	sa.AddSource(memory.NewSyncController(store))
+	cancel := make(chan struct{})
+	defer close(cancel)
+	result, err := sa.Analyze(cancel)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := result.Messages.FilterOutLowerThan(analysisLevels[level])
+	messages := make([]AnalysisMessage, 0, len(filtered))
+	for _, m := range filtered {
+		message := AnalysisMessage{
+			Code:             m.Type.Code(),
+			Level:            m.Type.Level().String(),
+			Message:          fmt.Sprintf(m.Type.Template(), m.Parameters...),
+			DocumentationURL: fmt.Sprint(m.Unstructured(false)["documentationUrl"]),
+		}
+		if m.Resource != nil {
+			schema := m.Resource.Metadata.Schema
+			message.Kind = schema.Kind()
+			message.Namespace = m.Resource.Metadata.FullName.Namespace.String()
+			message.ResourceName = m.Resource.Metadata.FullName.Name.String()
+			message.Link = ResourceLink(i.kubeConfigId, schema.Group(), message.Kind, message.Namespace, message.ResourceName)
+		}
+		messages = append(messages, message)
+	}
+	sort.SliceStable(messages, func(a, b int) bool {
+		if messages[a].Kind != messages[b].Kind {
+			return messages[a].Kind < messages[b].Kind
+		}
+		if messages[a].Namespace != messages[b].Namespace {
+			return messages[a].Namespace < messages[b].Namespace
+		}
+		if messages[a].ResourceName != messages[b].ResourceName {
+			return messages[a].ResourceName < messages[b].ResourceName
+		}
+		return messages[a].Code < messages[b].Code
+	})
+	return messages, nil
+}
+
+// newAnalysisStore 把informer缓存中的istio资源和k8s资源转换为分析器使用的配置, 资源之间可以跨命名空间引用, 所以总是加载所有命名空间
+func (i *IstioClient) newAnalysisStore() (model.ConfigStore, error) {
+	if err := i.startKubeInformers(); err != nil {
+		return nil, err
+	}
+	if err := waitForCacheSync(analysisTimeout, i.SharedInformerFactory.WaitForCacheSync,
+		i.kubeInformers.WaitForCacheSync, i.istioNamespaceInformers.WaitForCacheSync); err != nil {
+		return nil, err
+	}
+
+	objects := make(map[collection.Schema][]runtime.Object)
+	for _, gvr := range KindToIstioResourceSlice {
+		if !i.versions.IsServed(gvr) {
+			continue
+		}
+		gvk := resourceKinds[gvr]
+		s, found := collections.All.FindByGroupVersionKind(config.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind})
+		if !found {
+			continue
+		}
+		informer, err := i.SharedInformerFactory.ForResource(gvr)
+		if err != nil {
+			return nil, err
+		}
+		if objects[s], err = informer.Lister().List(labels.Everything()); err != nil {
+			return nil, err
+		}
+	}
+	if err := listKubeObjects(objects, i.kubeInformers, i.istioNamespaceInformers); err != nil {
+		return nil, err
+	}
+
+	schemas := collection.NewSchemasBuilder()
+	for s := range objects {
+		schemas.MustAdd(s)
+	}
+	store := memory.MakeSkipValidation(schemas.Build())
+	for s, list := range objects {
+		for _, obj := range list {
+			if _, err := store.Create(crdclient.TranslateObject(obj, s.Resource().GroupVersionKind(), defaultDomainSuffix)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return store, nil
+}
+
+// startKubeInformers 启动分析器需要的k8s资源informer, Secret不缓存, 依赖Secret的分析器会被跳过
+func (i *IstioClient) startKubeInformers() error {
+	if i.kubeCli == nil {
+		return fmt.Errorf("kubernetes client of cluster %d is not initialized", i.kubeConfigId)
+	}
+	i.kubeInformersOnce.Do(func() {
+		i.kubeInformers = informers.NewSharedInformerFactory(i.kubeCli, defaultIstioResyncPeriod)
+		i.kubeInformers.Core().V1().Namespaces().Informer()
+		i.kubeInformers.Core().V1().Services().Informer()
+		i.kubeInformers.Core().V1().Pods().Informer()
+		i.kubeInformers.Apps().V1().Deployments().Informer()
+		i.kubeInformers.Admissionregistration().V1().MutatingWebhookConfigurations().Informer()
+		i.kubeInformers.Start(i.stopChan)
+		// 注入模板和网格配置所在的ConfigMap只在istio命名空间下
+		i.istioNamespaceInformers = informers.NewSharedInformerFactoryWithOptions(i.kubeCli, defaultIstioResyncPeriod,
+			informers.WithNamespace(IstioNamespace))
+		i.istioNamespaceInformers.Core().V1().ConfigMaps().Informer()
+		i.istioNamespaceInformers.Start(i.stopChan)
+	})
+	return nil
+}
+
+func listKubeObjects(objects map[collection.Schema][]runtime.Object, kubeInformers, istioNamespaceInformers informers.SharedInformerFactory) error {
+	namespaces, err := kubeInformers.Core().V1().Namespaces().Lister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, obj := range namespaces {
+		objects[collections.K8SCoreV1Namespaces] = append(objects[collections.K8SCoreV1Namespaces], obj)
+	}
+	services, err := kubeInformers.Core().V1().Services().Lister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, obj := range services {
+		objects[collections.K8SCoreV1Services] = append(objects[collections.K8SCoreV1Services], obj)
+	}
+	pods, err := kubeInformers.Core().V1().Pods().Lister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, obj := range pods {
+		objects[collections.K8SCoreV1Pods] = append(objects[collections.K8SCoreV1Pods], obj)
+	}
+	deployments, err := kubeInformers.Apps().V1().Deployments().Lister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, obj := range deployments {
+		objects[collections.K8SAppsV1Deployments] = append(objects[collections.K8SAppsV1Deployments], obj)
+	}
+	webhooks, err := kubeInformers.Admissionregistration().V1().MutatingWebhookConfigurations().Lister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, obj := range webhooks {
+		objects[collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations] = append(
+			objects[collections.K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations], obj)
+	}
+	configMaps, err := istioNamespaceInformers.Core().V1().ConfigMaps().Lister().List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, obj := range configMaps {
+		objects[collections.K8SCoreV1Configmaps] = append(objects[collections.K8SCoreV1Configmaps], obj)
+	}
+	return nil
+}
+
+// convertHostToFQDN 短域名补全为所在命名空间的服务域名, 与istio的处理一致, 带"."或通配符的域名保持不变
+func convertHostToFQDN(namespace, host string) string {
+	if strings.HasPrefix(host, "*") || strings.Contains(host, ".") {
+		return host
+	}
+	return fmt.Sprintf("%s.%s.%s", host, namespace, defaultKubernetesDomain)
+}
+
+// IsServiceHost 按istio补全短域名的规则判断namespace下的host是否指向该命名空间的服务
+func IsServiceHost(namespace, host, service string) bool {
+	return convertHostToFQDN(namespace, host) == convertHostToFQDN(namespace, service)
+}
@@ -1,6 +1,9 @@
 package istio
 
 import (
+	"fmt"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/shuxnhs/istio-dashboard/domain/kube"
@@ -9,7 +12,10 @@ import (
 	"istio.io/client-go/pkg/clientset/versioned"
 	"istio.io/client-go/pkg/informers/externalversions"
 	"istio.io/pkg/log"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 const (
@@ -20,8 +26,17 @@ const (
 var domainLog = log.RegisterScope("istio-domain", "istio-domain debugging", 0)
 
 type IstioClient struct {
-	stopChan chan struct{}
-	kubeCli  *kubernetes.Clientset
+	kubeConfigId  int64
+	stopChan      chan struct{}
+	kubeCli       *kubernetes.Clientset
+	restConfig    *rest.Config
+	versions      kube.APIVersions
+	restClientsMu sync.Mutex
+	restClients   map[schema.GroupVersion]rest.Interface
+	// kubeInformers 分析配置时才需要的k8s资源缓存, 第一次分析时启动
+	kubeInformersOnce       sync.Once
+	kubeInformers           informers.SharedInformerFactory
+	istioNamespaceInformers informers.SharedInformerFactory
 	*versioned.Clientset
 	externalversions.SharedInformerFactory
 }
@@ -34,12 +49,24 @@ func NewIstioClientSet(kubeConfig *model.KubeConfig) *IstioClient {
 		return nil
 	}
 	istioClient := &IstioClient{
+		kubeConfigId:          kubeConfig.Id,
 		stopChan:              make(chan struct{}),
 		kubeCli:               kube.NewKubernetesClientSet(kubeConfig),
+		restConfig:            config,
+		restClients:           make(map[schema.GroupVersion]rest.Interface),
 		Clientset:             istioClientSet,
 		SharedInformerFactory: externalversions.NewSharedInformerFactory(istioClientSet, defaultIstioResyncPeriod),
 	}
+	istioClient.versions, err = getAPIVersions(kubeConfig.Cid, istioClientSet.Discovery())
+	if err != nil {
+		domainLog.Errorf("discover istio api versions err: %s", err)
+		istioClient.versions = defaultAPIVersions()
+	}
 	for _, istioResource := range KindToIstioResourceSlice {
+		// 集群不提供该版本时(istio版本过低或过高)不启动informer, 查询时直接按协商后的版本请求
+		if !istioClient.versions.IsServed(istioResource) {
+			continue
+		}
 		genericInformer, err := istioClient.SharedInformerFactory.ForResource(istioResource)
 		if err != nil {
 			domainLog.Errorf("new sharedInformerFactory for resource %#v, err: %s", istioResource, err)
@@ -51,6 +78,61 @@ func NewIstioClientSet(kubeConfig *model.KubeConfig) *IstioClient {
 	return istioClient
 }
 
+// Close 停止informer, 长期运行的任务使用完客户端后需要关闭
+func (i *IstioClient) Close() {
+	close(i.stopChan)
+}
+
+// WaitForCacheSync 等待informer完成首次同步, 超时返回错误; 根据查询结果修改资源前调用, 同步过程中的缓存只有部分资源
+func (i *IstioClient) WaitForCacheSync(timeout time.Duration) error {
+	return waitForCacheSync(timeout, i.SharedInformerFactory.WaitForCacheSync)
+}
+
+// waitForCacheSync 依次等待多个informer工厂同步, 共用同一个超时时间
+func waitForCacheSync(timeout time.Duration, waits ...func(stopCh <-chan struct{}) map[reflect.Type]bool) error {
+	stop := make(chan struct{})
+	timer := time.AfterFunc(timeout, func() { close(stop) })
+	defer timer.Stop()
+	for _, wait := range waits {
+		for informerType, synced := range wait(stop) {
+			if !synced {
+				return fmt.Errorf("wait for %s cache sync timeout", informerType)
+			}
+		}
+	}
+	return nil
+}
+
+type cachedIstioClient struct {
+	fingerprint string
+	client      *IstioClient
+}
+
+var (
+	clientCacheMu sync.Mutex
+	clientCache   = make(map[int64]*cachedIstioClient)
+)
+
+// GetIstioClient 每个集群共享一个客户端, informer只启动一次; 集群的连接配置修改后关闭旧的客户端重新创建, 调用方不能关闭返回的客户端
+func GetIstioClient(kubeConfig *model.KubeConfig) *IstioClient {
+	fingerprint := kube.ConfigFingerprint(kubeConfig)
+	clientCacheMu.Lock()
+	defer clientCacheMu.Unlock()
+	if cached, ok := clientCache[kubeConfig.Id]; ok {
+		if cached.fingerprint == fingerprint {
+			return cached.client
+		}
+		cached.client.Close()
+		delete(clientCache, kubeConfig.Id)
+	}
+	client := NewIstioClientSet(kubeConfig)
+	if client == nil {
+		return nil
+	}
+	clientCache[kubeConfig.Id] = &cachedIstioClient{fingerprint: fingerprint, client: client}
+	return client
+}
+
 func (i *IstioClient) GetIstioVersion() {
 
 }
@@ -1,13 +1,11 @@
 package istio
 
 import (
-	"context"
-
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	informer "istio.io/client-go/pkg/listers/networking/v1alpha3"
 	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type DestinationRule struct {
@@ -18,50 +16,81 @@ func NewDestinationRule(cli *IstioClient) *DestinationRule {
 	return &DestinationRule{cli}
 }
 
-func (d *DestinationRule) List(namespace string) []v1alpha3.DestinationRule {
-	destinationRuleList := make([]v1alpha3.DestinationRule, 0)
-	list, err := d.GetDestinationRuleLister().DestinationRules(namespace).List(labels.Everything())
-	if err != nil || len(list) == 0 {
-		list, err := d.Clientset.NetworkingV1alpha3().DestinationRules(namespace).List(context.Background(), metav1.ListOptions{})
-		if err == nil && list != nil {
-			destinationRuleList = list.Items
-		}
-		return destinationRuleList
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (d *DestinationRule) List(namespace string, label map[string]string) ([]*v1alpha3.DestinationRule, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
 	}
-	for _, destinationRule := range list {
-		destinationRuleList = append(destinationRuleList, *destinationRule)
+
+	destinationRuleList, err := d.GetDestinationRuleLister().DestinationRules(namespace).List(selector)
+	if err != nil || len(destinationRuleList) == 0 {
+		list := &v1alpha3.DestinationRuleList{}
+		if err := d.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule)).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		destinationRuleList = make([]*v1alpha3.DestinationRule, 0, len(list.Items))
+		for i := range list.Items {
+			destinationRuleList = append(destinationRuleList, &list.Items[i])
+		}
 	}
-	return destinationRuleList
+	return destinationRuleList, nil
 }
 
 func (d *DestinationRule) Get(namespace, destinationRuleName string) (*v1alpha3.DestinationRule, error) {
 	destinationRule, err := d.GetDestinationRuleLister().DestinationRules(namespace).Get(destinationRuleName)
-	if destinationRule == nil {
-		return d.Clientset.NetworkingV1alpha3().DestinationRules(namespace).
-			Get(context.Background(), destinationRuleName, metav1.GetOptions{})
+	if err != nil || destinationRule == nil {
+		destinationRule = &v1alpha3.DestinationRule{}
+		if err := d.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule)).Get(namespace, destinationRuleName, destinationRule); err != nil {
+			return nil, err
+		}
+	}
+	return destinationRule, nil
+}
+
+// getLatest 读取后修改再写入时直接从apiserver读取, informer中的对象可能落后导致一直冲突
+func (d *DestinationRule) getLatest(namespace, destinationRuleName string) (*v1alpha3.DestinationRule, error) {
+	destinationRule := &v1alpha3.DestinationRule{}
+	if err := d.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule)).Get(namespace, destinationRuleName, destinationRule); err != nil {
+		return nil, err
 	}
-	return destinationRule, err
+	return destinationRule, nil
 }
 
-func (d *DestinationRule) Create(destinationRule *v1alpha3.DestinationRule) error {
-	_, err := d.Clientset.NetworkingV1alpha3().DestinationRules(destinationRule.Namespace).
-		Create(context.Background(), destinationRule, metav1.CreateOptions{})
-	return err
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (d *DestinationRule) Create(destinationRule *v1alpha3.DestinationRule, dryRun bool) (*v1alpha3.DestinationRule, error) {
+	created := &v1alpha3.DestinationRule{}
+	if err := d.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule)).Create(destinationRule, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
-func (d *DestinationRule) Delete(namespace, destinationRuleName string) error {
-	return d.Clientset.NetworkingV1alpha3().DestinationRules(namespace).
-		Delete(context.Background(), destinationRuleName, metav1.DeleteOptions{})
+// Update destinationRule 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (d *DestinationRule) Update(destinationRule *v1alpha3.DestinationRule, dryRun bool) (*v1alpha3.DestinationRule, error) {
+	updated := &v1alpha3.DestinationRule{}
+	if err := d.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule)).Update(destinationRule, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Patch 只修改 patch 中的字段, patch 中带上 resourceVersion 时, 期间被其他人修改过返回 Conflict 错误
+func (d *DestinationRule) Patch(namespace, destinationRuleName string, patchType types.PatchType, patch []byte, dryRun bool) (*v1alpha3.DestinationRule, error) {
+	patched := &v1alpha3.DestinationRule{}
+	if err := d.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule)).Patch(namespace, destinationRuleName, patchType, patch, patched, dryRun); err != nil {
+		return nil, err
+	}
+	return patched, nil
 }
 
-func (d *DestinationRule) Update(destinationRule *v1alpha3.DestinationRule) error {
-	_, err := d.Clientset.NetworkingV1alpha3().DestinationRules(destinationRule.Namespace).
-		Update(context.Background(), destinationRule, metav1.UpdateOptions{})
-	return err
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (d *DestinationRule) Delete(namespace, destinationRuleName, resourceVersion string, dryRun bool) error {
+	return d.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule)).Delete(namespace, destinationRuleName, resourceVersion, dryRun)
 }
 
 func (d *DestinationRule) DoCreateOrUpdate(destinationRule *v1alpha3.DestinationRule) error {
-	oldDstinationRule, err := d.Get(destinationRule.Namespace, destinationRule.Name)
+	oldDestinationRule, err := d.Get(destinationRule.Namespace, destinationRule.Name)
 	exist := true
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -72,11 +101,14 @@ func (d *DestinationRule) DoCreateOrUpdate(destinationRule *v1alpha3.Destination
 	}
 	if !exist {
 		// create
-		return d.Create(destinationRule)
+		_, err = d.Create(destinationRule, false)
+		return err
 	} else {
-		// update
-		oldDstinationRule.Spec = destinationRule.Spec
-		return d.Update(oldDstinationRule)
+		// update, 从informer中获取的对象不能直接修改
+		newDestinationRule := oldDestinationRule.DeepCopy()
+		destinationRule.Spec.DeepCopyInto(&newDestinationRule.Spec)
+		_, err = d.Update(newDestinationRule, false)
+		return err
 	}
 }
 
@@ -1,11 +1,8 @@
 package istio
 
 import (
-	"context"
-
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	informer "istio.io/client-go/pkg/listers/networking/v1alpha3"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -18,46 +15,59 @@ func NewEnvoyFilter(cli *IstioClient) *EnvoyFilter {
 	return &EnvoyFilter{cli}
 }
 
-func (e *EnvoyFilter) List(namespace string) []v1alpha3.EnvoyFilter {
-	envoyFilterList := make([]v1alpha3.EnvoyFilter, 0)
-	list, err := e.GetEnvoyFilterLister().EnvoyFilters(namespace).List(labels.Everything())
-	if err != nil || len(list) == 0 {
-		list, err := e.Clientset.NetworkingV1alpha3().EnvoyFilters(namespace).List(context.Background(), metav1.ListOptions{})
-		if err == nil && list != nil {
-			envoyFilterList = list.Items
-		}
-		return envoyFilterList
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (e *EnvoyFilter) List(namespace string, label map[string]string) ([]*v1alpha3.EnvoyFilter, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
 	}
-	for _, envoyFilter := range list {
-		envoyFilterList = append(envoyFilterList, *envoyFilter)
+
+	envoyFilterList, err := e.GetEnvoyFilterLister().EnvoyFilters(namespace).List(selector)
+	if err != nil || len(envoyFilterList) == 0 {
+		list := &v1alpha3.EnvoyFilterList{}
+		if err := e.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameEnvoyFilter)).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		envoyFilterList = make([]*v1alpha3.EnvoyFilter, 0, len(list.Items))
+		for i := range list.Items {
+			envoyFilterList = append(envoyFilterList, &list.Items[i])
+		}
 	}
-	return envoyFilterList
+	return envoyFilterList, nil
 }
 
 func (e *EnvoyFilter) Get(namespace, envoyFilterName string) (*v1alpha3.EnvoyFilter, error) {
 	envoyFilter, err := e.GetEnvoyFilterLister().EnvoyFilters(namespace).Get(envoyFilterName)
 	if err != nil || envoyFilter == nil {
-		return e.Clientset.NetworkingV1alpha3().EnvoyFilters(namespace).
-			Get(context.Background(), envoyFilterName, metav1.GetOptions{})
+		envoyFilter = &v1alpha3.EnvoyFilter{}
+		if err := e.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameEnvoyFilter)).Get(namespace, envoyFilterName, envoyFilter); err != nil {
+			return nil, err
+		}
 	}
-	return envoyFilter, err
+	return envoyFilter, nil
 }
 
-func (e *EnvoyFilter) Create(envoyFilter *v1alpha3.EnvoyFilter) error {
-	_, err := e.Clientset.NetworkingV1alpha3().EnvoyFilters(envoyFilter.Namespace).
-		Create(context.Background(), envoyFilter, metav1.CreateOptions{})
-	return err
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (e *EnvoyFilter) Create(envoyFilter *v1alpha3.EnvoyFilter, dryRun bool) (*v1alpha3.EnvoyFilter, error) {
+	created := &v1alpha3.EnvoyFilter{}
+	if err := e.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameEnvoyFilter)).Create(envoyFilter, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
-func (e *EnvoyFilter) Delete(namespace, envoyFilterName string) error {
-	return e.Clientset.NetworkingV1alpha3().EnvoyFilters(namespace).
-		Delete(context.Background(), envoyFilterName, metav1.DeleteOptions{})
+// Update envoyFilter 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (e *EnvoyFilter) Update(envoyFilter *v1alpha3.EnvoyFilter, dryRun bool) (*v1alpha3.EnvoyFilter, error) {
+	updated := &v1alpha3.EnvoyFilter{}
+	if err := e.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameEnvoyFilter)).Update(envoyFilter, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
-func (e *EnvoyFilter) Update(envoyFilter *v1alpha3.EnvoyFilter) error {
-	_, err := e.Clientset.NetworkingV1alpha3().EnvoyFilters(envoyFilter.Namespace).
-		Update(context.Background(), envoyFilter, metav1.UpdateOptions{})
-	return err
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (e *EnvoyFilter) Delete(namespace, envoyFilterName, resourceVersion string, dryRun bool) error {
+	return e.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameEnvoyFilter)).Delete(namespace, envoyFilterName, resourceVersion, dryRun)
 }
 
 func (e *EnvoyFilter) GetEnvoyFilterLister() informer.EnvoyFilterLister {
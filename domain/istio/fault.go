@@ -0,0 +1,124 @@
+package istio
+
+import (
+	"fmt"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+)
+
+// faultOwnerAnnotation InjectFault 新建的 VirtualService 上记录故障路由名, RemoveFault 只删除带有相同标记的 VirtualService
+const faultOwnerAnnotation = "istio-dashboard/fault-owner"
+
+// InjectFault 在 VirtualService 中增加带故障的路由, 路由名为 name, 复制原本处理这些请求的路由, 所以其他请求不受影响;
+// match 为空时在每条路由之前都复制一条带故障的路由, 路由名为 name-下标; VirtualService 不存在时新建, 此时返回的 created 为true
+func (i *IstioClient) InjectFault(namespace, virtualServiceName, service, name string, match *RouteMatch, fault *networking.HTTPFaultInjection) (bool, error) {
+	virtualService := NewVirtualService(i)
+	created := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vs, err := virtualService.getLatest(namespace, virtualServiceName)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			vs = newServiceVirtualService(namespace, virtualServiceName, service, 0)
+			vs.Annotations = map[string]string{faultOwnerAnnotation: name}
+			if err = insertFaultRoutes(vs, name, match, fault); err != nil {
+				return err
+			}
+			if _, err = virtualService.Create(vs, false); err != nil {
+				return err
+			}
+			created = true
+			return nil
+		}
+		vs = vs.DeepCopy()
+		removeFaultRoutes(vs, name)
+		if err = insertFaultRoutes(vs, name, match, fault); err != nil {
+			return err
+		}
+		_, err = virtualService.Update(vs, false)
+		return err
+	})
+	return created, err
+}
+
+// RemoveFault 删除 InjectFault 增加的路由, deleteCreated 为true、VirtualService 由同一个故障创建且只剩下默认路由时删除整个 VirtualService
+func (i *IstioClient) RemoveFault(namespace, virtualServiceName, name string, deleteCreated bool) error {
+	virtualService := NewVirtualService(i)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vs, err := virtualService.getLatest(namespace, virtualServiceName)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		vs = vs.DeepCopy()
+		if removeFaultRoutes(vs, name) == 0 {
+			return nil
+		}
+		if deleteCreated && vs.Annotations[faultOwnerAnnotation] == name && len(vs.Spec.GetHttp()) == 1 && len(vs.Spec.GetHttp()[0].GetMatch()) == 0 &&
+			len(vs.Spec.GetTcp()) == 0 && len(vs.Spec.GetTls()) == 0 {
+			if err = virtualService.Delete(namespace, virtualServiceName, vs.ResourceVersion, false); errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		_, err = virtualService.Update(vs, false)
+		return err
+	})
+}
+
+func insertFaultRoutes(vs *v1alpha3.VirtualService, name string, match *RouteMatch, fault *networking.HTTPFaultInjection) error {
+	if match.IsEmpty() {
+		routes := make([]*networking.HTTPRoute, 0, len(vs.Spec.GetHttp())*2)
+		for index, http := range vs.Spec.GetHttp() {
+			if http.GetRedirect() == nil && http.GetDelegate() == nil {
+				route := http.DeepCopy()
+				route.Name = fmt.Sprintf("%s-%d", name, index)
+				route.Fault = fault
+				routes = append(routes, route)
+			}
+			routes = append(routes, http)
+		}
+		if len(routes) == len(vs.Spec.GetHttp()) {
+			return fmt.Errorf("virtualservice %s/%s has no http route to inject fault", vs.Namespace, vs.Name)
+		}
+		vs.Spec.Http = routes
+		return nil
+	}
+
+	httpMatch := match.toProto()
+	index := routeIndex(vs.Spec.GetHttp(), httpMatch)
+	if index == len(vs.Spec.GetHttp()) {
+		return fmt.Errorf("no http route in virtualservice %s/%s matches the requests", vs.Namespace, vs.Name)
+	}
+	fallback := vs.Spec.Http[index]
+	if fallback.GetRedirect() != nil || fallback.GetDelegate() != nil {
+		return fmt.Errorf("http route %s of virtualservice %s/%s is a redirect or delegate", routeName(fallback, index), vs.Namespace, vs.Name)
+	}
+	route := fallback.DeepCopy()
+	route.Name = name
+	route.Match = []*networking.HTTPMatchRequest{httpMatch}
+	route.Fault = fault
+	vs.Spec.Http = append(vs.Spec.Http[:index:index], append([]*networking.HTTPRoute{route}, vs.Spec.Http[index:]...)...)
+	return nil
+}
+
+// removeFaultRoutes 返回删除的路由数量
+func removeFaultRoutes(vs *v1alpha3.VirtualService, name string) int {
+	routes := make([]*networking.HTTPRoute, 0, len(vs.Spec.GetHttp()))
+	for _, http := range vs.Spec.GetHttp() {
+		if http.GetName() == name || strings.HasPrefix(http.GetName(), name+"-") {
+			continue
+		}
+		routes = append(routes, http)
+	}
+	removed := len(vs.Spec.GetHttp()) - len(routes)
+	vs.Spec.Http = routes
+	return removed
+}
@@ -1,13 +1,8 @@
 package istio
 
 import (
-	"context"
-
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	informer "istio.io/client-go/pkg/listers/networking/v1alpha3"
-	"istio.io/pkg/log"
-	kerror "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -22,8 +17,11 @@ func NewGateway(cli *IstioClient) *Gateway {
 func (g *Gateway) Get(namespace, gatewayName string) (*v1alpha3.Gateway, error) {
 	gateway, err := g.GetGatewayLister().Gateways(namespace).Get(gatewayName)
 	if gateway == nil {
-		return g.Clientset.NetworkingV1alpha3().Gateways(namespace).
-			Get(context.Background(), gatewayName, metav1.GetOptions{})
+		gateway = &v1alpha3.Gateway{}
+		if err := g.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameGateway)).Get(namespace, gatewayName, gateway); err != nil {
+			return nil, err
+		}
+		return gateway, nil
 	}
 	return gateway, err
 }
@@ -36,8 +34,8 @@ func (g *Gateway) List(namespace string, label map[string]string) ([]*v1alpha3.G
 
 	gateways, err := g.GetGatewayLister().Gateways(namespace).List(selector)
 	if gateways == nil {
-		gws, err := g.Clientset.NetworkingV1alpha3().Gateways(namespace).
-			List(context.Background(), metav1.ListOptions{LabelSelector: selector.String()})
+		gws := &v1alpha3.GatewayList{}
+		err := g.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameGateway)).List(namespace, selector, gws)
 		if err != nil {
 			return nil, err
 		}
@@ -60,32 +58,27 @@ func (g *Gateway) ListCache(namespace string, label map[string]string) ([]*v1alp
 	return g.GetGatewayLister().Gateways(namespace).List(selector)
 }
 
-func (g *Gateway) Create(gateway *v1alpha3.Gateway) error {
-	_, k8sErr := g.Clientset.NetworkingV1alpha3().Gateways(gateway.Namespace).Create(context.Background(), gateway, metav1.CreateOptions{})
-	if k8sErr != nil {
-		return k8sErr
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (g *Gateway) Create(gateway *v1alpha3.Gateway, dryRun bool) (*v1alpha3.Gateway, error) {
+	created := &v1alpha3.Gateway{}
+	if err := g.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameGateway)).Create(gateway, created, dryRun); err != nil {
+		return nil, err
 	}
-	return nil
+	return created, nil
 }
 
-func (g *Gateway) Update(gateway *v1alpha3.Gateway) error {
-	_, k8sErr := g.Clientset.NetworkingV1alpha3().Gateways(gateway.Namespace).Update(context.Background(), gateway, metav1.UpdateOptions{})
-	if k8sErr != nil {
-		return k8sErr
+// Update gateway 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (g *Gateway) Update(gateway *v1alpha3.Gateway, dryRun bool) (*v1alpha3.Gateway, error) {
+	updated := &v1alpha3.Gateway{}
+	if err := g.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameGateway)).Update(gateway, updated, dryRun); err != nil {
+		return nil, err
 	}
-	return nil
+	return updated, nil
 }
 
-func (g *Gateway) Delete(namespace, gatewayName string) error {
-	k8sErr := g.Clientset.NetworkingV1alpha3().Gateways(namespace).Delete(context.Background(), gatewayName, metav1.DeleteOptions{})
-	if k8sErr != nil {
-		if kerror.IsNotFound(k8sErr) {
-			return nil
-		}
-		log.Error(k8sErr, namespace, gatewayName)
-		return k8sErr
-	}
-	return nil
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (g *Gateway) Delete(namespace, gatewayName, resourceVersion string, dryRun bool) error {
+	return g.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameGateway)).Delete(namespace, gatewayName, resourceVersion, dryRun)
 }
 
 func (g *Gateway) GetGatewayLister() informer.GatewayLister {
@@ -0,0 +1,105 @@
+package istio
+
+import (
+	"fmt"
+
+	"istio.io/client-go/pkg/clientset/versioned/scheme"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GenericResource 按GVR访问 Sidecar、PeerAuthentication 等没有专门逻辑的资源, 优先从informer读取, 返回的对象为 scheme 中对应的类型
+type GenericResource struct {
+	*IstioClient
+	gvr schema.GroupVersionResource
+	gvk schema.GroupVersionKind
+}
+
+// NewGenericResource gvr 为 KindToIstioResourceSlice 中的资源
+func NewGenericResource(cli *IstioClient, gvr schema.GroupVersionResource) *GenericResource {
+	return &GenericResource{IstioClient: cli, gvr: gvr, gvk: resourceKinds[gvr]}
+}
+
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (g *GenericResource) List(namespace string, label map[string]string) ([]runtime.Object, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	if g.versions.IsServed(g.gvr) {
+		informer, err := g.SharedInformerFactory.ForResource(g.gvr)
+		if err == nil {
+			objects, err := informer.Lister().ByNamespace(namespace).List(selector)
+			if err == nil && len(objects) != 0 {
+				return objects, nil
+			}
+		}
+	}
+	list, err := g.newObject(g.gvk.Kind + "List")
+	if err != nil {
+		return nil, err
+	}
+	if err = g.resource(g.gvr).List(namespace, selector, list); err != nil {
+		return nil, err
+	}
+	return meta.ExtractList(list)
+}
+
+func (g *GenericResource) Get(namespace, name string) (runtime.Object, error) {
+	if g.versions.IsServed(g.gvr) {
+		informer, err := g.SharedInformerFactory.ForResource(g.gvr)
+		if err == nil {
+			if obj, err := informer.Lister().ByNamespace(namespace).Get(name); err == nil {
+				return obj, nil
+			}
+		}
+	}
+	obj, err := g.newObject(g.gvk.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if err = g.resource(g.gvr).Get(namespace, name, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (g *GenericResource) Create(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+	created, err := g.newObject(g.gvk.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if err = g.resource(g.gvr).Create(obj, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update obj 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (g *GenericResource) Update(obj runtime.Object, dryRun bool) (runtime.Object, error) {
+	updated, err := g.newObject(g.gvk.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if err = g.resource(g.gvr).Update(obj, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (g *GenericResource) Delete(namespace, name, resourceVersion string, dryRun bool) error {
+	return g.resource(g.gvr).Delete(namespace, name, resourceVersion, dryRun)
+}
+
+// newObject 返回gvr所在版本中kind对应的类型
+func (g *GenericResource) newObject(kind string) (runtime.Object, error) {
+	if g.gvk.Empty() {
+		return nil, fmt.Errorf("unsupported istio resource %s", g.gvr)
+	}
+	return scheme.Scheme.New(g.gvk.GroupVersion().WithKind(kind))
+}
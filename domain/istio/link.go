@@ -0,0 +1,46 @@
+package istio
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// linkableKinds dashboard中提供了 get 接口的资源, 按group区分, 两个group都有Gateway
+var linkableKinds = map[string]map[string]bool{
+	v1alpha3.GroupName: {
+		"VirtualService":  true,
+		"Gateway":         true,
+		"DestinationRule": true,
+		"ServiceEntry":    true,
+		"EnvoyFilter":     true,
+	},
+	gatewayapi.GroupName: {
+		"GatewayClass":   true,
+		"Gateway":        true,
+		"HTTPRoute":      true,
+		"TCPRoute":       true,
+		"TLSRoute":       true,
+		"ReferenceGrant": true,
+	},
+}
+
+// ResourceLink 返回资源在dashboard中的访问路径, 用于在envoy配置和istio资源之间跳转, kubeConfigId 为集群的id;
+// 网关API资源(HTTPRoute等)在 /gatewayapi 下, 其余istio资源在 /istio 下, 没有 get 接口的资源返回空
+func ResourceLink(kubeConfigId int64, group, kind, namespace, name string) string {
+	if !linkableKinds[group][kind] {
+		return ""
+	}
+	prefix := "/istio"
+	if group == gatewayapi.GroupName {
+		prefix = "/gatewayapi"
+	}
+	query := url.Values{}
+	query.Set("id", fmt.Sprint(kubeConfigId))
+	query.Set("namespace", namespace)
+	query.Set("name", name)
+	return fmt.Sprintf("%s/%s/get?%s", prefix, strings.ToLower(kind), query.Encode())
+}
@@ -0,0 +1,215 @@
+package istio
+
+import (
+	"errors"
+	"fmt"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+)
+
+// MirrorTarget 镜像流量的目标, Host 为空时为服务自身, 此时 Subset 为影子版本; Host 为网格外的地址时需要有对应的 ServiceEntry;
+// Percentage 为镜像请求的百分比, 镜像请求的响应会被丢弃
+type MirrorTarget struct {
+	Host       string  `json:"host"`
+	Subset     string  `json:"subset"`
+	Port       uint32  `json:"port"`
+	Percentage float64 `json:"percentage"`
+}
+
+// MirrorConfig Route 为空时修改所有只路由到该服务的http路由
+type MirrorConfig struct {
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	Route     string `json:"route"`
+	MirrorTarget
+}
+
+// MirrorRoute VirtualService 中一条路由到服务的http路由, 没有镜像时 Mirror 为空
+type MirrorRoute struct {
+	Name   string        `json:"name"`
+	Index  int           `json:"index"`
+	Mirror *MirrorTarget `json:"mirror"`
+}
+
+// MirrorPolicy 服务的 VirtualService 中的流量镜像, 没有 VirtualService 时名称为空
+type MirrorPolicy struct {
+	Namespace       string        `json:"namespace"`
+	Service         string        `json:"service"`
+	VirtualService  string        `json:"virtualService"`
+	ResourceVersion string        `json:"resourceVersion"`
+	Routes          []MirrorRoute `json:"routes"`
+	Warnings        []string      `json:"warnings"`
+}
+
+func (c *MirrorConfig) Validate() error {
+	if c.Namespace == "" || c.Service == "" {
+		return errors.New("namespace and service are required")
+	}
+	if c.Host == "" && c.Subset == "" {
+		return errors.New("at least one of host and subset is required")
+	}
+	if c.Host != "" && c.Subset == "" && convertHostToFQDN(c.Namespace, c.Host) == convertHostToFQDN(c.Namespace, c.Service) {
+		return errors.New("subset is required when mirroring to the service itself")
+	}
+	if c.Percentage <= 0 || c.Percentage > 100 {
+		return errors.New("percentage must be in (0, 100]")
+	}
+	return nil
+}
+
+func (c *MirrorConfig) mirrorHost() string {
+	if c.Host == "" {
+		return c.Service
+	}
+	return c.Host
+}
+
+// GetMirror 获取服务当前的流量镜像
+func (i *IstioClient) GetMirror(namespace, service string) (*MirrorPolicy, error) {
+	vs, err := i.FindServiceVirtualService(namespace, service)
+	if err != nil {
+		return nil, err
+	}
+	return newMirrorPolicy(namespace, service, vs), nil
+}
+
+// SetMirror 使用 json patch 设置路由的 mirror 和 mirrorPercentage, 同时删除已废弃的 mirrorPercent; VirtualService 不存在时新建
+func (i *IstioClient) SetMirror(config *MirrorConfig, dryRun bool) (*MirrorPolicy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	warnings := make([]string, 0)
+	if config.Subset != "" {
+		if exist, err := i.hasSubset(config.Namespace, config.mirrorHost(), config.Subset); err != nil {
+			warnings = append(warnings, fmt.Sprintf("lookup subset %s of %s failed: %s", config.Subset, config.mirrorHost(), err))
+		} else if !exist {
+			warnings = append(warnings, fmt.Sprintf("subset %s of %s is not defined in any destinationrule, mirrored requests will fail", config.Subset, config.mirrorHost()))
+		}
+	}
+	mirror := &networking.Destination{Host: config.mirrorHost(), Subset: config.Subset}
+	if config.Port != 0 {
+		mirror.Port = &networking.PortSelector{Number: config.Port}
+	}
+	percentage := &networking.Percent{Value: config.Percentage}
+
+	vs, err := i.FindServiceVirtualService(config.Namespace, config.Service)
+	if err != nil {
+		return nil, err
+	}
+	if vs == nil {
+		if config.Route != "" {
+			return nil, fmt.Errorf("service %s has no virtualservice with http route %s", config.Service, config.Route)
+		}
+		vs = newServiceVirtualService(config.Namespace, config.Service, config.Service, 0)
+		vs.Spec.Http[0].Mirror = mirror
+		vs.Spec.Http[0].MirrorPercentage = percentage
+		if vs, err = NewVirtualService(i).Create(vs, dryRun); err != nil {
+			return nil, err
+		}
+	} else {
+		vs, err = i.patchServiceRoutes(vs, config.Namespace, config.Service, config.Route, dryRun, func(index int, http *networking.HTTPRoute) []map[string]interface{} {
+			ops := []map[string]interface{}{
+				{"op": "add", "path": fmt.Sprintf("/spec/http/%d/mirror", index), "value": mirror},
+				{"op": "add", "path": fmt.Sprintf("/spec/http/%d/mirrorPercentage", index), "value": percentage},
+			}
+			if http.GetMirrorPercent() != nil {
+				ops = append(ops, map[string]interface{}{"op": "remove", "path": fmt.Sprintf("/spec/http/%d/mirrorPercent", index)})
+			}
+			return ops
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	policy := newMirrorPolicy(config.Namespace, config.Service, vs)
+	policy.Warnings = warnings
+	return policy, nil
+}
+
+// RemoveMirror 删除路由上的流量镜像, route 为空时删除所有路由到该服务的http路由上的镜像
+func (i *IstioClient) RemoveMirror(namespace, service, route string, dryRun bool) (*MirrorPolicy, error) {
+	vs, err := i.FindServiceVirtualService(namespace, service)
+	if err != nil {
+		return nil, err
+	}
+	if vs == nil {
+		return newMirrorPolicy(namespace, service, nil), nil
+	}
+	vs, err = i.patchServiceRoutes(vs, namespace, service, route, dryRun, func(index int, http *networking.HTTPRoute) []map[string]interface{} {
+		ops := make([]map[string]interface{}, 0)
+		if http.GetMirror() != nil {
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": fmt.Sprintf("/spec/http/%d/mirror", index)})
+		}
+		if http.GetMirrorPercentage() != nil {
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": fmt.Sprintf("/spec/http/%d/mirrorPercentage", index)})
+		}
+		if http.GetMirrorPercent() != nil {
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": fmt.Sprintf("/spec/http/%d/mirrorPercent", index)})
+		}
+		return ops
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newMirrorPolicy(namespace, service, vs), nil
+}
+
+// SubsetLabels 获取host的 DestinationRule 中subset的标签, 没有找到subset时返回nil
+func (i *IstioClient) SubsetLabels(namespace, host, subset string) (map[string]string, error) {
+	destinationRules, err := NewDestinationRule(i).List(namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	fqdn := convertHostToFQDN(namespace, host)
+	for _, dr := range destinationRules {
+		if convertHostToFQDN(dr.Namespace, dr.Spec.GetHost()) != fqdn {
+			continue
+		}
+		for _, s := range dr.Spec.GetSubsets() {
+			if s.GetName() != subset {
+				continue
+			}
+			labels := map[string]string{}
+			for key, value := range s.GetLabels() {
+				labels[key] = value
+			}
+			return labels, nil
+		}
+	}
+	return nil, nil
+}
+
+func newMirrorPolicy(namespace, service string, vs *v1alpha3.VirtualService) *MirrorPolicy {
+	policy := &MirrorPolicy{Namespace: namespace, Service: service, Routes: make([]MirrorRoute, 0), Warnings: make([]string, 0)}
+	if vs == nil {
+		return policy
+	}
+	policy.VirtualService = vs.Name
+	policy.ResourceVersion = vs.ResourceVersion
+	for _, index := range serviceRouteIndexes(vs, namespace, service) {
+		http := vs.Spec.Http[index]
+		route := MirrorRoute{Name: http.GetName(), Index: index}
+		if mirror := http.GetMirror(); mirror != nil {
+			route.Mirror = &MirrorTarget{
+				Host:       mirror.GetHost(),
+				Subset:     mirror.GetSubset(),
+				Port:       mirror.GetPort().GetNumber(),
+				Percentage: mirrorPercentage(http),
+			}
+		}
+		policy.Routes = append(policy.Routes, route)
+	}
+	return policy
+}
+
+// mirrorPercentage 都没有设置时istio镜像全部请求
+func mirrorPercentage(http *networking.HTTPRoute) float64 {
+	if http.GetMirrorPercentage() != nil {
+		return http.GetMirrorPercentage().GetValue()
+	}
+	if http.GetMirrorPercent() != nil {
+		return float64(http.GetMirrorPercent().GetValue())
+	}
+	return 100
+}
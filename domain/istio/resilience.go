@@ -0,0 +1,485 @@
+package istio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// 可以删除的策略
+const (
+	PolicyConnectionPool   = "connectionPool"
+	PolicyOutlierDetection = "outlierDetection"
+	PolicyRetries          = "retries"
+	PolicyTimeout          = "timeout"
+)
+
+var retryOnPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// 以下类型的json字段与istio资源中的字段一致, 可以直接作为 merge patch 使用; 时间为 1s、500ms 格式的字符串, 为0或空的字段不修改
+
+type TCPSettings struct {
+	MaxConnections int32  `json:"maxConnections,omitempty"`
+	ConnectTimeout string `json:"connectTimeout,omitempty"`
+}
+
+type HTTPSettings struct {
+	Http1MaxPendingRequests  int32  `json:"http1MaxPendingRequests,omitempty"`
+	Http2MaxRequests         int32  `json:"http2MaxRequests,omitempty"`
+	MaxRequestsPerConnection int32  `json:"maxRequestsPerConnection,omitempty"`
+	MaxRetries               int32  `json:"maxRetries,omitempty"`
+	IdleTimeout              string `json:"idleTimeout,omitempty"`
+}
+
+type ConnectionPool struct {
+	Tcp  *TCPSettings  `json:"tcp,omitempty"`
+	Http *HTTPSettings `json:"http,omitempty"`
+}
+
+type OutlierDetection struct {
+	Consecutive5xxErrors     uint32 `json:"consecutive5xxErrors,omitempty"`
+	ConsecutiveGatewayErrors uint32 `json:"consecutiveGatewayErrors,omitempty"`
+	Interval                 string `json:"interval,omitempty"`
+	BaseEjectionTime         string `json:"baseEjectionTime,omitempty"`
+	MaxEjectionPercent       int32  `json:"maxEjectionPercent,omitempty"`
+	MinHealthPercent         int32  `json:"minHealthPercent,omitempty"`
+}
+
+// RetryPolicy attempts 为0时不重试, retryOn 为逗号分隔的重试条件, 如 5xx,connect-failure
+type RetryPolicy struct {
+	Attempts      int32  `json:"attempts"`
+	PerTryTimeout string `json:"perTryTimeout,omitempty"`
+	RetryOn       string `json:"retryOn,omitempty"`
+}
+
+// RoutePolicy VirtualService 中一条路由到服务的http路由的重试和超时
+type RoutePolicy struct {
+	Name    string       `json:"name"`
+	Index   int          `json:"index"`
+	Retries *RetryPolicy `json:"retries,omitempty"`
+	Timeout string       `json:"timeout,omitempty"`
+}
+
+// ResiliencePolicy 连接池和异常检测来自服务的 DestinationRule 的 trafficPolicy(不包括端口级别的设置), 重试和超时来自服务的 VirtualService
+type ResiliencePolicy struct {
+	Namespace              string            `json:"namespace"`
+	Service                string            `json:"service"`
+	DestinationRule        string            `json:"destinationRule"`
+	DestinationRuleVersion string            `json:"destinationRuleVersion"`
+	ConnectionPool         *ConnectionPool   `json:"connectionPool,omitempty"`
+	OutlierDetection       *OutlierDetection `json:"outlierDetection,omitempty"`
+	VirtualService         string            `json:"virtualService"`
+	VirtualServiceVersion  string            `json:"virtualServiceVersion"`
+	Routes                 []RoutePolicy     `json:"routes"`
+}
+
+// ResiliencePatch 为空的字段不修改, 连接池和异常检测只修改设置的字段, 重试整体替换;
+// Route 为空时修改所有只路由到该服务的http路由; Remove 为要删除的策略 connectionPool/outlierDetection/retries/timeout
+type ResiliencePatch struct {
+	Namespace        string            `json:"namespace"`
+	Service          string            `json:"service"`
+	ConnectionPool   *ConnectionPool   `json:"connectionPool,omitempty"`
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+	Retries          *RetryPolicy      `json:"retries,omitempty"`
+	Timeout          string            `json:"timeout,omitempty"`
+	Route            string            `json:"route,omitempty"`
+	Remove           []string          `json:"remove,omitempty"`
+}
+
+func validateDuration(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: %s", field, err)
+	}
+	if d < time.Millisecond {
+		return fmt.Errorf("%s must be at least 1ms", field)
+	}
+	return nil
+}
+
+func (p *ResiliencePatch) removes(policy string) bool {
+	return containsString(p.Remove, policy)
+}
+
+func (p *ResiliencePatch) Validate() error {
+	if p.Namespace == "" || p.Service == "" {
+		return errors.New("namespace and service are required")
+	}
+	for _, policy := range p.Remove {
+		switch policy {
+		case PolicyConnectionPool, PolicyOutlierDetection, PolicyRetries, PolicyTimeout:
+		default:
+			return fmt.Errorf("unknown policy to remove: %s", policy)
+		}
+	}
+	if p.ConnectionPool == nil && p.OutlierDetection == nil && p.Retries == nil && p.Timeout == "" && len(p.Remove) == 0 {
+		return errors.New("nothing to change")
+	}
+	if (p.ConnectionPool != nil && p.removes(PolicyConnectionPool)) ||
+		(p.OutlierDetection != nil && p.removes(PolicyOutlierDetection)) ||
+		(p.Retries != nil && p.removes(PolicyRetries)) ||
+		(p.Timeout != "" && p.removes(PolicyTimeout)) {
+		return errors.New("a policy can not be set and removed at the same time")
+	}
+	if cp := p.ConnectionPool; cp != nil {
+		if cp.Tcp != nil {
+			if err := validateDuration("connectionPool.tcp.connectTimeout", cp.Tcp.ConnectTimeout); err != nil {
+				return err
+			}
+		}
+		if cp.Http != nil {
+			if err := validateDuration("connectionPool.http.idleTimeout", cp.Http.IdleTimeout); err != nil {
+				return err
+			}
+		}
+	}
+	if od := p.OutlierDetection; od != nil {
+		if err := validateDuration("outlierDetection.interval", od.Interval); err != nil {
+			return err
+		}
+		if err := validateDuration("outlierDetection.baseEjectionTime", od.BaseEjectionTime); err != nil {
+			return err
+		}
+		if od.MaxEjectionPercent < 0 || od.MaxEjectionPercent > 100 || od.MinHealthPercent < 0 || od.MinHealthPercent > 100 {
+			return errors.New("outlierDetection percents must be in [0, 100]")
+		}
+	}
+	if r := p.Retries; r != nil {
+		if r.Attempts < 0 {
+			return errors.New("retries.attempts must not be negative")
+		}
+		if err := validateDuration("retries.perTryTimeout", r.PerTryTimeout); err != nil {
+			return err
+		}
+		if r.RetryOn != "" {
+			for _, condition := range strings.Split(r.RetryOn, ",") {
+				if !retryOnPattern.MatchString(condition) {
+					return fmt.Errorf("invalid retryOn condition: %q", condition)
+				}
+			}
+		}
+	}
+	return validateDuration("timeout", p.Timeout)
+}
+
+// GetResiliencePolicy 获取服务当前的弹性策略, 没有 DestinationRule 或 VirtualService 时对应的名称为空
+func (i *IstioClient) GetResiliencePolicy(namespace, service string) (*ResiliencePolicy, error) {
+	dr, err := i.FindServiceDestinationRule(namespace, service)
+	if err != nil {
+		return nil, err
+	}
+	vs, err := i.FindServiceVirtualService(namespace, service)
+	if err != nil {
+		return nil, err
+	}
+	return newResiliencePolicy(namespace, service, dr, vs), nil
+}
+
+// SetResiliencePolicy DestinationRule 使用 merge patch, VirtualService 使用 json patch 修改路由的重试和超时, 不会替换整个spec;
+// 补丁中带上读取时的 resourceVersion, 期间被其他人修改时重新读取后再修改; 资源不存在时新建
+func (i *IstioClient) SetResiliencePolicy(patch *ResiliencePatch, dryRun bool) (*ResiliencePolicy, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	dr, err := i.FindServiceDestinationRule(patch.Namespace, patch.Service)
+	if err != nil {
+		return nil, err
+	}
+	if patch.ConnectionPool != nil || patch.OutlierDetection != nil ||
+		patch.removes(PolicyConnectionPool) || patch.removes(PolicyOutlierDetection) {
+		if dr, err = i.patchDestinationRule(dr, patch, dryRun); err != nil {
+			return nil, err
+		}
+	}
+	vs, err := i.FindServiceVirtualService(patch.Namespace, patch.Service)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Retries != nil || patch.Timeout != "" || patch.removes(PolicyRetries) || patch.removes(PolicyTimeout) {
+		if vs, err = i.patchVirtualService(vs, patch, dryRun); err != nil {
+			return nil, err
+		}
+	}
+	return newResiliencePolicy(patch.Namespace, patch.Service, dr, vs), nil
+}
+
+// FindServiceDestinationRule 使用服务命名空间下host为该服务的 DestinationRule, 多个时按名称取第一个, 没有时返回nil
+func (i *IstioClient) FindServiceDestinationRule(namespace, service string) (*v1alpha3.DestinationRule, error) {
+	destinationRules, err := NewDestinationRule(i).List(namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(destinationRules, func(a, b int) bool {
+		return destinationRules[a].Name < destinationRules[b].Name
+	})
+	serviceHost := convertHostToFQDN(namespace, service)
+	for _, dr := range destinationRules {
+		if convertHostToFQDN(dr.Namespace, dr.Spec.GetHost()) == serviceHost {
+			return dr, nil
+		}
+	}
+	return nil, nil
+}
+
+func (i *IstioClient) patchDestinationRule(dr *v1alpha3.DestinationRule, patch *ResiliencePatch, dryRun bool) (*v1alpha3.DestinationRule, error) {
+	trafficPolicy := map[string]interface{}{}
+	if patch.ConnectionPool != nil {
+		trafficPolicy[PolicyConnectionPool] = patch.ConnectionPool
+	} else if patch.removes(PolicyConnectionPool) {
+		trafficPolicy[PolicyConnectionPool] = nil
+	}
+	if patch.OutlierDetection != nil {
+		trafficPolicy[PolicyOutlierDetection] = patch.OutlierDetection
+	} else if patch.removes(PolicyOutlierDetection) {
+		trafficPolicy[PolicyOutlierDetection] = nil
+	}
+
+	destinationRule := NewDestinationRule(i)
+	if dr == nil {
+		if patch.ConnectionPool == nil && patch.OutlierDetection == nil {
+			return nil, nil
+		}
+		// 新建时通过json转换, 与 merge patch 使用相同的字段
+		data, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"name": patch.Service, "namespace": patch.Namespace},
+			"spec":     map[string]interface{}{"host": patch.Service, "trafficPolicy": trafficPolicy},
+		})
+		if err != nil {
+			return nil, err
+		}
+		dr = &v1alpha3.DestinationRule{}
+		if err = json.Unmarshal(data, dr); err != nil {
+			return nil, err
+		}
+		return destinationRule.Create(dr, dryRun)
+	}
+
+	var patched *v1alpha3.DestinationRule
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := destinationRule.getLatest(dr.Namespace, dr.Name)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"resourceVersion": latest.ResourceVersion},
+			"spec":     map[string]interface{}{"trafficPolicy": trafficPolicy},
+		})
+		if err != nil {
+			return err
+		}
+		patched, err = destinationRule.Patch(dr.Namespace, dr.Name, types.MergePatchType, data, dryRun)
+		return err
+	})
+	return patched, err
+}
+
+func (i *IstioClient) patchVirtualService(vs *v1alpha3.VirtualService, patch *ResiliencePatch, dryRun bool) (*v1alpha3.VirtualService, error) {
+	virtualService := NewVirtualService(i)
+	if vs == nil {
+		if patch.Retries == nil && patch.Timeout == "" {
+			return nil, nil
+		}
+		if patch.Route != "" {
+			return nil, fmt.Errorf("service %s has no virtualservice with http route %s", patch.Service, patch.Route)
+		}
+		vs = newServiceVirtualService(patch.Namespace, patch.Service, patch.Service, 0)
+		vs.Spec.Http[0].Retries = patch.Retries.toProto()
+		if patch.Timeout != "" {
+			vs.Spec.Http[0].Timeout = durationProto(patch.Timeout)
+		}
+		return virtualService.Create(vs, dryRun)
+	}
+
+	return i.patchServiceRoutes(vs, patch.Namespace, patch.Service, patch.Route, dryRun, func(index int, http *networking.HTTPRoute) []map[string]interface{} {
+		ops := make([]map[string]interface{}, 0)
+		if patch.Retries != nil {
+			ops = append(ops, map[string]interface{}{"op": "add", "path": fmt.Sprintf("/spec/http/%d/retries", index), "value": patch.Retries})
+		} else if patch.removes(PolicyRetries) && http.GetRetries() != nil {
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": fmt.Sprintf("/spec/http/%d/retries", index)})
+		}
+		if patch.Timeout != "" {
+			ops = append(ops, map[string]interface{}{"op": "add", "path": fmt.Sprintf("/spec/http/%d/timeout", index), "value": patch.Timeout})
+		} else if patch.removes(PolicyTimeout) && http.GetTimeout() != nil {
+			ops = append(ops, map[string]interface{}{"op": "remove", "path": fmt.Sprintf("/spec/http/%d/timeout", index)})
+		}
+		return ops
+	})
+}
+
+// patchServiceRoutes 使用 json patch 修改只路由到服务的http路由, route 不为空时只修改该路由, routeOps 返回一条路由的修改
+func (i *IstioClient) patchServiceRoutes(vs *v1alpha3.VirtualService, namespace, service, route string, dryRun bool,
+	routeOps func(index int, http *networking.HTTPRoute) []map[string]interface{}) (*v1alpha3.VirtualService, error) {
+	virtualService := NewVirtualService(i)
+	var patched *v1alpha3.VirtualService
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest, err := virtualService.getLatest(vs.Namespace, vs.Name)
+		if err != nil {
+			return err
+		}
+		indexes := serviceRouteIndexes(latest, namespace, service)
+		if route != "" {
+			indexes = filterRouteIndexes(latest, indexes, route)
+		}
+		if len(indexes) == 0 {
+			return fmt.Errorf("virtualservice %s/%s has no http route only to service %s", latest.Namespace, latest.Name, service)
+		}
+		// 替换 resourceVersion 作为前置条件, 期间被修改过时返回 Conflict, 保证下标对应的还是同一条路由
+		ops := []map[string]interface{}{
+			{"op": "replace", "path": "/metadata/resourceVersion", "value": latest.ResourceVersion},
+		}
+		for _, index := range indexes {
+			ops = append(ops, routeOps(index, latest.Spec.Http[index])...)
+		}
+		data, err := json.Marshal(ops)
+		if err != nil {
+			return err
+		}
+		patched, err = virtualService.Patch(latest.Namespace, latest.Name, types.JSONPatchType, data, dryRun)
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("virtualservice %s/%s was deleted: %s", vs.Namespace, vs.Name, err)
+	}
+	return patched, err
+}
+
+// serviceRouteIndexes 所有目标都是该服务的http路由的下标
+func serviceRouteIndexes(vs *v1alpha3.VirtualService, namespace, service string) []int {
+	serviceHost := convertHostToFQDN(namespace, service)
+	indexes := make([]int, 0)
+	for index, http := range vs.Spec.GetHttp() {
+		if len(http.GetRoute()) == 0 {
+			continue
+		}
+		onlyService := true
+		for _, route := range http.GetRoute() {
+			if convertHostToFQDN(vs.Namespace, route.GetDestination().GetHost()) != serviceHost {
+				onlyService = false
+				break
+			}
+		}
+		if onlyService {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+func filterRouteIndexes(vs *v1alpha3.VirtualService, indexes []int, name string) []int {
+	for _, index := range indexes {
+		if vs.Spec.Http[index].GetName() == name {
+			return []int{index}
+		}
+	}
+	return nil
+}
+
+func newResiliencePolicy(namespace, service string, dr *v1alpha3.DestinationRule, vs *v1alpha3.VirtualService) *ResiliencePolicy {
+	policy := &ResiliencePolicy{Namespace: namespace, Service: service, Routes: make([]RoutePolicy, 0)}
+	if dr != nil {
+		policy.DestinationRule = dr.Name
+		policy.DestinationRuleVersion = dr.ResourceVersion
+		policy.ConnectionPool = connectionPoolFromProto(dr.Spec.GetTrafficPolicy().GetConnectionPool())
+		policy.OutlierDetection = outlierDetectionFromProto(dr.Spec.GetTrafficPolicy().GetOutlierDetection())
+	}
+	if vs != nil {
+		policy.VirtualService = vs.Name
+		policy.VirtualServiceVersion = vs.ResourceVersion
+		for _, index := range serviceRouteIndexes(vs, namespace, service) {
+			http := vs.Spec.Http[index]
+			policy.Routes = append(policy.Routes, RoutePolicy{
+				Name:    http.GetName(),
+				Index:   index,
+				Retries: retryPolicyFromProto(http.GetRetries()),
+				Timeout: durationString(http.GetTimeout()),
+			})
+		}
+	}
+	return policy
+}
+
+func connectionPoolFromProto(cp *networking.ConnectionPoolSettings) *ConnectionPool {
+	if cp == nil {
+		return nil
+	}
+	pool := &ConnectionPool{}
+	if tcp := cp.GetTcp(); tcp != nil {
+		pool.Tcp = &TCPSettings{
+			MaxConnections: tcp.GetMaxConnections(),
+			ConnectTimeout: durationString(tcp.GetConnectTimeout()),
+		}
+	}
+	if http := cp.GetHttp(); http != nil {
+		pool.Http = &HTTPSettings{
+			Http1MaxPendingRequests:  http.GetHttp1MaxPendingRequests(),
+			Http2MaxRequests:         http.GetHttp2MaxRequests(),
+			MaxRequestsPerConnection: http.GetMaxRequestsPerConnection(),
+			MaxRetries:               http.GetMaxRetries(),
+			IdleTimeout:              durationString(http.GetIdleTimeout()),
+		}
+	}
+	return pool
+}
+
+func outlierDetectionFromProto(od *networking.OutlierDetection) *OutlierDetection {
+	if od == nil {
+		return nil
+	}
+	return &OutlierDetection{
+		Consecutive5xxErrors:     od.GetConsecutive_5XxErrors().GetValue(),
+		ConsecutiveGatewayErrors: od.GetConsecutiveGatewayErrors().GetValue(),
+		Interval:                 durationString(od.GetInterval()),
+		BaseEjectionTime:         durationString(od.GetBaseEjectionTime()),
+		MaxEjectionPercent:       od.GetMaxEjectionPercent(),
+		MinHealthPercent:         od.GetMinHealthPercent(),
+	}
+}
+
+func retryPolicyFromProto(retries *networking.HTTPRetry) *RetryPolicy {
+	if retries == nil {
+		return nil
+	}
+	return &RetryPolicy{
+		Attempts:      retries.GetAttempts(),
+		PerTryTimeout: durationString(retries.GetPerTryTimeout()),
+		RetryOn:       retries.GetRetryOn(),
+	}
+}
+
+func (r *RetryPolicy) toProto() *networking.HTTPRetry {
+	if r == nil {
+		return nil
+	}
+	retries := &networking.HTTPRetry{Attempts: r.Attempts, RetryOn: r.RetryOn}
+	if r.PerTryTimeout != "" {
+		retries.PerTryTimeout = durationProto(r.PerTryTimeout)
+	}
+	return retries
+}
+
+func durationString(d *durationpb.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return d.AsDuration().String()
+}
+
+// durationProto value 已经校验过
+func durationProto(value string) *durationpb.Duration {
+	d, _ := time.ParseDuration(value)
+	return durationpb.New(d)
+}
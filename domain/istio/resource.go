@@ -0,0 +1,34 @@
+package istio
+
+import (
+	"fmt"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+
+	"istio.io/client-go/pkg/clientset/versioned/scheme"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// resource gvr 为wrapper使用的类型对应的资源, 实际请求的版本由集群提供的版本决定
+func (i *IstioClient) resource(gvr schema.GroupVersionResource) *kube.ResourceClient {
+	negotiated, err := i.versions.Negotiate(gvr.GroupResource(), supportedVersions[gvr.Group])
+	if err != nil {
+		return kube.NewResourceClientError(gvr, err)
+	}
+
+	i.restClientsMu.Lock()
+	defer i.restClientsMu.Unlock()
+	gv := negotiated.GroupVersion()
+	cli, ok := i.restClients[gv]
+	if !ok {
+		config := rest.CopyConfig(i.restConfig)
+		config.GroupVersion = &gv
+		cli = kube.NewRestClient(config)
+		if cli == nil {
+			return kube.NewResourceClientError(negotiated, fmt.Errorf("new rest client for %s failed", gv))
+		}
+		i.restClients[gv] = cli
+	}
+	return kube.NewResourceClient(cli, negotiated, scheme.Scheme)
+}
@@ -0,0 +1,498 @@
+package istio
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"google.golang.org/protobuf/proto"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const cookieHeader = "cookie"
+
+// StringMatch exact/prefix/regex 只能设置一个
+type StringMatch struct {
+	Exact  string `json:"exact,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+	Regex  string `json:"regex,omitempty"`
+}
+
+// RouteMatch 请求的匹配条件, 多个条件之间为且的关系
+type RouteMatch struct {
+	Headers      map[string]StringMatch `json:"headers,omitempty"`
+	Cookies      map[string]StringMatch `json:"cookies,omitempty"`
+	QueryParams  map[string]StringMatch `json:"queryParams,omitempty"`
+	SourceLabels map[string]string      `json:"sourceLabels,omitempty"`
+}
+
+// RoutingRule 把满足所有条件的请求路由到服务的subset, Name 为http路由名, 同名的路由会被更新
+type RoutingRule struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Service   string `json:"service"`
+	RouteMatch
+	Subset string `json:"subset"`
+	Port   uint32 `json:"port,omitempty"`
+}
+
+// RoutingRulePreview Diff 为合并前后 VirtualService 的yaml差异, ResourceVersion 为预览时的版本, 新建时为空
+type RoutingRulePreview struct {
+	Namespace       string   `json:"namespace"`
+	VirtualService  string   `json:"virtualService"`
+	ResourceVersion string   `json:"resourceVersion"`
+	Created         bool     `json:"created"`
+	Index           int      `json:"index"`
+	Warnings        []string `json:"warnings"`
+	Diff            string   `json:"diff"`
+
+	merged *v1alpha3.VirtualService
+}
+
+func (m StringMatch) validate() error {
+	set := 0
+	for _, v := range []string{m.Exact, m.Prefix, m.Regex} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of exact, prefix and regex is required")
+	}
+	if m.Regex != "" {
+		if _, err := regexp.Compile(m.Regex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m StringMatch) toProto() *networking.StringMatch {
+	switch {
+	case m.Exact != "":
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: m.Exact}}
+	case m.Prefix != "":
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: m.Prefix}}
+	}
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: m.Regex}}
+}
+
+// cookieMatch istio 不支持直接匹配cookie, 转换为对cookie请求头的正则匹配, envoy的正则需要匹配整个请求头
+func cookieMatch(name string, m StringMatch) *networking.StringMatch {
+	value := m.Regex
+	switch {
+	case m.Exact != "":
+		value = regexp.QuoteMeta(m.Exact)
+	case m.Prefix != "":
+		value = regexp.QuoteMeta(m.Prefix) + "[^;]*"
+	}
+	regex := fmt.Sprintf(`^(.*?;\s*)?%s=(?:%s)(;.*)?$`, regexp.QuoteMeta(name), value)
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: regex}}
+}
+
+// IsEmpty 没有任何条件时匹配所有请求
+func (m *RouteMatch) IsEmpty() bool {
+	return len(m.Headers)+len(m.Cookies)+len(m.QueryParams)+len(m.SourceLabels) == 0
+}
+
+func (m *RouteMatch) Validate() error {
+	// 多个cookie无法用一个与顺序无关的正则表达
+	if len(m.Cookies) > 1 {
+		return errors.New("only one cookie condition is supported")
+	}
+	for name, sm := range m.Headers {
+		if len(m.Cookies) > 0 && strings.EqualFold(name, cookieHeader) {
+			return errors.New("cookie header can not be used with cookies")
+		}
+		if err := sm.validate(); err != nil {
+			return fmt.Errorf("header %s: %s", name, err)
+		}
+	}
+	for name, sm := range m.Cookies {
+		if err := sm.validate(); err != nil {
+			return fmt.Errorf("cookie %s: %s", name, err)
+		}
+	}
+	for name, sm := range m.QueryParams {
+		if err := sm.validate(); err != nil {
+			return fmt.Errorf("query param %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+func (m *RouteMatch) toProto() *networking.HTTPMatchRequest {
+	match := &networking.HTTPMatchRequest{}
+	if len(m.Headers)+len(m.Cookies) > 0 {
+		match.Headers = make(map[string]*networking.StringMatch)
+	}
+	// envoy中请求头都是小写的
+	for name, sm := range m.Headers {
+		match.Headers[strings.ToLower(name)] = sm.toProto()
+	}
+	for name, sm := range m.Cookies {
+		match.Headers[cookieHeader] = cookieMatch(name, sm)
+	}
+	if len(m.QueryParams) > 0 {
+		match.QueryParams = make(map[string]*networking.StringMatch)
+		for name, sm := range m.QueryParams {
+			match.QueryParams[name] = sm.toProto()
+		}
+	}
+	if len(m.SourceLabels) > 0 {
+		match.SourceLabels = m.SourceLabels
+	}
+	return match
+}
+
+func (r *RoutingRule) Validate() error {
+	if r.Name == "" || r.Namespace == "" || r.Service == "" || r.Subset == "" {
+		return errors.New("name, namespace, service and subset are required")
+	}
+	if r.RouteMatch.IsEmpty() {
+		return errors.New("at least one match condition is required")
+	}
+	return r.RouteMatch.Validate()
+}
+
+func (r *RoutingRule) destination(port *networking.PortSelector) *networking.Destination {
+	destination := &networking.Destination{Host: r.Service, Subset: r.Subset}
+	if r.Port != 0 {
+		destination.Port = &networking.PortSelector{Number: r.Port}
+	} else if port != nil {
+		destination.Port = &networking.PortSelector{Number: port.GetNumber()}
+	}
+	return destination
+}
+
+// PreviewRoutingRule 把规则合并到服务的 VirtualService 中并返回差异, 不会修改集群
+func (i *IstioClient) PreviewRoutingRule(rule *RoutingRule) (*RoutingRulePreview, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	current, err := i.FindServiceVirtualService(rule.Namespace, rule.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &RoutingRulePreview{Namespace: rule.Namespace, Warnings: make([]string, 0)}
+	var merged *v1alpha3.VirtualService
+	if current == nil {
+		preview.Created = true
+		merged = newServiceVirtualService(rule.Namespace, rule.Service, rule.Service, rule.Port)
+	} else {
+		preview.ResourceVersion = current.ResourceVersion
+		merged = current.DeepCopy()
+	}
+	preview.VirtualService = merged.Name
+	preview.Index, preview.Warnings = mergeRoutingRule(merged, rule)
+	preview.merged = merged
+
+	if exist, err := i.hasSubset(rule.Namespace, rule.Service, rule.Subset); err != nil {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("lookup subset %s failed: %s", rule.Subset, err))
+	} else if !exist {
+		preview.Warnings = append(preview.Warnings,
+			fmt.Sprintf("subset %s is not defined in any DestinationRule for host %s", rule.Subset, rule.Service))
+	}
+	if preview.Diff, err = diffVirtualService(current, merged); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// ApplyRoutingRule resourceVersion 为预览时的版本, 不为空时只有 VirtualService 没有被修改过才会应用
+func (i *IstioClient) ApplyRoutingRule(rule *RoutingRule, resourceVersion string, dryRun bool) (*RoutingRulePreview, error) {
+	preview, err := i.PreviewRoutingRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	virtualService := NewVirtualService(i)
+	var applied *v1alpha3.VirtualService
+	if preview.Created {
+		if resourceVersion != "" {
+			return nil, apierrors.NewConflict(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService).GroupResource(),
+				preview.VirtualService, errors.New("the virtualservice has been deleted since preview"))
+		}
+		applied, err = virtualService.Create(preview.merged, dryRun)
+	} else {
+		if resourceVersion != "" {
+			preview.merged.ResourceVersion = resourceVersion
+		}
+		applied, err = virtualService.Update(preview.merged, dryRun)
+	}
+	if err != nil {
+		return nil, err
+	}
+	preview.ResourceVersion = applied.ResourceVersion
+	return preview, nil
+}
+
+// FindServiceVirtualService 使用服务命名空间下作用于mesh且包含该服务域名的 VirtualService, 多个时按名称取第一个, 没有时返回nil
+func (i *IstioClient) FindServiceVirtualService(namespace, service string) (*v1alpha3.VirtualService, error) {
+	virtualServices, err := NewVirtualService(i).List(namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(virtualServices, func(a, b int) bool {
+		return virtualServices[a].Name < virtualServices[b].Name
+	})
+	serviceHost := convertHostToFQDN(namespace, service)
+	for _, vs := range virtualServices {
+		if !isMeshVirtualService(vs) {
+			continue
+		}
+		for _, host := range vs.Spec.GetHosts() {
+			if convertHostToFQDN(vs.Namespace, host) == serviceHost {
+				return vs, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// newServiceVirtualService 只有一条路由到服务的默认路由
+func newServiceVirtualService(namespace, name, service string, port uint32) *v1alpha3.VirtualService {
+	vs := &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+	vs.Spec.Hosts = []string{service}
+	defaultRoute := &networking.HTTPRoute{
+		Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: service}}},
+	}
+	if port != 0 {
+		defaultRoute.Route[0].Destination.Port = &networking.PortSelector{Number: port}
+	}
+	vs.Spec.Http = []*networking.HTTPRoute{defaultRoute}
+	return vs
+}
+
+func (i *IstioClient) hasSubset(namespace, host, subset string) (bool, error) {
+	labels, err := i.SubsetLabels(namespace, host, subset)
+	return labels != nil, err
+}
+
+// mergeRoutingRule 返回规则所在的位置和提示
+// 新规则放在第一条会匹配到它的请求的路由之前, 这条路由之后比新规则更具体的路由原本就匹配不到, 所以不会改变其他路由的行为
+func mergeRoutingRule(vs *v1alpha3.VirtualService, rule *RoutingRule) (int, []string) {
+	warnings := make([]string, 0)
+	match := rule.RouteMatch.toProto()
+
+	var route *networking.HTTPRoute
+	oldIndex := -1
+	for index, http := range vs.Spec.GetHttp() {
+		if http.GetName() == rule.Name {
+			route, oldIndex = http, index
+			vs.Spec.Http = append(vs.Spec.Http[:index:index], vs.Spec.Http[index+1:]...)
+			break
+		}
+	}
+
+	index := routeIndex(vs.Spec.Http, match)
+	// 更新已有的规则时, 原来的位置仍然可以匹配到则保持不变
+	if oldIndex >= 0 && oldIndex < index {
+		index = oldIndex
+	}
+
+	var fallback *networking.HTTPRoute
+	if index < len(vs.Spec.Http) {
+		fallback = vs.Spec.Http[index]
+	}
+	if route == nil {
+		route = &networking.HTTPRoute{Name: rule.Name}
+		// 继承原本会处理这些请求的路由的超时、重试等策略
+		if fallback != nil && fallback.GetRedirect() == nil && fallback.GetDelegate() == nil {
+			route.Rewrite = fallback.GetRewrite()
+			route.Timeout = fallback.GetTimeout()
+			route.Retries = fallback.GetRetries()
+			route.Headers = fallback.GetHeaders()
+			route.CorsPolicy = fallback.GetCorsPolicy()
+		}
+	}
+	var port *networking.PortSelector
+	if fallback != nil && len(fallback.GetRoute()) > 0 {
+		port = fallback.GetRoute()[0].GetDestination().GetPort()
+	}
+	route.Match = []*networking.HTTPMatchRequest{match}
+	route.Redirect, route.Delegate = nil, nil
+	route.Route = []*networking.HTTPRouteDestination{{Destination: rule.destination(port)}}
+
+	vs.Spec.Http = append(vs.Spec.Http[:index:index], append([]*networking.HTTPRoute{route}, vs.Spec.Http[index:]...)...)
+	for i := index + 1; i < len(vs.Spec.Http); i++ {
+		http := vs.Spec.Http[i]
+		if len(http.GetMatch()) > 0 && matchesCoveredBy(http, match) {
+			warnings = append(warnings, fmt.Sprintf("http route %s is shadowed by rule %s", routeName(http, i), rule.Name))
+		}
+	}
+	return index, warnings
+}
+
+// routeIndex 第一条会匹配到这些请求的路由的位置, 没有时为路由的数量
+func routeIndex(routes []*networking.HTTPRoute, match *networking.HTTPMatchRequest) int {
+	for index, http := range routes {
+		if routeCovers(http, match) {
+			return index
+		}
+	}
+	return len(routes)
+}
+
+func routeName(http *networking.HTTPRoute, index int) string {
+	if http.GetName() != "" {
+		return http.GetName()
+	}
+	return fmt.Sprintf("http[%d]", index)
+}
+
+// routeCovers 满足 match 的请求都会被这条路由匹配到
+func routeCovers(http *networking.HTTPRoute, match *networking.HTTPMatchRequest) bool {
+	if len(http.GetMatch()) == 0 {
+		return true
+	}
+	for _, m := range http.GetMatch() {
+		if matchCovers(m, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCoveredBy 这条路由能匹配到的请求都会先被 match 匹配到
+func matchesCoveredBy(http *networking.HTTPRoute, match *networking.HTTPMatchRequest) bool {
+	for _, m := range http.GetMatch() {
+		if !matchCovers(match, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchCovers broad 的每个条件 narrow 都满足
+func matchCovers(broad, narrow *networking.HTTPMatchRequest) bool {
+	if !stringMatchCovers(broad.GetUri(), narrow.GetUri()) ||
+		!stringMatchCovers(broad.GetScheme(), narrow.GetScheme()) ||
+		!stringMatchCovers(broad.GetMethod(), narrow.GetMethod()) ||
+		!stringMatchCovers(broad.GetAuthority(), narrow.GetAuthority()) {
+		return false
+	}
+	if broad.GetUri() != nil && broad.GetIgnoreUriCase() != narrow.GetIgnoreUriCase() {
+		return false
+	}
+	if broad.GetPort() != 0 && broad.GetPort() != narrow.GetPort() {
+		return false
+	}
+	if broad.GetSourceNamespace() != "" && broad.GetSourceNamespace() != narrow.GetSourceNamespace() {
+		return false
+	}
+	if len(broad.GetGateways()) > 0 {
+		if len(narrow.GetGateways()) == 0 {
+			return false
+		}
+		for _, gateway := range narrow.GetGateways() {
+			if !containsString(broad.GetGateways(), gateway) {
+				return false
+			}
+		}
+	}
+	for name, m := range broad.GetHeaders() {
+		if !stringMatchCovers(m, narrow.GetHeaders()[name]) {
+			return false
+		}
+	}
+	for name, m := range broad.GetQueryParams() {
+		if !stringMatchCovers(m, narrow.GetQueryParams()[name]) {
+			return false
+		}
+	}
+	for name, m := range broad.GetWithoutHeaders() {
+		if !proto.Equal(m, narrow.GetWithoutHeaders()[name]) {
+			return false
+		}
+	}
+	for key, value := range broad.GetSourceLabels() {
+		if v, ok := narrow.GetSourceLabels()[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// stringMatchCovers 只识别相同的条件和前缀包含的情况, 正则无法判断
+func stringMatchCovers(broad, narrow *networking.StringMatch) bool {
+	if broad == nil {
+		return true
+	}
+	if narrow == nil {
+		return false
+	}
+	if proto.Equal(broad, narrow) {
+		return true
+	}
+	prefix := broad.GetPrefix()
+	if prefix == "" {
+		return false
+	}
+	switch {
+	case narrow.GetExact() != "":
+		return strings.HasPrefix(narrow.GetExact(), prefix)
+	case narrow.GetPrefix() != "":
+		return strings.HasPrefix(narrow.GetPrefix(), prefix)
+	}
+	return false
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// diffVirtualService 比较yaml格式的 VirtualService, 不包含 status
+func diffVirtualService(current, merged *v1alpha3.VirtualService) (string, error) {
+	from := ""
+	if current != nil {
+		current = current.DeepCopy()
+		current.Status.Reset()
+		data, err := kube.FormatResource(current, GVKVirtualService, kube.FormatYAML)
+		if err != nil {
+			return "", err
+		}
+		from = data.(string)
+	}
+	merged = merged.DeepCopy()
+	merged.Status.Reset()
+	data, err := kube.FormatResource(merged, GVKVirtualService, kube.FormatYAML)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(data.(string)),
+		FromFile: "current",
+		ToFile:   "proposed",
+		Context:  3,
+	})
+}
+
+// isMeshVirtualService 没有指定网关或者显式指定了mesh时, 路由作用于网格内的sidecar
+func isMeshVirtualService(vs *v1alpha3.VirtualService) bool {
+	if len(vs.Spec.GetGateways()) == 0 {
+		return true
+	}
+	for _, gateway := range vs.Spec.GetGateways() {
+		if gateway == meshGateway {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,334 @@
+package istio
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+)
+
+func exactMatch(value string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: value}}
+}
+
+func prefixMatch(value string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: value}}
+}
+
+func regexMatch(value string) *networking.StringMatch {
+	return &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: value}}
+}
+
+func headerRoute(name, header string, m *networking.StringMatch, subset string) *networking.HTTPRoute {
+	return &networking.HTTPRoute{
+		Name: name,
+		Match: []*networking.HTTPMatchRequest{{
+			Headers: map[string]*networking.StringMatch{header: m},
+		}},
+		Route: []*networking.HTTPRouteDestination{{
+			Destination: &networking.Destination{Host: "reviews", Subset: subset},
+		}},
+	}
+}
+
+func testVirtualService(port uint32, routes ...*networking.HTTPRoute) *v1alpha3.VirtualService {
+	vs := newServiceVirtualService("default", "reviews", "reviews", port)
+	vs.Spec.Http = append(routes, vs.Spec.Http...)
+	return vs
+}
+
+func routeNames(vs *v1alpha3.VirtualService) []string {
+	names := make([]string, 0)
+	for index, http := range vs.Spec.GetHttp() {
+		names = append(names, routeName(http, index))
+	}
+	return names
+}
+
+func TestMergeRoutingRule(t *testing.T) {
+	rule := func(name, header string, m StringMatch) *RoutingRule {
+		return &RoutingRule{
+			Name:       name,
+			Namespace:  "default",
+			Service:    "reviews",
+			RouteMatch: RouteMatch{Headers: map[string]StringMatch{header: m}},
+			Subset:     "v2",
+		}
+	}
+	cases := []struct {
+		name         string
+		vs           *v1alpha3.VirtualService
+		rule         *RoutingRule
+		wantIndex    int
+		wantNames    []string
+		wantWarnings []string
+	}{
+		{
+			name:         "inserted before the default route",
+			vs:           testVirtualService(0),
+			rule:         rule("canary", "end-user", StringMatch{Exact: "jason"}),
+			wantIndex:    0,
+			wantNames:    []string{"canary", "http[1]"},
+			wantWarnings: []string{},
+		},
+		{
+			name:         "more specific routes keep their priority",
+			vs:           testVirtualService(0, headerRoute("jason", "end-user", exactMatch("jason"), "v1")),
+			rule:         rule("canary", "end-user", StringMatch{Prefix: "ja"}),
+			wantIndex:    1,
+			wantNames:    []string{"jason", "canary", "http[2]"},
+			wantWarnings: []string{},
+		},
+		{
+			name:         "inserted before the first route covering the rule",
+			vs:           testVirtualService(0, headerRoute("ja", "end-user", prefixMatch("ja"), "v1")),
+			rule:         rule("canary", "end-user", StringMatch{Exact: "jason"}),
+			wantIndex:    0,
+			wantNames:    []string{"canary", "ja", "http[2]"},
+			wantWarnings: []string{},
+		},
+		{
+			name: "routes covered by the rule are reported as shadowed",
+			vs: testVirtualService(0,
+				headerRoute("j", "end-user", prefixMatch("j"), "v1"),
+				headerRoute("", "end-user", exactMatch("jason"), "v3"),
+			),
+			rule:      rule("canary", "end-user", StringMatch{Prefix: "j"}),
+			wantIndex: 0,
+			wantNames: []string{"canary", "j", "http[2]", "http[3]"},
+			wantWarnings: []string{
+				"http route j is shadowed by rule canary",
+				"http route http[2] is shadowed by rule canary",
+			},
+		},
+		{
+			name: "updating a rule keeps its position when still reachable",
+			vs: testVirtualService(0,
+				headerRoute("canary", "end-user", exactMatch("jason"), "v1"),
+				headerRoute("env", "x-env", prefixMatch("a"), "v1"),
+			),
+			rule:         rule("canary", "end-user", StringMatch{Prefix: "j"}),
+			wantIndex:    0,
+			wantNames:    []string{"canary", "env", "http[2]"},
+			wantWarnings: []string{},
+		},
+		{
+			name: "updating a rule moves it up when the old position is no longer reachable",
+			vs: testVirtualService(0,
+				headerRoute("j", "end-user", prefixMatch("j"), "v1"),
+				headerRoute("canary", "end-user", exactMatch("jason"), "v1"),
+			),
+			rule:         rule("canary", "end-user", StringMatch{Prefix: "j"}),
+			wantIndex:    0,
+			wantNames:    []string{"canary", "j", "http[2]"},
+			wantWarnings: []string{"http route j is shadowed by rule canary"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			index, warnings := mergeRoutingRule(c.vs, c.rule)
+			if index != c.wantIndex {
+				t.Errorf("index = %d, want %d", index, c.wantIndex)
+			}
+			if names := routeNames(c.vs); !reflect.DeepEqual(names, c.wantNames) {
+				t.Errorf("routes = %v, want %v", names, c.wantNames)
+			}
+			if !reflect.DeepEqual(warnings, c.wantWarnings) {
+				t.Errorf("warnings = %v, want %v", warnings, c.wantWarnings)
+			}
+			merged := c.vs.Spec.Http[index]
+			if merged.GetName() != c.rule.Name || merged.GetRoute()[0].GetDestination().GetSubset() != c.rule.Subset {
+				t.Errorf("route at %d = %v, want rule %s to subset %s", index, merged, c.rule.Name, c.rule.Subset)
+			}
+		})
+	}
+}
+
+func TestMergeRoutingRuleInheritsFallback(t *testing.T) {
+	vs := testVirtualService(9080)
+	vs.Spec.Http[0].Timeout = durationpb.New(5e9)
+	vs.Spec.Http[0].Retries = &networking.HTTPRetry{Attempts: 3}
+	rule := &RoutingRule{
+		Name:       "canary",
+		Namespace:  "default",
+		Service:    "reviews",
+		RouteMatch: RouteMatch{QueryParams: map[string]StringMatch{"canary": {Exact: "true"}}},
+		Subset:     "v2",
+	}
+	index, _ := mergeRoutingRule(vs, rule)
+	route := vs.Spec.Http[index]
+	if route.GetTimeout().AsDuration() != 5e9 || route.GetRetries().GetAttempts() != 3 {
+		t.Errorf("timeout and retries are not inherited: %v", route)
+	}
+	if port := route.GetRoute()[0].GetDestination().GetPort().GetNumber(); port != 9080 {
+		t.Errorf("destination port = %d, want 9080", port)
+	}
+}
+
+func TestMatchCovers(t *testing.T) {
+	cases := []struct {
+		name   string
+		broad  *networking.HTTPMatchRequest
+		narrow *networking.HTTPMatchRequest
+		want   bool
+	}{
+		{
+			name:   "empty match covers everything",
+			broad:  &networking.HTTPMatchRequest{},
+			narrow: &networking.HTTPMatchRequest{Uri: exactMatch("/api")},
+			want:   true,
+		},
+		{
+			name:   "uri prefix covers a longer exact uri",
+			broad:  &networking.HTTPMatchRequest{Uri: prefixMatch("/api")},
+			narrow: &networking.HTTPMatchRequest{Uri: exactMatch("/api/v1")},
+			want:   true,
+		},
+		{
+			name:   "uri condition is not covered by a match without uri",
+			broad:  &networking.HTTPMatchRequest{Uri: prefixMatch("/api")},
+			narrow: &networking.HTTPMatchRequest{},
+			want:   false,
+		},
+		{
+			name:   "different uri case sensitivity",
+			broad:  &networking.HTTPMatchRequest{Uri: prefixMatch("/api"), IgnoreUriCase: true},
+			narrow: &networking.HTTPMatchRequest{Uri: prefixMatch("/api")},
+			want:   false,
+		},
+		{
+			name:   "port must be the same",
+			broad:  &networking.HTTPMatchRequest{Port: 80},
+			narrow: &networking.HTTPMatchRequest{},
+			want:   false,
+		},
+		{
+			name: "narrow may have more headers",
+			broad: &networking.HTTPMatchRequest{
+				Headers: map[string]*networking.StringMatch{"end-user": exactMatch("jason")},
+			},
+			narrow: &networking.HTTPMatchRequest{
+				Headers: map[string]*networking.StringMatch{"end-user": exactMatch("jason"), "x-env": exactMatch("dev")},
+			},
+			want: true,
+		},
+		{
+			name: "header missing in narrow",
+			broad: &networking.HTTPMatchRequest{
+				Headers: map[string]*networking.StringMatch{"end-user": exactMatch("jason")},
+			},
+			narrow: &networking.HTTPMatchRequest{
+				QueryParams: map[string]*networking.StringMatch{"end-user": exactMatch("jason")},
+			},
+			want: false,
+		},
+		{
+			name:   "source labels are a subset",
+			broad:  &networking.HTTPMatchRequest{SourceLabels: map[string]string{"app": "productpage"}},
+			narrow: &networking.HTTPMatchRequest{SourceLabels: map[string]string{"app": "productpage", "version": "v1"}},
+			want:   true,
+		},
+		{
+			name:   "source label value differs",
+			broad:  &networking.HTTPMatchRequest{SourceLabels: map[string]string{"app": "productpage"}},
+			narrow: &networking.HTTPMatchRequest{SourceLabels: map[string]string{"app": "ratings"}},
+			want:   false,
+		},
+		{
+			name:   "gateways of narrow are included in broad",
+			broad:  &networking.HTTPMatchRequest{Gateways: []string{"mesh", "ingress"}},
+			narrow: &networking.HTTPMatchRequest{Gateways: []string{"ingress"}},
+			want:   true,
+		},
+		{
+			name:   "narrow without gateways applies to all of them",
+			broad:  &networking.HTTPMatchRequest{Gateways: []string{"ingress"}},
+			narrow: &networking.HTTPMatchRequest{},
+			want:   false,
+		},
+		{
+			name: "without headers must be equal",
+			broad: &networking.HTTPMatchRequest{
+				WithoutHeaders: map[string]*networking.StringMatch{"x-env": exactMatch("dev")},
+			},
+			narrow: &networking.HTTPMatchRequest{
+				WithoutHeaders: map[string]*networking.StringMatch{"x-env": prefixMatch("d")},
+			},
+			want: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := matchCovers(c.broad, c.narrow); got != c.want {
+				t.Errorf("matchCovers() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestStringMatchCovers(t *testing.T) {
+	cases := []struct {
+		name   string
+		broad  *networking.StringMatch
+		narrow *networking.StringMatch
+		want   bool
+	}{
+		{name: "no condition", broad: nil, narrow: nil, want: true},
+		{name: "no condition covers any", broad: nil, narrow: exactMatch("a"), want: true},
+		{name: "condition does not cover none", broad: exactMatch("a"), narrow: nil, want: false},
+		{name: "same exact", broad: exactMatch("a"), narrow: exactMatch("a"), want: true},
+		{name: "different exact", broad: exactMatch("a"), narrow: exactMatch("ab"), want: false},
+		{name: "exact does not cover prefix", broad: exactMatch("a"), narrow: prefixMatch("a"), want: false},
+		{name: "prefix covers exact", broad: prefixMatch("/a"), narrow: exactMatch("/ab"), want: true},
+		{name: "prefix covers longer prefix", broad: prefixMatch("/a"), narrow: prefixMatch("/ab"), want: true},
+		{name: "prefix does not cover shorter prefix", broad: prefixMatch("/ab"), narrow: prefixMatch("/a"), want: false},
+		{name: "prefix does not cover regex", broad: prefixMatch("/a"), narrow: regexMatch("/a.*"), want: false},
+		{name: "same regex", broad: regexMatch("/a.*"), narrow: regexMatch("/a.*"), want: true},
+		{name: "different regex", broad: regexMatch("/a.*"), narrow: regexMatch("/ab.*"), want: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := stringMatchCovers(c.broad, c.narrow); got != c.want {
+				t.Errorf("stringMatchCovers() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCookieMatch(t *testing.T) {
+	cases := []struct {
+		name   string
+		cookie string
+		match  StringMatch
+		header string
+		want   bool
+	}{
+		{name: "only cookie", cookie: "user", match: StringMatch{Exact: "a.b"}, header: "user=a.b", want: true},
+		{name: "last cookie", cookie: "user", match: StringMatch{Exact: "a.b"}, header: "x=1; user=a.b", want: true},
+		{name: "first cookie", cookie: "user", match: StringMatch{Exact: "a.b"}, header: "user=a.b;x=1", want: true},
+		{name: "exact value is quoted", cookie: "user", match: StringMatch{Exact: "a.b"}, header: "user=aXb", want: false},
+		{name: "exact value is complete", cookie: "user", match: StringMatch{Exact: "a.b"}, header: "user=a.bc", want: false},
+		{name: "name is complete", cookie: "user", match: StringMatch{Exact: "a.b"}, header: "otheruser=a.b", want: false},
+		{name: "name is quoted", cookie: "a.b", match: StringMatch{Exact: "1"}, header: "aXb=1", want: false},
+		{name: "prefix", cookie: "user", match: StringMatch{Prefix: "ja"}, header: "user=jason; x=1", want: true},
+		{name: "prefix stays in the cookie", cookie: "user", match: StringMatch{Prefix: "ja"}, header: "user=x; y=ja", want: false},
+		{name: "regex", cookie: "user", match: StringMatch{Regex: "v[0-9]|canary"}, header: "x=1; user=canary", want: true},
+		{name: "regex alternatives are grouped", cookie: "user", match: StringMatch{Regex: "v[0-9]|canary"}, header: "x=canary", want: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			regex := cookieMatch(c.cookie, c.match).GetRegex()
+			// envoy的正则需要匹配整个请求头
+			re, err := regexp.Compile("^(?:" + regex + ")$")
+			if err != nil {
+				t.Fatalf("invalid regex %q: %s", regex, err)
+			}
+			if got := re.MatchString(c.header); got != c.want {
+				t.Errorf("%q match %q = %v, want %v", regex, c.header, got, c.want)
+			}
+		})
+	}
+}
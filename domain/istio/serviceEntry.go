@@ -1,11 +1,8 @@
 package istio
 
 import (
-	"context"
-
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	informer "istio.io/client-go/pkg/listers/networking/v1alpha3"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -17,46 +14,59 @@ func NewServiceEntry(cli *IstioClient) *ServiceEntry {
 	return &ServiceEntry{cli}
 }
 
-func (s *ServiceEntry) List(namespace string) []v1alpha3.ServiceEntry {
-	serviceEntryList := make([]v1alpha3.ServiceEntry, 0)
-	list, err := s.GetServiceEntryLister().ServiceEntries(namespace).List(labels.Everything())
-	if err != nil || len(list) == 0 {
-		list, err := s.Clientset.NetworkingV1alpha3().ServiceEntries(namespace).List(context.Background(), metav1.ListOptions{})
-		if err == nil && list != nil {
-			serviceEntryList = list.Items
-		}
-		return serviceEntryList
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (s *ServiceEntry) List(namespace string, label map[string]string) ([]*v1alpha3.ServiceEntry, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
 	}
-	for _, serviceEntry := range list {
-		serviceEntryList = append(serviceEntryList, *serviceEntry)
+
+	serviceEntryList, err := s.GetServiceEntryLister().ServiceEntries(namespace).List(selector)
+	if err != nil || len(serviceEntryList) == 0 {
+		list := &v1alpha3.ServiceEntryList{}
+		if err := s.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameServiceEntry)).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		serviceEntryList = make([]*v1alpha3.ServiceEntry, 0, len(list.Items))
+		for i := range list.Items {
+			serviceEntryList = append(serviceEntryList, &list.Items[i])
+		}
 	}
-	return serviceEntryList
+	return serviceEntryList, nil
 }
 
 func (s *ServiceEntry) Get(namespace, serviceEntryName string) (*v1alpha3.ServiceEntry, error) {
 	serviceEntry, err := s.GetServiceEntryLister().ServiceEntries(namespace).Get(serviceEntryName)
-	if serviceEntry == nil {
-		return s.Clientset.NetworkingV1alpha3().ServiceEntries(namespace).
-			Get(context.Background(), serviceEntryName, metav1.GetOptions{})
+	if err != nil || serviceEntry == nil {
+		serviceEntry = &v1alpha3.ServiceEntry{}
+		if err := s.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameServiceEntry)).Get(namespace, serviceEntryName, serviceEntry); err != nil {
+			return nil, err
+		}
 	}
-	return serviceEntry, err
+	return serviceEntry, nil
 }
 
-func (s *ServiceEntry) Create(serviceEntry *v1alpha3.ServiceEntry) error {
-	_, err := s.Clientset.NetworkingV1alpha3().ServiceEntries(serviceEntry.Namespace).
-		Create(context.Background(), serviceEntry, metav1.CreateOptions{})
-	return err
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (s *ServiceEntry) Create(serviceEntry *v1alpha3.ServiceEntry, dryRun bool) (*v1alpha3.ServiceEntry, error) {
+	created := &v1alpha3.ServiceEntry{}
+	if err := s.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameServiceEntry)).Create(serviceEntry, created, dryRun); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
-func (s *ServiceEntry) Delete(namespace, serviceEntryName string) error {
-	return s.Clientset.NetworkingV1alpha3().ServiceEntries(namespace).
-		Delete(context.Background(), serviceEntryName, metav1.DeleteOptions{})
+// Update serviceEntry 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (s *ServiceEntry) Update(serviceEntry *v1alpha3.ServiceEntry, dryRun bool) (*v1alpha3.ServiceEntry, error) {
+	updated := &v1alpha3.ServiceEntry{}
+	if err := s.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameServiceEntry)).Update(serviceEntry, updated, dryRun); err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
-func (s *ServiceEntry) Update(serviceEntry *v1alpha3.ServiceEntry) error {
-	_, err := s.Clientset.NetworkingV1alpha3().ServiceEntries(serviceEntry.Namespace).
-		Update(context.Background(), serviceEntry, metav1.UpdateOptions{})
-	return err
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (s *ServiceEntry) Delete(namespace, serviceEntryName, resourceVersion string, dryRun bool) error {
+	return s.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameServiceEntry)).Delete(namespace, serviceEntryName, resourceVersion, dryRun)
 }
 
 func (s *ServiceEntry) GetServiceEntryLister() informer.ServiceEntryLister {
@@ -1,7 +1,10 @@
 package istio
 
 import (
+	extensionsv1alpha1 "istio.io/client-go/pkg/apis/extensions/v1alpha1"
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	securityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	telemetryv1alpha1 "istio.io/client-go/pkg/apis/telemetry/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -11,8 +14,35 @@ const (
 	ResourceNameVirtualService  = "virtualservices"
 	ResourceNameServiceEntry    = "serviceentries"
 	ResourceNameEnvoyFilter     = "envoyfilters"
+	ResourceNameSidecar         = "sidecars"
+	ResourceNameWorkloadEntry   = "workloadentries"
+	ResourceNameWorkloadGroup   = "workloadgroups"
+
+	ResourceNamePeerAuthentication    = "peerauthentications"
+	ResourceNameRequestAuthentication = "requestauthentications"
+	ResourceNameAuthorizationPolicy   = "authorizationpolicies"
+	ResourceNameTelemetry             = "telemetries"
+	ResourceNameWasmPlugin            = "wasmplugins"
+)
+
+var (
+	GVKGateway         = v1alpha3.SchemeGroupVersion.WithKind("Gateway")
+	GVKDestinationRule = v1alpha3.SchemeGroupVersion.WithKind("DestinationRule")
+	GVKVirtualService  = v1alpha3.SchemeGroupVersion.WithKind("VirtualService")
+	GVKServiceEntry    = v1alpha3.SchemeGroupVersion.WithKind("ServiceEntry")
+	GVKEnvoyFilter     = v1alpha3.SchemeGroupVersion.WithKind("EnvoyFilter")
+	GVKSidecar         = v1alpha3.SchemeGroupVersion.WithKind("Sidecar")
+	GVKWorkloadEntry   = v1alpha3.SchemeGroupVersion.WithKind("WorkloadEntry")
+	GVKWorkloadGroup   = v1alpha3.SchemeGroupVersion.WithKind("WorkloadGroup")
+
+	GVKPeerAuthentication    = securityv1beta1.SchemeGroupVersion.WithKind("PeerAuthentication")
+	GVKRequestAuthentication = securityv1beta1.SchemeGroupVersion.WithKind("RequestAuthentication")
+	GVKAuthorizationPolicy   = securityv1beta1.SchemeGroupVersion.WithKind("AuthorizationPolicy")
+	GVKTelemetry             = telemetryv1alpha1.SchemeGroupVersion.WithKind("Telemetry")
+	GVKWasmPlugin            = extensionsv1alpha1.SchemeGroupVersion.WithKind("WasmPlugin")
 )
 
+// KindToIstioResourceSlice wrapper使用的资源类型及版本, 集群提供该版本时才会启动informer
 var KindToIstioResourceSlice = []schema.GroupVersionResource{
 	schema.GroupVersionResource{
 		Group:    v1alpha3.GroupName,
@@ -39,4 +69,61 @@ var KindToIstioResourceSlice = []schema.GroupVersionResource{
 		Version:  v1alpha3.SchemeGroupVersion.Version,
 		Resource: ResourceNameEnvoyFilter,
 	},
+	schema.GroupVersionResource{
+		Group:    v1alpha3.GroupName,
+		Version:  v1alpha3.SchemeGroupVersion.Version,
+		Resource: ResourceNameSidecar,
+	},
+	schema.GroupVersionResource{
+		Group:    v1alpha3.GroupName,
+		Version:  v1alpha3.SchemeGroupVersion.Version,
+		Resource: ResourceNameWorkloadEntry,
+	},
+	schema.GroupVersionResource{
+		Group:    v1alpha3.GroupName,
+		Version:  v1alpha3.SchemeGroupVersion.Version,
+		Resource: ResourceNameWorkloadGroup,
+	},
+	schema.GroupVersionResource{
+		Group:    securityv1beta1.GroupName,
+		Version:  securityv1beta1.SchemeGroupVersion.Version,
+		Resource: ResourceNamePeerAuthentication,
+	},
+	schema.GroupVersionResource{
+		Group:    securityv1beta1.GroupName,
+		Version:  securityv1beta1.SchemeGroupVersion.Version,
+		Resource: ResourceNameRequestAuthentication,
+	},
+	schema.GroupVersionResource{
+		Group:    securityv1beta1.GroupName,
+		Version:  securityv1beta1.SchemeGroupVersion.Version,
+		Resource: ResourceNameAuthorizationPolicy,
+	},
+	schema.GroupVersionResource{
+		Group:    telemetryv1alpha1.GroupName,
+		Version:  telemetryv1alpha1.SchemeGroupVersion.Version,
+		Resource: ResourceNameTelemetry,
+	},
+	schema.GroupVersionResource{
+		Group:    extensionsv1alpha1.GroupName,
+		Version:  extensionsv1alpha1.SchemeGroupVersion.Version,
+		Resource: ResourceNameWasmPlugin,
+	},
+}
+
+// resourceKinds KindToIstioResourceSlice 中的资源对应的类型
+var resourceKinds = map[schema.GroupVersionResource]schema.GroupVersionKind{
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameGateway):                      GVKGateway,
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameDestinationRule):              GVKDestinationRule,
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService):               GVKVirtualService,
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameServiceEntry):                 GVKServiceEntry,
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameEnvoyFilter):                  GVKEnvoyFilter,
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameSidecar):                      GVKSidecar,
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameWorkloadEntry):                GVKWorkloadEntry,
+	v1alpha3.SchemeGroupVersion.WithResource(ResourceNameWorkloadGroup):                GVKWorkloadGroup,
+	securityv1beta1.SchemeGroupVersion.WithResource(ResourceNamePeerAuthentication):    GVKPeerAuthentication,
+	securityv1beta1.SchemeGroupVersion.WithResource(ResourceNameRequestAuthentication): GVKRequestAuthentication,
+	securityv1beta1.SchemeGroupVersion.WithResource(ResourceNameAuthorizationPolicy):   GVKAuthorizationPolicy,
+	telemetryv1alpha1.SchemeGroupVersion.WithResource(ResourceNameTelemetry):           GVKTelemetry,
+	extensionsv1alpha1.SchemeGroupVersion.WithResource(ResourceNameWasmPlugin):         GVKWasmPlugin,
 }
@@ -0,0 +1,62 @@
+package istio
+
+import (
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+
+	extensionsv1alpha1 "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	securityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	telemetryv1alpha1 "istio.io/client-go/pkg/apis/telemetry/v1alpha1"
+	"k8s.io/client-go/discovery"
+)
+
+// supportedVersions 每个组支持的版本, 按优先级排列; 同一资源各版本的结构相同, 都可以解析为wrapper使用的类型
+var supportedVersions = map[string][]string{
+	v1alpha3.GroupName:           {"v1", "v1beta1", "v1alpha3"},
+	securityv1beta1.GroupName:    {"v1", "v1beta1"},
+	telemetryv1alpha1.GroupName:  {"v1", "v1alpha1"},
+	extensionsv1alpha1.GroupName: {"v1alpha1"},
+}
+
+// ResourceVersion 资源协商后的版本
+type ResourceVersion struct {
+	Group          string   `json:"group"`
+	Resource       string   `json:"resource"`
+	Version        string   `json:"version"`
+	ServedVersions []string `json:"servedVersions"`
+}
+
+// getAPIVersions 查询集群中istio各个组提供的资源及版本
+func getAPIVersions(cid string, cli discovery.DiscoveryInterface) (kube.APIVersions, error) {
+	groups := make([]string, 0, len(supportedVersions))
+	for group := range supportedVersions {
+		groups = append(groups, group)
+	}
+	return kube.DiscoverAPIVersions(cid, cli, groups)
+}
+
+// defaultAPIVersions discovery失败时假设集群提供wrapper使用的版本
+func defaultAPIVersions() kube.APIVersions {
+	versions := make(kube.APIVersions)
+	for _, gvr := range KindToIstioResourceSlice {
+		versions[gvr.GroupResource()] = []string{gvr.Version}
+	}
+	return versions
+}
+
+// GetResourceVersions 返回每种资源协商后的版本, 集群不提供的资源版本为空
+func (i *IstioClient) GetResourceVersions() []ResourceVersion {
+	resourceVersions := make([]ResourceVersion, 0, len(KindToIstioResourceSlice))
+	for _, gvr := range KindToIstioResourceSlice {
+		served := i.versions[gvr.GroupResource()]
+		if served == nil {
+			served = make([]string, 0)
+		}
+		resourceVersion := ResourceVersion{Group: gvr.Group, Resource: gvr.Resource, ServedVersions: served}
+		if negotiated, err := i.versions.Negotiate(gvr.GroupResource(), supportedVersions[gvr.Group]); err == nil {
+			resourceVersion.Version = negotiated.Version
+		}
+		resourceVersions = append(resourceVersions, resourceVersion)
+	}
+	return resourceVersions
+}
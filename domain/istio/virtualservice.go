@@ -1,13 +1,11 @@
 package istio
 
 import (
-	"context"
-
 	"istio.io/client-go/pkg/apis/networking/v1alpha3"
 	informer "istio.io/client-go/pkg/listers/networking/v1alpha3"
 	"k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type VirtualService struct {
@@ -18,62 +16,82 @@ func NewVirtualService(cli *IstioClient) *VirtualService {
 	return &VirtualService{cli}
 }
 
-func (v *VirtualService) Get(namespace, virtualServiceName string) (*v1alpha3.VirtualService, error) {
-	virtualService, err := v.GetVirtualServiceLister().VirtualServices(namespace).Get(virtualServiceName)
-	if virtualService == nil {
-		return v.Clientset.NetworkingV1alpha3().VirtualServices(namespace).
-			Get(context.Background(), virtualServiceName, metav1.GetOptions{})
+// List namespace 为空时查询所有命名空间, label 为空时不按标签过滤
+func (v *VirtualService) List(namespace string, label map[string]string) ([]*v1alpha3.VirtualService, error) {
+	selector := labels.Everything()
+	if len(label) != 0 {
+		selector = labels.SelectorFromSet(label)
+	}
+
+	virtualServiceList, err := v.GetVirtualServiceLister().VirtualServices(namespace).List(selector)
+	if err != nil || len(virtualServiceList) == 0 {
+		list := &v1alpha3.VirtualServiceList{}
+		if err := v.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService)).List(namespace, selector, list); err != nil {
+			return nil, err
+		}
+		virtualServiceList = make([]*v1alpha3.VirtualService, 0, len(list.Items))
+		for i := range list.Items {
+			virtualServiceList = append(virtualServiceList, &list.Items[i])
+		}
 	}
-	return virtualService, err
+	return virtualServiceList, nil
 }
 
-func (v *VirtualService) List(namespace string, opts metav1.ListOptions) []v1alpha3.VirtualService {
-	virtualServiceList := make([]v1alpha3.VirtualService, 0)
-	list, err := v.GetVirtualServiceLister().VirtualServices(namespace).List(labels.Everything())
-	if err != nil || len(list) == 0 {
-		list, err := v.Clientset.NetworkingV1alpha3().VirtualServices(namespace).List(context.Background(), opts)
-		if err == nil && list != nil {
-			virtualServiceList = list.Items
+func (v *VirtualService) Get(namespace, virtualServiceName string) (*v1alpha3.VirtualService, error) {
+	virtualService, err := v.GetVirtualServiceLister().VirtualServices(namespace).Get(virtualServiceName)
+	if err != nil || virtualService == nil {
+		virtualService = &v1alpha3.VirtualService{}
+		if err := v.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService)).Get(namespace, virtualServiceName, virtualService); err != nil {
+			return nil, err
 		}
-		return virtualServiceList
 	}
-	for _, virtualService := range list {
-		virtualServiceList = append(virtualServiceList, *virtualService)
+	return virtualService, nil
+}
+
+// getLatest 读取后修改再写入时直接从apiserver读取, informer中的对象可能落后导致一直冲突
+func (v *VirtualService) getLatest(namespace, virtualServiceName string) (*v1alpha3.VirtualService, error) {
+	virtualService := &v1alpha3.VirtualService{}
+	if err := v.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService)).Get(namespace, virtualServiceName, virtualService); err != nil {
+		return nil, err
 	}
-	return virtualServiceList
+	return virtualService, nil
 }
 
-func (v *VirtualService) Create(vs *v1alpha3.VirtualService) error {
-	_, k8sErr := v.Clientset.NetworkingV1alpha3().VirtualServices(vs.Namespace).Create(context.Background(), vs, metav1.CreateOptions{})
-	if k8sErr != nil {
-		return k8sErr
+// Create dryRun为true时只做服务端校验, 不会真正创建
+func (v *VirtualService) Create(virtualService *v1alpha3.VirtualService, dryRun bool) (*v1alpha3.VirtualService, error) {
+	created := &v1alpha3.VirtualService{}
+	if err := v.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService)).Create(virtualService, created, dryRun); err != nil {
+		return nil, err
 	}
-	return nil
+	return created, nil
 }
 
-func (v *VirtualService) Update(vs *v1alpha3.VirtualService) error {
-	_, k8sErr := v.Clientset.NetworkingV1alpha3().VirtualServices(vs.Namespace).Update(context.Background(), vs, metav1.UpdateOptions{})
-	if k8sErr != nil {
-		return k8sErr
+// Update virtualService 需要带上读取时的 resourceVersion, 期间被其他人修改过时返回 Conflict 错误
+func (v *VirtualService) Update(virtualService *v1alpha3.VirtualService, dryRun bool) (*v1alpha3.VirtualService, error) {
+	updated := &v1alpha3.VirtualService{}
+	if err := v.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService)).Update(virtualService, updated, dryRun); err != nil {
+		return nil, err
 	}
-	return nil
+	return updated, nil
 }
 
-func (v *VirtualService) Delete(namespace, name string) error {
-	k8sErr := v.Clientset.NetworkingV1alpha3().VirtualServices(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
-	if k8sErr != nil {
-		if errors.IsNotFound(k8sErr) {
-			return nil
-		}
-		return k8sErr
+// Patch 只修改 patch 中的字段, patch 中带上 resourceVersion 时, 期间被其他人修改过返回 Conflict 错误
+func (v *VirtualService) Patch(namespace, virtualServiceName string, patchType types.PatchType, patch []byte, dryRun bool) (*v1alpha3.VirtualService, error) {
+	patched := &v1alpha3.VirtualService{}
+	if err := v.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService)).Patch(namespace, virtualServiceName, patchType, patch, patched, dryRun); err != nil {
+		return nil, err
 	}
+	return patched, nil
+}
 
-	return nil
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (v *VirtualService) Delete(namespace, virtualServiceName, resourceVersion string, dryRun bool) error {
+	return v.resource(v1alpha3.SchemeGroupVersion.WithResource(ResourceNameVirtualService)).Delete(namespace, virtualServiceName, resourceVersion, dryRun)
 }
 
 func (v *VirtualService) DoCreateOrUpdate(virtualService *v1alpha3.VirtualService) error {
-	exist := true
 	oldVirtualService, err := v.Get(virtualService.Namespace, virtualService.Name)
+	exist := true
 	if err != nil {
 		if errors.IsNotFound(err) {
 			exist = false
@@ -81,12 +99,16 @@ func (v *VirtualService) DoCreateOrUpdate(virtualService *v1alpha3.VirtualServic
 			return err
 		}
 	}
-
 	if !exist {
-		return v.Create(virtualService)
+		// create
+		_, err = v.Create(virtualService, false)
+		return err
 	} else {
-		oldVirtualService.Spec = virtualService.Spec
-		return v.Update(oldVirtualService)
+		// update, 从informer中获取的对象不能直接修改
+		newVirtualService := oldVirtualService.DeepCopy()
+		virtualService.Spec.DeepCopyInto(&newVirtualService.Spec)
+		_, err = v.Update(newVirtualService, false)
+		return err
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/kiali/kiali/models"
+	pmod "github.com/prometheus/common/model"
 )
 
 const (
@@ -92,9 +93,36 @@ func (c *Client) GetServicesMetrics(namespace, service, avg, direction, duration
 		domainLog.Errorf("build request err, err: %s", err)
 		return metrics
 	}
-	err = c.DoRequest(request, &metrics)
+	result := make(map[string][]metric)
+	err = c.DoRequest(request, &result)
 	if err != nil {
 		domainLog.Errorf("do request err, err: %s", err)
+		return metrics
+	}
+	for name, series := range result {
+		metrics[name] = make([]models.Metric, 0, len(series))
+		for _, m := range series {
+			metrics[name] = append(metrics[name], m.toModel())
+		}
 	}
 	return metrics
 }
+
+// metric kiali返回的数据点格式为 [timestamp, "value"], models.Datapoint 只实现了序列化, 借助 SamplePair 解析
+type metric struct {
+	Labels     map[string]string `json:"labels"`
+	Datapoints []pmod.SamplePair `json:"datapoints"`
+	Stat       string            `json:"stat,omitempty"`
+	Name       string            `json:"name"`
+}
+
+func (m metric) toModel() models.Metric {
+	datapoints := make([]models.Datapoint, 0, len(m.Datapoints))
+	for _, datapoint := range m.Datapoints {
+		datapoints = append(datapoints, models.Datapoint{
+			Timestamp: int64(datapoint.Timestamp),
+			Value:     float64(datapoint.Value),
+		})
+	}
+	return models.Metric{Labels: m.Labels, Datapoints: datapoints, Stat: m.Stat, Name: m.Name}
+}
@@ -0,0 +1,51 @@
+package kube
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// DecodeResource 解析yaml或json格式的资源, kind必须与gvk一致, apiVersion可以是同一个组的任意版本
+func DecodeResource(data []byte, gvk schema.GroupVersionKind, obj runtime.Object) error {
+	if err := yaml.Unmarshal(data, obj); err != nil {
+		return err
+	}
+	objGVK := obj.GetObjectKind().GroupVersionKind()
+	if objGVK.Kind != "" && objGVK.Kind != gvk.Kind {
+		return fmt.Errorf("kind %s does not match %s", objGVK.Kind, gvk.Kind)
+	}
+	if objGVK.Group != "" && objGVK.Group != gvk.Group {
+		return fmt.Errorf("apiVersion %s does not match group %s", objGVK.GroupVersion(), gvk.Group)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// FormatResource 补全apiVersion和kind并去掉managedFields, json格式返回对象, 由gin序列化; yaml格式返回字符串
+func FormatResource(obj runtime.Object, gvk schema.GroupVersionKind, format string) (interface{}, error) {
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	switch format {
+	case "", FormatJSON:
+		return obj, nil
+	case FormatYAML:
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
@@ -0,0 +1,153 @@
+package kube
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	defaultForwarderIdleTimeout   = 5 * time.Minute
+	defaultForwarderCheckInterval = 30 * time.Second
+)
+
+// DefaultPortForwarderPool 全局的端口转发池, 所有集群共用
+var DefaultPortForwarderPool = NewPortForwarderPool(defaultForwarderIdleTimeout, defaultForwarderCheckInterval)
+
+// PortForwarderKey 端口转发的唯一标识
+type PortForwarderKey struct {
+	ClusterID string
+	Namespace string
+	Pod       string
+	Port      int
+}
+
+type pooledForwarder struct {
+	PortForwarder
+	lastUsed time.Time
+}
+
+// PortForwarderPool 复用到pod的端口转发, 避免每次请求都重新建立SPDY连接, 空闲超时后自动关闭
+type PortForwarderPool struct {
+	mu          sync.Mutex
+	forwarders  map[PortForwarderKey]*pooledForwarder
+	idleTimeout time.Duration
+	stopCh      chan struct{}
+}
+
+func NewPortForwarderPool(idleTimeout, checkInterval time.Duration) *PortForwarderPool {
+	p := &PortForwarderPool{
+		forwarders:  make(map[PortForwarderKey]*pooledForwarder),
+		idleTimeout: idleTimeout,
+		stopCh:      make(chan struct{}),
+	}
+	go p.run(checkInterval)
+	return p
+}
+
+// Get 获取可用的端口转发, 不存在或已失效时重新建立
+func (p *PortForwarderPool) Get(key PortForwarderKey, restConfig *rest.Config) (PortForwarder, error) {
+	p.mu.Lock()
+	pf, ok := p.forwarders[key]
+	if ok {
+		pf.lastUsed = time.Now()
+	}
+	p.mu.Unlock()
+
+	if ok {
+		if healthy(pf) {
+			return pf, nil
+		}
+		p.Evict(key, pf)
+	}
+
+	// 建立连接比较耗时, 不在锁内进行
+	fw, err := NewPortForwarder(restConfig, key.Pod, key.Namespace, "127.0.0.1", 0, key.Port)
+	if err != nil {
+		return nil, err
+	}
+	if err = fw.Start(); err != nil {
+		fw.Close()
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if exist, ok := p.forwarders[key]; ok {
+		// 并发请求已经建立了转发, 使用已有的
+		fw.Close()
+		exist.lastUsed = time.Now()
+		return exist, nil
+	}
+	pf = &pooledForwarder{PortForwarder: fw, lastUsed: time.Now()}
+	p.forwarders[key] = pf
+	return pf, nil
+}
+
+// Evict 关闭并移除 Get 返回的端口转发, 请求失败时调用, 下次请求会重新建立;
+// 已经被其他请求替换的端口转发不会被移除, 避免关闭新建立的转发
+func (p *PortForwarderPool) Evict(key PortForwarderKey, fw PortForwarder) {
+	p.mu.Lock()
+	pf, ok := p.forwarders[key]
+	ok = ok && PortForwarder(pf) == fw
+	if ok {
+		delete(p.forwarders, key)
+	}
+	p.mu.Unlock()
+	if ok {
+		pf.Close()
+	}
+}
+
+// Close 关闭所有端口转发
+func (p *PortForwarderPool) Close() {
+	close(p.stopCh)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, pf := range p.forwarders {
+		pf.Close()
+		delete(p.forwarders, key)
+	}
+}
+
+func (p *PortForwarderPool) run(checkInterval time.Duration) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.cleanup()
+		}
+	}
+}
+
+// 关闭空闲超时和已断开的端口转发
+func (p *PortForwarderPool) cleanup() {
+	p.mu.Lock()
+	expired := make([]*pooledForwarder, 0)
+	for key, pf := range p.forwarders {
+		if time.Since(pf.lastUsed) > p.idleTimeout || !healthy(pf) {
+			expired = append(expired, pf)
+			delete(p.forwarders, key)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, pf := range expired {
+		domainLog.Debugf("close idle port forwarder %s", pf.Address())
+		pf.Close()
+	}
+}
+
+// healthy 转发的连接断开后会自动重连, 重连失败时停止; 仍在运行但请求失败的转发由调用方 Evict
+func healthy(fw PortForwarder) bool {
+	select {
+	case <-fw.Done():
+		return false
+	default:
+		return true
+	}
+}
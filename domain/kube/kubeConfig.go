@@ -1,6 +1,10 @@
 package kube
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
 	"github.com/shuxnhs/istio-dashboard/model"
 
 	"k8s.io/client-go/kubernetes"
@@ -50,3 +54,16 @@ func NewKubernetesClientSet(kubeConfig *model.KubeConfig) *kubernetes.Clientset
 func NewKubernetesRestClient(kubeConfig *model.KubeConfig) *rest.RESTClient {
 	return NewRestClient(GetConfigStoreKubeConfig(kubeConfig))
 }
+
+// ConfigFingerprint 集群连接相关配置的摘要, 缓存的客户端用来判断配置是否被修改
+func ConfigFingerprint(kubeConfig *model.KubeConfig) string {
+	sum := sha256.New()
+	for _, field := range []string{
+		kubeConfig.K8sHost, strconv.Itoa(kubeConfig.K8sAuthType), kubeConfig.K8sAuthBasic, kubeConfig.K8sAuthToken,
+		kubeConfig.K8sClusterAuthData, kubeConfig.K8sClientCertificateData, kubeConfig.K8sClientKeyData,
+	} {
+		sum.Write([]byte(field))
+		sum.Write([]byte{0})
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
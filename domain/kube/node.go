@@ -0,0 +1,21 @@
+package kube
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type Node struct {
+	cli *kubernetes.Clientset
+}
+
+func NewNode(cli *kubernetes.Clientset) *Node {
+	return &Node{cli: cli}
+}
+
+func (n *Node) ListNodes() (*v1.NodeList, error) {
+	return n.cli.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+}
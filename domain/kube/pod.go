@@ -0,0 +1,25 @@
+package kube
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type Pod struct {
+	cli *kubernetes.Clientset
+}
+
+func NewPod(cli *kubernetes.Clientset) *Pod {
+	return &Pod{cli: cli}
+}
+
+func (p *Pod) GetPod(namespace, podName string) (*v1.Pod, error) {
+	return p.cli.CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+}
+
+func (p *Pod) ListPods(namespace string, opts metav1.ListOptions) (*v1.PodList, error) {
+	return p.cli.CoreV1().Pods(namespace).List(context.Background(), opts)
+}
@@ -31,12 +31,16 @@ type PortForwarder interface {
 
 	// WaitForStop blocks until connection closed (e.g. control-C interrupt)
 	WaitForStop()
+
+	// Done is closed once the forwarder stopped, either closed or failed to re-establish the connection.
+	Done() <-chan struct{}
 }
 
 var _ PortForwarder = &forwarder{}
 
 type forwarder struct {
 	stopCh       chan struct{}
+	doneCh       chan struct{}
 	restConfig   *rest.Config
 	podName      string
 	ns           string
@@ -50,6 +54,7 @@ func (f *forwarder) Start() error {
 	errCh := make(chan error, 1)
 	readyCh := make(chan struct{}, 1)
 	go func() {
+		defer close(f.doneCh)
 		for {
 			select {
 			case <-f.stopCh:
@@ -96,6 +101,10 @@ func (f *forwarder) WaitForStop() {
 	<-f.stopCh
 }
 
+func (f *forwarder) Done() <-chan struct{} {
+	return f.doneCh
+}
+
 func (f *forwarder) buildK8sPortForwarder(readyCh chan struct{}) (*portforward.PortForwarder, error) {
 	restClient, err := rest.RESTClientFor(f.restConfig)
 	if err != nil {
@@ -147,6 +156,7 @@ func NewPortForwarder(restConfig *rest.Config, podName, ns, localAddress string,
 	}
 	f := &forwarder{
 		stopCh:       make(chan struct{}),
+		doneCh:       make(chan struct{}),
 		restConfig:   restConfig,
 		podName:      podName,
 		ns:           ns,
@@ -0,0 +1,157 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+// ResourceClient 按协商后的版本访问CRD资源, 同一资源各版本的结构兼容, 请求和响应都通过json转换为调用方使用的类型
+type ResourceClient struct {
+	cli    rest.Interface
+	gvr    schema.GroupVersionResource
+	scheme *runtime.Scheme
+	kind   string
+	err    error
+}
+
+// NewResourceClient cli 为协商后版本的rest客户端, scheme 用于确定调用方类型的kind
+func NewResourceClient(cli rest.Interface, gvr schema.GroupVersionResource, scheme *runtime.Scheme) *ResourceClient {
+	return &ResourceClient{cli: cli, gvr: gvr, scheme: scheme}
+}
+
+// NewResourceClientError 版本协商失败时, 所有请求都返回该错误
+func NewResourceClientError(gvr schema.GroupVersionResource, err error) *ResourceClient {
+	return &ResourceClient{gvr: gvr, err: err}
+}
+
+// WithKind 资源改名后(如 ReferencePolicy -> ReferenceGrant)沿用旧的类型时, 指定请求中的kind
+func (r *ResourceClient) WithKind(kind string) *ResourceClient {
+	r.kind = kind
+	return r
+}
+
+func (r *ResourceClient) Get(namespace, name string, out runtime.Object) error {
+	if r.err != nil {
+		return r.err
+	}
+	data, err := r.cli.Get().Namespace(namespace).Resource(r.gvr.Resource).Name(name).
+		Do(context.Background()).Raw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (r *ResourceClient) List(namespace string, selector labels.Selector, out runtime.Object) error {
+	if r.err != nil {
+		return r.err
+	}
+	data, err := r.cli.Get().Namespace(namespace).Resource(r.gvr.Resource).
+		VersionedParams(&metav1.ListOptions{LabelSelector: selector.String()}, metav1.ParameterCodec).
+		Do(context.Background()).Raw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (r *ResourceClient) Create(obj runtime.Object, out runtime.Object, dryRun bool) error {
+	if r.err != nil {
+		return r.err
+	}
+	body, namespace, _, err := r.encode(obj)
+	if err != nil {
+		return err
+	}
+	data, err := r.cli.Post().Namespace(namespace).Resource(r.gvr.Resource).
+		VersionedParams(&metav1.CreateOptions{DryRun: DryRunOption(dryRun)}, metav1.ParameterCodec).
+		Body(body).Do(context.Background()).Raw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (r *ResourceClient) Update(obj runtime.Object, out runtime.Object, dryRun bool) error {
+	if r.err != nil {
+		return r.err
+	}
+	body, namespace, name, err := r.encode(obj)
+	if err != nil {
+		return err
+	}
+	data, err := r.cli.Put().Namespace(namespace).Resource(r.gvr.Resource).Name(name).
+		VersionedParams(&metav1.UpdateOptions{DryRun: DryRunOption(dryRun)}, metav1.ParameterCodec).
+		Body(body).Do(context.Background()).Raw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Patch CRD 不支持 strategic merge patch, patchType 只能是 json patch 或 merge patch
+func (r *ResourceClient) Patch(namespace, name string, patchType types.PatchType, patch []byte, out runtime.Object, dryRun bool) error {
+	if r.err != nil {
+		return r.err
+	}
+	data, err := r.cli.Patch(patchType).Namespace(namespace).Resource(r.gvr.Resource).Name(name).
+		VersionedParams(&metav1.PatchOptions{DryRun: DryRunOption(dryRun)}, metav1.ParameterCodec).
+		Body(patch).Do(context.Background()).Raw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Delete resourceVersion 不为空时, 只有版本一致才会删除
+func (r *ResourceClient) Delete(namespace, name, resourceVersion string, dryRun bool) error {
+	if r.err != nil {
+		return r.err
+	}
+	opts := metav1.DeleteOptions{DryRun: DryRunOption(dryRun)}
+	if resourceVersion != "" {
+		opts.Preconditions = &metav1.Preconditions{ResourceVersion: &resourceVersion}
+	}
+	body, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	return r.cli.Delete().Namespace(namespace).Resource(r.gvr.Resource).Name(name).
+		Body(body).Do(context.Background()).Error()
+}
+
+// encode 将对象的apiVersion改为协商后的版本, 没有指定kind时由对象的类型决定
+func (r *ResourceClient) encode(obj runtime.Object) ([]byte, string, string, error) {
+	kind := r.kind
+	if kind == "" {
+		gvks, _, err := r.scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, "", "", err
+		}
+		kind = gvks[0].Kind
+	}
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(r.gvr.GroupVersion().WithKind(kind))
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, "", "", err
+	}
+	body, err := json.Marshal(obj)
+	return body, accessor.GetNamespace(), accessor.GetName(), err
+}
+
+// DryRunOption 服务端试运行, 会经过校验和准入webhook, 但不会持久化
+func DryRunOption(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package kube
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type Service struct {
+	cli *kubernetes.Clientset
+}
+
+func NewService(cli *kubernetes.Clientset) *Service {
+	return &Service{cli: cli}
+}
+
+func (s *Service) GetService(namespace, serviceName string) (*v1.Service, error) {
+	return s.cli.CoreV1().Services(namespace).Get(context.Background(), serviceName, metav1.GetOptions{})
+}
+
+// ListEndpointSlices 获取服务的所有EndpointSlice
+func (s *Service) ListEndpointSlices(namespace, serviceName string) (*discoveryv1.EndpointSliceList, error) {
+	return s.cli.DiscoveryV1().EndpointSlices(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+	})
+}
+
+func (s *Service) ListServices(namespace string) (*v1.ServiceList, error) {
+	return s.cli.CoreV1().Services(namespace).List(context.Background(), metav1.ListOptions{})
+}
@@ -0,0 +1,120 @@
+package kube
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const defaultAPIVersionsTTL = 5 * time.Minute
+
+// APIVersions 集群中每种资源实际提供的版本, 按集群的优先级排列
+type APIVersions map[schema.GroupResource][]string
+
+// apiVersionsCache 按集群和组缓存discovery的结果, 避免每次请求都查询
+var apiVersionsCache = struct {
+	mu      sync.Mutex
+	entries map[apiVersionsKey]apiVersionsEntry
+}{entries: make(map[apiVersionsKey]apiVersionsEntry)}
+
+type apiVersionsKey struct {
+	cid   string
+	group string
+}
+
+type apiVersionsEntry struct {
+	versions APIVersions
+	expireAt time.Time
+}
+
+// DiscoverAPIVersions 查询集群中指定组提供的资源及版本
+func DiscoverAPIVersions(cid string, cli discovery.DiscoveryInterface, groups []string) (APIVersions, error) {
+	versions := make(APIVersions)
+	missing := make(map[string]bool)
+	now := time.Now()
+	apiVersionsCache.mu.Lock()
+	for _, group := range groups {
+		entry, ok := apiVersionsCache.entries[apiVersionsKey{cid: cid, group: group}]
+		if !ok || now.After(entry.expireAt) {
+			missing[group] = true
+			continue
+		}
+		for gr, served := range entry.versions {
+			versions[gr] = served
+		}
+	}
+	apiVersionsCache.mu.Unlock()
+	if len(missing) == 0 {
+		return versions, nil
+	}
+
+	serverGroups, err := cli.ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	discovered := make(map[string]APIVersions)
+	for group := range missing {
+		// 集群中没有的组也需要缓存
+		discovered[group] = make(APIVersions)
+	}
+	for _, group := range serverGroups.Groups {
+		if !missing[group.Name] {
+			continue
+		}
+		for _, version := range group.Versions {
+			resources, err := cli.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return nil, err
+			}
+			for _, resource := range resources.APIResources {
+				gr := schema.GroupResource{Group: group.Name, Resource: resource.Name}
+				discovered[group.Name][gr] = append(discovered[group.Name][gr], version.Version)
+				versions[gr] = append(versions[gr], version.Version)
+			}
+		}
+	}
+
+	apiVersionsCache.mu.Lock()
+	for group, groupVersions := range discovered {
+		apiVersionsCache.entries[apiVersionsKey{cid: cid, group: group}] = apiVersionsEntry{
+			versions: groupVersions,
+			expireAt: now.Add(defaultAPIVersionsTTL),
+		}
+	}
+	apiVersionsCache.mu.Unlock()
+	return versions, nil
+}
+
+// IsServed 集群是否提供该版本的资源
+func (v APIVersions) IsServed(gvr schema.GroupVersionResource) bool {
+	for _, version := range v[gvr.GroupResource()] {
+		if version == gvr.Version {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate 按客户端支持的版本的优先级, 选择集群提供的版本
+func (v APIVersions) Negotiate(gr schema.GroupResource, supported []string) (schema.GroupVersionResource, error) {
+	served := v[gr]
+	for _, version := range supported {
+		for _, servedVersion := range served {
+			if version == servedVersion {
+				return gr.WithVersion(version), nil
+			}
+		}
+	}
+	return schema.GroupVersionResource{}, &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusNotFound,
+		Reason:  metav1.StatusReasonNotFound,
+		Message: fmt.Sprintf("%s is not served by the cluster in any supported version %v, served: %v", gr, supported, served),
+	}}
+}
@@ -0,0 +1,112 @@
+package sidecar
+
+import (
+	"fmt"
+
+	bootstrapv3 "github.com/envoyproxy/go-control-plane/envoy/config/bootstrap/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"google.golang.org/protobuf/proto"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/util/protomarshal"
+)
+
+// Bootstrap 边车启动配置, 包含节点元数据、连接的istiod以及指标和链路追踪配置
+type Bootstrap struct {
+	NodeID           string                     `json:"nodeId"`
+	ServiceCluster   string                     `json:"serviceCluster"`
+	IstioVersion     string                     `json:"istioVersion"`
+	ClusterID        string                     `json:"clusterId"`
+	MeshID           string                     `json:"meshId"`
+	Namespace        string                     `json:"namespace"`
+	Labels           map[string]string          `json:"labels"`
+	Locality         *core.Locality             `json:"locality"`
+	ProxyConfig      *model.NodeMetaProxyConfig `json:"proxyConfig"`
+	DiscoveryAddress string                     `json:"discoveryAddress"`
+	XdsServer        XdsServer                  `json:"xdsServer"`
+	StatsSinks       []interface{}              `json:"statsSinks"`
+	Tracing          interface{}                `json:"tracing"`
+}
+
+// XdsServer envoy实际连接的xDS服务, 通常是pilot-agent的unix socket, 由pilot-agent代理到istiod
+type XdsServer struct {
+	Cluster   string   `json:"cluster"`
+	Addresses []string `json:"addresses"`
+}
+
+func ConfigDumpToBootstrap(configDump *ConfigDump) (*Bootstrap, error) {
+	bootstrapDump, err := configDump.GetBootstrapConfigDump()
+	if err != nil {
+		return nil, err
+	}
+	bs := bootstrapDump.GetBootstrap()
+	meta, err := model.ParseMetadata(bs.GetNode().GetMetadata())
+	if err != nil {
+		return nil, err
+	}
+
+	bootstrap := &Bootstrap{
+		NodeID:         bs.GetNode().GetId(),
+		ServiceCluster: bs.GetNode().GetCluster(),
+		IstioVersion:   meta.IstioVersion,
+		ClusterID:      meta.ClusterID.String(),
+		MeshID:         meta.MeshID,
+		Namespace:      meta.Namespace,
+		Labels:         meta.Labels,
+		Locality:       bs.GetNode().GetLocality(),
+		ProxyConfig:    meta.ProxyConfig,
+		XdsServer:      retrieveXdsServer(bs),
+		StatsSinks:     make([]interface{}, 0),
+	}
+	if meta.ProxyConfig != nil {
+		bootstrap.DiscoveryAddress = meta.ProxyConfig.DiscoveryAddress
+	}
+	for _, sink := range bs.GetStatsSinks() {
+		bootstrap.StatsSinks = append(bootstrap.StatsSinks, describeProto(sink, sink.GetName()))
+	}
+	if bs.GetTracing() != nil {
+		bootstrap.Tracing = describeProto(bs.GetTracing(), bs.GetTracing().GetHttp().GetName())
+	}
+	return bootstrap, nil
+}
+
+// 找到ADS使用的集群及其地址
+func retrieveXdsServer(bs *bootstrapv3.Bootstrap) XdsServer {
+	server := XdsServer{Addresses: make([]string, 0)}
+	for _, grpcService := range bs.GetDynamicResources().GetAdsConfig().GetGrpcServices() {
+		if grpcService.GetEnvoyGrpc() != nil {
+			server.Cluster = grpcService.GetEnvoyGrpc().GetClusterName()
+			break
+		}
+		if grpcService.GetGoogleGrpc() != nil {
+			server.Addresses = append(server.Addresses, grpcService.GetGoogleGrpc().GetTargetUri())
+		}
+	}
+	if server.Cluster == "" {
+		return server
+	}
+	for _, c := range bs.GetStaticResources().GetClusters() {
+		if c.GetName() != server.Cluster {
+			continue
+		}
+		for _, lbEndpoints := range c.GetLoadAssignment().GetEndpoints() {
+			for _, lbEndpoint := range lbEndpoints.GetLbEndpoints() {
+				addr := lbEndpoint.GetEndpoint().GetAddress()
+				if sockAddr := addr.GetSocketAddress(); sockAddr != nil {
+					server.Addresses = append(server.Addresses, fmt.Sprintf("%s:%d", sockAddr.GetAddress(), sockAddr.GetPortValue()))
+				} else if pipe := addr.GetPipe(); pipe != nil {
+					server.Addresses = append(server.Addresses, "unix://"+pipe.GetPath())
+				}
+			}
+		}
+	}
+	return server
+}
+
+// describeProto 转为json展示, typed_config 的类型未注册时只返回名称
+func describeProto(msg proto.Message, name string) interface{} {
+	m, err := protomarshal.ToJSONMap(msg)
+	if err != nil {
+		return map[string]interface{}{"name": name, "error": err.Error()}
+	}
+	return m
+}
@@ -0,0 +1,77 @@
+package sidecar
+
+import (
+	"sync"
+	"time"
+)
+
+const defaultConfigDumpTTL = 10 * time.Second
+
+// configDumps 缓存envoy的config_dump, CDS/LDS/RDS等视图共用一次请求
+var configDumps = newConfigDumpCache(defaultConfigDumpTTL)
+
+type configDumpKey struct {
+	clusterID string
+	namespace string
+	pod       string
+}
+
+type configDumpEntry struct {
+	ready    chan struct{}
+	config   []byte
+	err      error
+	expireAt time.Time
+}
+
+type configDumpCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[configDumpKey]*configDumpEntry
+}
+
+func newConfigDumpCache(ttl time.Duration) *configDumpCache {
+	return &configDumpCache{ttl: ttl, entries: make(map[configDumpKey]*configDumpEntry)}
+}
+
+// get 缓存未过期时直接返回, 同一个pod的并发请求只会拉取一次
+func (c *configDumpCache) get(key configDumpKey, fetch func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	c.removeExpired()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &configDumpEntry{ready: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+		return entry.config, entry.err
+	}
+
+	entry.config, entry.err = fetch()
+	c.mu.Lock()
+	if entry.err != nil {
+		// 失败的结果不缓存
+		delete(c.entries, key)
+	} else {
+		entry.expireAt = time.Now().Add(c.ttl)
+	}
+	c.mu.Unlock()
+	close(entry.ready)
+	return entry.config, entry.err
+}
+
+func (c *configDumpCache) removeExpired() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		select {
+		case <-entry.ready:
+			if now.After(entry.expireAt) {
+				delete(c.entries, key)
+			}
+		default:
+			// 正在拉取
+		}
+	}
+}
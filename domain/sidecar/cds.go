@@ -1,7 +1,6 @@
 package sidecar
 
 import (
-	"fmt"
 	"strings"
 
 	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
@@ -26,33 +25,31 @@ func ClustersToCDS(configDump *ConfigDump) []CDS {
 		return cds
 	}
 	for _, cluster := range clusters {
-		if len(strings.Split(cluster.GetName(), "|")) > 3 {
-			direction, subset, fqdn, port := model.ParseSubsetKey(cluster.GetName())
-			if subset == "" {
-				subset = "-"
-			}
-			cds = append(cds, CDS{
-				FQDN:            fqdn,
-				Port:            port,
-				Subset:          subset,
-				Direction:       direction,
-				Type:            cluster.GetType().String(),
-				DestinationRule: mdToDestinationRule(cluster.GetMetadata()),
-			})
-		} else {
-			cds = append(cds, CDS{
-				FQDN:            host.Name(cluster.GetName()),
-				Port:            0,
-				Subset:          "-",
-				Direction:       "-",
-				Type:            cluster.GetType().String(),
-				DestinationRule: mdToDestinationRule(cluster.GetMetadata()),
-			})
-		}
+		direction, subset, fqdn, port := parseClusterName(cluster.GetName())
+		cds = append(cds, CDS{
+			FQDN:            fqdn,
+			Port:            port,
+			Subset:          subset,
+			Direction:       direction,
+			Type:            cluster.GetType().String(),
+			DestinationRule: mdToDestinationRule(cluster.GetMetadata()),
+		})
 	}
 	return cds
 }
 
+// parseClusterName 解析istio生成的集群名称 direction|port|subset|fqdn, 其他集群(如BlackHoleCluster)直接作为fqdn
+func parseClusterName(name string) (model.TrafficDirection, string, host.Name, int) {
+	if len(strings.Split(name, "|")) > 3 {
+		direction, subset, fqdn, port := model.ParseSubsetKey(name)
+		if subset == "" {
+			subset = "-"
+		}
+		return direction, subset, fqdn, port
+	}
+	return "-", "-", host.Name(name), 0
+}
+
 func mdToDestinationRule(metadata *core.Metadata) string {
 	if metadata == nil {
 		return ""
@@ -65,12 +62,5 @@ func mdToDestinationRule(metadata *core.Metadata) string {
 	if !ok {
 		return ""
 	}
-	if strings.HasPrefix(config.GetStringValue(), "/apis/networking.istio.io/v1alpha3/namespaces/") {
-		pieces := strings.Split(config.GetStringValue(), "/")
-		if len(pieces) != 8 {
-			return ""
-		}
-		return fmt.Sprintf("%s.%s", pieces[7], pieces[5])
-	}
-	return "<unknown>"
+	return renderConfig(config.GetStringValue())
 }
@@ -0,0 +1,208 @@
+package sidecar
+
+import (
+	"fmt"
+	"sort"
+
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	"google.golang.org/protobuf/proto"
+	"istio.io/istio/pilot/pkg/xds/v3"
+)
+
+// XdsCheck 单个xDS类型的同步结果, Diffs 中 from 为istiod下发的配置, to 为envoy当前生效的配置
+type XdsCheck struct {
+	XdsStatus
+	Version string         `json:"version"`
+	Diffs   []ResourceDiff `json:"diffs"`
+}
+
+// SyncCheck 等同于 istioctl proxy-status <pod>
+type SyncCheck struct {
+	ProxyID      string   `json:"proxyId"`
+	ClusterID    string   `json:"clusterId"`
+	Istiod       string   `json:"istiod"`
+	IstioVersion string   `json:"istioVersion"`
+	CDS          XdsCheck `json:"cds"`
+	LDS          XdsCheck `json:"lds"`
+	RDS          XdsCheck `json:"rds"`
+	EDS          XdsCheck `json:"eds"`
+}
+
+// CompareConfigDump envoy 的config_dump需要包含端点(config_dump?include_eds), istiodEndpoints 为istiod /debug/edsz 返回的端点
+func CompareConfigDump(proxyID string, status *ProxyStatus, istiod, envoy *ConfigDump, istiodEndpoints []*endpoint.ClusterLoadAssignment) *SyncCheck {
+	if status == nil {
+		// 代理没有连接到任何istiod
+		status = &ProxyStatus{
+			ProxyID: proxyID,
+			CDS:     newXdsStatus("", ""),
+			LDS:     newXdsStatus("", ""),
+			EDS:     newXdsStatus("", ""),
+			RDS:     newXdsStatus("", ""),
+		}
+	}
+	return &SyncCheck{
+		ProxyID:      status.ProxyID,
+		ClusterID:    status.ClusterID,
+		Istiod:       status.Istiod,
+		IstioVersion: status.IstioVersion,
+		CDS: XdsCheck{
+			XdsStatus: status.CDS,
+			Version:   envoy.clustersVersion(),
+			Diffs:     DiffResources(clustersByName(istiod), clustersByName(envoy)),
+		},
+		LDS: XdsCheck{
+			XdsStatus: status.LDS,
+			Version:   envoy.listenersVersion(),
+			Diffs:     DiffResources(listenersByName(istiod), listenersByName(envoy)),
+		},
+		RDS: XdsCheck{
+			XdsStatus: status.RDS,
+			Version:   envoy.routesVersion(),
+			Diffs:     DiffResources(routesByName(istiod), routesByName(envoy)),
+		},
+		EDS: XdsCheck{
+			XdsStatus: status.EDS,
+			Version:   envoy.endpointsVersion(),
+			Diffs:     DiffResources(endpointsByName(istiodEndpoints), endpointsByName(envoy.dynamicEndpoints())),
+		},
+	}
+}
+
+func clustersByName(configDump *ConfigDump) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	clusters, err := configDump.GetDynamicClusters()
+	if err != nil {
+		return resources
+	}
+	for _, c := range clusters {
+		resources[c.GetName()] = c
+	}
+	return resources
+}
+
+func listenersByName(configDump *ConfigDump) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	listeners, err := configDump.GetDynamicListeners()
+	if err != nil {
+		return resources
+	}
+	for _, l := range listeners {
+		resources[l.GetName()] = l
+	}
+	return resources
+}
+
+func routesByName(configDump *ConfigDump) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	routes, err := configDump.GetDynamicRouters()
+	if err != nil {
+		return resources
+	}
+	for _, r := range routes {
+		resources[r.GetName()] = r
+	}
+	return resources
+}
+
+// endpointsByName 只比较端点地址, envoy中的端点带有健康检查后的状态和默认的权重, 与istiod生成的不能直接比较
+func endpointsByName(assignments []*endpoint.ClusterLoadAssignment) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	for _, assignment := range assignments {
+		lbEndpoints := make([]*endpoint.LbEndpoint, 0)
+		for _, localityEndpoints := range assignment.GetEndpoints() {
+			for _, lbEndpoint := range localityEndpoints.GetLbEndpoints() {
+				lbEndpoints = append(lbEndpoints, &endpoint.LbEndpoint{
+					HostIdentifier: &endpoint.LbEndpoint_Endpoint{
+						Endpoint: &endpoint.Endpoint{Address: lbEndpoint.GetEndpoint().GetAddress()},
+					},
+				})
+			}
+		}
+		sort.Slice(lbEndpoints, func(i, j int) bool {
+			return endpointAddress(lbEndpoints[i]) < endpointAddress(lbEndpoints[j])
+		})
+		resources[assignment.GetClusterName()] = &endpoint.ClusterLoadAssignment{
+			ClusterName: assignment.GetClusterName(),
+			Endpoints:   []*endpoint.LocalityLbEndpoints{{LbEndpoints: lbEndpoints}},
+		}
+	}
+	return resources
+}
+
+func endpointAddress(lbEndpoint *endpoint.LbEndpoint) string {
+	addr := lbEndpoint.GetEndpoint().GetAddress()
+	if addr.GetPipe() != nil {
+		return "unix://" + addr.GetPipe().GetPath()
+	}
+	return fmt.Sprintf("%s:%d", addr.GetSocketAddress().GetAddress(), addr.GetSocketAddress().GetPortValue())
+}
+
+// dynamicEndpoints istiod只下发EDS集群的端点, 不比较静态集群中的端点
+func (c *ConfigDump) dynamicEndpoints() []*endpoint.ClusterLoadAssignment {
+	assignments := make([]*endpoint.ClusterLoadAssignment, 0)
+	ed, err := c.GetEndpointConfigDump()
+	if err != nil {
+		return assignments
+	}
+	for _, e := range ed.DynamicEndpointConfigs {
+		if e.GetEndpointConfig() == nil {
+			continue
+		}
+		assignment := &endpoint.ClusterLoadAssignment{}
+		e.EndpointConfig.TypeUrl = v3.EndpointType
+		if err := e.EndpointConfig.UnmarshalTo(assignment); err == nil {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments
+}
+
+func (c *ConfigDump) clustersVersion() string {
+	cd, err := c.GetClusterConfigDump()
+	if err != nil {
+		return ""
+	}
+	return cd.GetVersionInfo()
+}
+
+func (c *ConfigDump) listenersVersion() string {
+	ld, err := c.GetListenerConfigDump()
+	if err != nil {
+		return ""
+	}
+	return ld.GetVersionInfo()
+}
+
+// 路由没有全局的版本号, 取最近一次更新的路由版本
+func (c *ConfigDump) routesVersion() string {
+	rd, err := c.GetRouterConfigDump()
+	if err != nil {
+		return ""
+	}
+	version := ""
+	var lastUpdated int64
+	for _, r := range rd.DynamicRouteConfigs {
+		if updated := r.GetLastUpdated().AsTime().UnixNano(); version == "" || updated > lastUpdated {
+			version = r.GetVersionInfo()
+			lastUpdated = updated
+		}
+	}
+	return version
+}
+
+// 端点没有全局的版本号, 取最近一次更新的端点版本
+func (c *ConfigDump) endpointsVersion() string {
+	ed, err := c.GetEndpointConfigDump()
+	if err != nil {
+		return ""
+	}
+	version := ""
+	var lastUpdated int64
+	for _, e := range ed.DynamicEndpointConfigs {
+		if updated := e.GetLastUpdated().AsTime().UnixNano(); version == "" || updated > lastUpdated {
+			version = e.GetVersionInfo()
+			lastUpdated = updated
+		}
+	}
+	return version
+}
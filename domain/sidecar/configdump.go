@@ -6,17 +6,22 @@ import (
 
 	admin "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
 	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/golang/protobuf/ptypes/any"
 	"istio.io/istio/pilot/pkg/xds/v3"
 	"istio.io/istio/pkg/util/protomarshal"
 )
 
 const (
+	bootstrap string = "type.googleapis.com/envoy.admin.v3.BootstrapConfigDump"
 	clusters  string = "type.googleapis.com/envoy.admin.v3.ClustersConfigDump"
 	listeners string = "type.googleapis.com/envoy.admin.v3.ListenersConfigDump"
 	routes    string = "type.googleapis.com/envoy.admin.v3.RoutesConfigDump"
+	secrets   string = "type.googleapis.com/envoy.admin.v3.SecretsConfigDump"
+	endpoints string = "type.googleapis.com/envoy.admin.v3.EndpointsConfigDump"
 )
 
 type ConfigDump struct {
@@ -43,6 +48,25 @@ func (c *ConfigDump) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+func (c *ConfigDump) GetBootstrapConfigDump() (*admin.BootstrapConfigDump, error) {
+	var bootstrapDumpAny *any.Any
+	for _, conf := range c.Configs {
+		if conf.TypeUrl == bootstrap {
+			bootstrapDumpAny = conf
+		}
+	}
+	if bootstrapDumpAny == nil {
+		return nil, fmt.Errorf("config dump has no configuration type %s", bootstrap)
+	}
+
+	bootstrapDump := &admin.BootstrapConfigDump{}
+	err := bootstrapDumpAny.UnmarshalTo(bootstrapDump)
+	if err != nil {
+		return nil, err
+	}
+	return bootstrapDump, nil
+}
+
 func (c *ConfigDump) GetClusterConfigDump() (*admin.ClustersConfigDump, error) {
 	var clusterDumpAny *any.Any
 	for _, conf := range c.Configs {
@@ -176,3 +200,140 @@ func (c *ConfigDump) GetRouters() ([]*route.RouteConfiguration, error) {
 
 	return routes, nil
 }
+
+// GetEndpointConfigDump 只有 config_dump?include_eds 才包含端点
+func (c *ConfigDump) GetEndpointConfigDump() (*admin.EndpointsConfigDump, error) {
+	var endpointDumpAny *any.Any
+	for _, conf := range c.Configs {
+		if conf.TypeUrl == endpoints {
+			endpointDumpAny = conf
+		}
+	}
+	if endpointDumpAny == nil {
+		return nil, fmt.Errorf("config dump has no configuration type %s", endpoints)
+	}
+
+	endpointDump := &admin.EndpointsConfigDump{}
+	err := endpointDumpAny.UnmarshalTo(endpointDump)
+	if err != nil {
+		return nil, err
+	}
+	return endpointDump, nil
+}
+
+func (c *ConfigDump) GetEndpoints() ([]*endpoint.ClusterLoadAssignment, error) {
+	endpoints := make([]*endpoint.ClusterLoadAssignment, 0)
+	ed, err := c.GetEndpointConfigDump()
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range ed.StaticEndpointConfigs {
+		if e.GetEndpointConfig() == nil {
+			continue
+		}
+		tmpEndpoint := &endpoint.ClusterLoadAssignment{}
+		e.EndpointConfig.TypeUrl = v3.EndpointType
+		if err := e.EndpointConfig.UnmarshalTo(tmpEndpoint); err == nil {
+			endpoints = append(endpoints, tmpEndpoint)
+		}
+	}
+	for _, e := range ed.DynamicEndpointConfigs {
+		if e.GetEndpointConfig() == nil {
+			continue
+		}
+		tmpEndpoint := &endpoint.ClusterLoadAssignment{}
+		e.EndpointConfig.TypeUrl = v3.EndpointType
+		if err := e.EndpointConfig.UnmarshalTo(tmpEndpoint); err == nil {
+			endpoints = append(endpoints, tmpEndpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+func (c *ConfigDump) GetSecretConfigDump() (*admin.SecretsConfigDump, error) {
+	var secretDumpAny *any.Any
+	for _, conf := range c.Configs {
+		if conf.TypeUrl == secrets {
+			secretDumpAny = conf
+		}
+	}
+	if secretDumpAny == nil {
+		return nil, fmt.Errorf("config dump has no configuration type %s", secrets)
+	}
+
+	secretDump := &admin.SecretsConfigDump{}
+	err := secretDumpAny.UnmarshalTo(secretDump)
+	if err != nil {
+		return nil, err
+	}
+	return secretDump, nil
+}
+
+// NewSecret 解析config_dump中的证书, 私钥已经被envoy隐藏
+func NewSecret(secretAny *any.Any) (*tls.Secret, error) {
+	secret := &tls.Secret{}
+	secretAny.TypeUrl = v3.SecretType
+	if err := secretAny.UnmarshalTo(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// GetDynamicClusters 获取istiod通过xDS下发的集群, 不包含bootstrap中的静态集群
+func (c *ConfigDump) GetDynamicClusters() ([]*cluster.Cluster, error) {
+	clusters := make([]*cluster.Cluster, 0)
+	cd, err := c.GetClusterConfigDump()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cd.DynamicActiveClusters {
+		if c.GetCluster() != nil {
+			tmpCluster := &cluster.Cluster{}
+			c.Cluster.TypeUrl = v3.ClusterType
+			if err := c.Cluster.UnmarshalTo(tmpCluster); err == nil {
+				clusters = append(clusters, tmpCluster)
+			}
+		}
+	}
+	return clusters, nil
+}
+
+// GetDynamicListeners 获取istiod通过xDS下发且已生效的监听器
+func (c *ConfigDump) GetDynamicListeners() ([]*listener.Listener, error) {
+	listeners := make([]*listener.Listener, 0)
+	ld, err := c.GetListenerConfigDump()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range ld.DynamicListeners {
+		if l.GetActiveState().GetListener() == nil {
+			continue
+		}
+		tmpListener := &listener.Listener{}
+		l.ActiveState.Listener.TypeUrl = v3.ListenerType
+		if err := l.ActiveState.Listener.UnmarshalTo(tmpListener); err == nil {
+			listeners = append(listeners, tmpListener)
+		}
+	}
+	return listeners, nil
+}
+
+// GetDynamicRouters 获取istiod通过xDS下发的路由
+func (c *ConfigDump) GetDynamicRouters() ([]*route.RouteConfiguration, error) {
+	routes := make([]*route.RouteConfiguration, 0)
+	rd, err := c.GetRouterConfigDump()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rd.DynamicRouteConfigs {
+		if r.GetRouteConfig() == nil {
+			continue
+		}
+		tmpRoute := &route.RouteConfiguration{}
+		r.RouteConfig.TypeUrl = v3.RouteType
+		if err := r.RouteConfig.UnmarshalTo(tmpRoute); err == nil {
+			routes = append(routes, tmpRoute)
+		}
+	}
+	return routes, nil
+}
@@ -0,0 +1,101 @@
+package sidecar
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	"google.golang.org/protobuf/proto"
+	"istio.io/istio/pkg/util/protomarshal"
+
+	// 注册envoy所有扩展的类型, 用于解析过滤器中的 typed_config
+	_ "istio.io/istio/pkg/config/xds"
+)
+
+const (
+	DetailFormatJSON = "json"
+	DetailFormatYAML = "yaml"
+)
+
+// GetClusterDetail 获取集群的完整配置, 包括熔断、异常检测、TLS等
+func (s *Sidecar) GetClusterDetail(namespace, pod, name, format string) (interface{}, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return ClusterDetail(configDump, name, format)
+}
+
+// GetListenerDetail address 为监听器的 address:port 或名称
+func (s *Sidecar) GetListenerDetail(namespace, pod, address, format string) (interface{}, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return ListenerDetail(configDump, address, format)
+}
+
+// GetRouteDetail 获取路由的完整配置, 包括header匹配、重试、超时、权重等
+func (s *Sidecar) GetRouteDetail(namespace, pod, name, format string) (interface{}, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return RouteDetail(configDump, name, format)
+}
+
+func ClusterDetail(configDump *ConfigDump, name, format string) (interface{}, error) {
+	clusters, err := configDump.GetClusters()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clusters {
+		if c.GetName() == name {
+			return marshalDetail(c, format)
+		}
+	}
+	return nil, fmt.Errorf("cluster %s not found", name)
+}
+
+func ListenerDetail(configDump *ConfigDump, address, format string) (interface{}, error) {
+	listeners, err := configDump.GetListeners()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range listeners {
+		if l.GetName() == address || listenerHostPort(l) == address {
+			return marshalDetail(l, format)
+		}
+	}
+	return nil, fmt.Errorf("listener %s not found", address)
+}
+
+func RouteDetail(configDump *ConfigDump, name, format string) (interface{}, error) {
+	routes, err := configDump.GetRouters()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		if r.GetName() == name {
+			return marshalDetail(r, format)
+		}
+	}
+	return nil, fmt.Errorf("route %s not found", name)
+}
+
+func listenerHostPort(l *listener.Listener) string {
+	port := l.GetAddress().GetSocketAddress().GetPortValue()
+	return net.JoinHostPort(retrieveListenerAddress(l), strconv.Itoa(int(port)))
+}
+
+// marshalDetail json格式返回对象, 由gin序列化; yaml格式返回字符串
+func marshalDetail(msg proto.Message, format string) (interface{}, error) {
+	switch format {
+	case "", DetailFormatJSON:
+		return protomarshal.ToJSONMap(msg)
+	case DetailFormatYAML:
+		return protomarshal.ToYAML(msg)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
@@ -0,0 +1,233 @@
+package sidecar
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
+	"google.golang.org/protobuf/proto"
+	"istio.io/istio/pkg/util/protomarshal"
+)
+
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// ResourceDiff 同名xDS资源的差异, added/removed 以 to 为准
+type ResourceDiff struct {
+	Name   string      `json:"name"`
+	Action string      `json:"action"`
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// FieldDiff 单个字段的差异, Path 为 protobuf json 字段路径, 如 circuitBreakers.thresholds[0].maxRequests
+type FieldDiff struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ConfigDiff 两个边车的配置差异, 只比较资源本身, 不包含 version_info 和 last_updated 等易变字段
+type ConfigDiff struct {
+	Clusters  []ResourceDiff `json:"clusters"`
+	Listeners []ResourceDiff `json:"listeners"`
+	Routes    []ResourceDiff `json:"routes"`
+	Endpoints []ResourceDiff `json:"endpoints"`
+}
+
+// DiffSidecar 比较两个边车的配置, 两个边车可以在不同的集群
+func DiffSidecar(from *Sidecar, fromNamespace, fromPod string, to *Sidecar, toNamespace, toPod string) (*ConfigDiff, error) {
+	fromDump, err := from.getConfigDump(fromNamespace, fromPod)
+	if err != nil {
+		return nil, err
+	}
+	toDump, err := to.getConfigDump(toNamespace, toPod)
+	if err != nil {
+		return nil, err
+	}
+	fromClusters, err := from.getClusters(fromNamespace, fromPod)
+	if err != nil {
+		return nil, err
+	}
+	toClusters, err := to.getClusters(toNamespace, toPod)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := DiffConfigDump(fromDump, toDump)
+	diff.Endpoints = DiffResources(endpointsByCluster(fromClusters), endpointsByCluster(toClusters))
+	return diff, nil
+}
+
+// DiffConfigDump 比较两份config_dump中的集群、监听器和路由, 包括bootstrap中的静态配置
+func DiffConfigDump(from, to *ConfigDump) *ConfigDiff {
+	return &ConfigDiff{
+		Clusters:  DiffResources(allClustersByName(from), allClustersByName(to)),
+		Listeners: DiffResources(allListenersByName(from), allListenersByName(to)),
+		Routes:    DiffResources(allRoutesByName(from), allRoutesByName(to)),
+		Endpoints: make([]ResourceDiff, 0),
+	}
+}
+
+func allClustersByName(configDump *ConfigDump) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	clusters, err := configDump.GetClusters()
+	if err != nil {
+		return resources
+	}
+	for _, c := range clusters {
+		resources[c.GetName()] = c
+	}
+	return resources
+}
+
+func allListenersByName(configDump *ConfigDump) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	listeners, err := configDump.GetListeners()
+	if err != nil {
+		return resources
+	}
+	for _, l := range listeners {
+		resources[l.GetName()] = l
+	}
+	return resources
+}
+
+func allRoutesByName(configDump *ConfigDump) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	routes, err := configDump.GetRouters()
+	if err != nil {
+		return resources
+	}
+	for _, r := range routes {
+		resources[r.GetName()] = r
+	}
+	return resources
+}
+
+// endpointsByCluster 只保留端点的地址、健康状态、权重、优先级和地域, 去掉实时变化的统计
+func endpointsByCluster(clusters *Cluster) map[string]proto.Message {
+	resources := make(map[string]proto.Message)
+	for _, cluster := range clusters.GetClusterStatuses() {
+		hosts := make([]*adminapi.HostStatus, 0, len(cluster.GetHostStatuses()))
+		for _, host := range cluster.GetHostStatuses() {
+			hosts = append(hosts, &adminapi.HostStatus{
+				Address: host.GetAddress(),
+				HealthStatus: &adminapi.HostHealthStatus{
+					EdsHealthStatus:    host.GetHealthStatus().GetEdsHealthStatus(),
+					FailedOutlierCheck: host.GetHealthStatus().GetFailedOutlierCheck(),
+				},
+				Weight:   host.GetWeight(),
+				Priority: host.GetPriority(),
+				Locality: host.GetLocality(),
+			})
+		}
+		sort.Slice(hosts, func(i, j int) bool {
+			return retrieveEndpointAddress(hosts[i]) < retrieveEndpointAddress(hosts[j]) ||
+				(retrieveEndpointAddress(hosts[i]) == retrieveEndpointAddress(hosts[j]) &&
+					retrieveEndpointPort(hosts[i]) < retrieveEndpointPort(hosts[j]))
+		})
+		resources[cluster.GetName()] = &adminapi.ClusterStatus{Name: cluster.GetName(), HostStatuses: hosts}
+	}
+	return resources
+}
+
+// DiffResources 按名称比较两组xDS资源
+func DiffResources(from, to map[string]proto.Message) []ResourceDiff {
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := make([]ResourceDiff, 0)
+	for _, name := range names {
+		f, inFrom := from[name]
+		t, inTo := to[name]
+		switch {
+		case !inFrom:
+			diffs = append(diffs, ResourceDiff{Name: name, Action: DiffAdded})
+		case !inTo:
+			diffs = append(diffs, ResourceDiff{Name: name, Action: DiffRemoved})
+		default:
+			if fields := diffMessage(f, t); len(fields) > 0 {
+				diffs = append(diffs, ResourceDiff{Name: name, Action: DiffChanged, Fields: fields})
+			}
+		}
+	}
+	return diffs
+}
+
+func diffMessage(from, to proto.Message) []FieldDiff {
+	fromMap, fromErr := protomarshal.ToJSONMap(from)
+	toMap, toErr := protomarshal.ToJSONMap(to)
+	if fromErr != nil || toErr != nil {
+		// 存在未注册的Any类型时无法转换为json, 只比较是否相等
+		if proto.Equal(from, to) {
+			return nil
+		}
+		return []FieldDiff{{Path: "", From: from, To: to}}
+	}
+	return diffValue("", fromMap, toMap)
+}
+
+func diffValue(path string, from, to interface{}) []FieldDiff {
+	switch f := from.(type) {
+	case map[string]interface{}:
+		t, ok := to.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f)+len(t))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		for k := range t {
+			if _, ok := f[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		diffs := make([]FieldDiff, 0)
+		for _, k := range keys {
+			diffs = append(diffs, diffValue(joinFieldPath(path, k), f[k], t[k])...)
+		}
+		return diffs
+	case []interface{}:
+		t, ok := to.([]interface{})
+		if !ok {
+			break
+		}
+		diffs := make([]FieldDiff, 0)
+		for i := 0; i < len(f) || i < len(t); i++ {
+			var fi, ti interface{}
+			if i < len(f) {
+				fi = f[i]
+			}
+			if i < len(t) {
+				ti = t[i]
+			}
+			diffs = append(diffs, diffValue(fmt.Sprintf("%s[%d]", path, i), fi, ti)...)
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	return []FieldDiff{{Path: path, From: from, To: to}}
+}
+
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
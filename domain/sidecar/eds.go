@@ -1,10 +1,19 @@
 package sidecar
 
 import (
+	"sort"
+
 	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
 	"github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 )
 
+// envoy /clusters 中每个端点的统计项
+const (
+	hostStatRqActive = "rq_active"
+	hostStatRqError  = "rq_error"
+	hostStatCxActive = "cx_active"
+)
+
 type EDSInfo map[string][]EDSClusterInfo
 
 type EDSClusterInfo struct {
@@ -12,8 +21,33 @@ type EDSClusterInfo struct {
 	Port               int    `json:"port"`
 	Status             string `json:"status"`
 	FailedOutlierCheck bool   `json:"filedOutlierCheck"`
+	Region             string `json:"region"`
+	Zone               string `json:"zone"`
+	SubZone            string `json:"subZone"`
+	Weight             uint32 `json:"weight"`
+	Priority           uint32 `json:"priority"`
+	RqActive           uint64 `json:"rqActive"`
+	RqError            uint64 `json:"rqError"`
+	CxActive           uint64 `json:"cxActive"`
 }
 
+// EDSLocality 集群中同一优先级、同一地域的端点汇总, WeightPercent 为该地域在同一优先级中的权重占比
+type EDSLocality struct {
+	Region        string  `json:"region"`
+	Zone          string  `json:"zone"`
+	SubZone       string  `json:"subZone"`
+	Priority      uint32  `json:"priority"`
+	Hosts         int     `json:"hosts"`
+	HealthyHosts  int     `json:"healthyHosts"`
+	Weight        uint32  `json:"weight"`
+	WeightPercent float64 `json:"weightPercent"`
+	RqActive      uint64  `json:"rqActive"`
+	RqError       uint64  `json:"rqError"`
+	CxActive      uint64  `json:"cxActive"`
+}
+
+type EDSLocalityInfo map[string][]EDSLocality
+
 type EDS struct {
 	Address            string `json:"address"`
 	Port               int    `json:"port"`
@@ -27,11 +61,20 @@ func ClustersToEDSInfo(clusters *Cluster) EDSInfo {
 	for _, cluster := range clusters.ClusterStatuses {
 		edsClusterInfos := make([]EDSClusterInfo, 0)
 		for _, host := range cluster.HostStatuses {
+			stats := retrieveHostStats(host)
 			edsClusterInfos = append(edsClusterInfos, EDSClusterInfo{
 				Address:            retrieveEndpointAddress(host),
 				Port:               int(retrieveEndpointPort(host)),
 				Status:             retrieveEndpointStatus(host).String(),
 				FailedOutlierCheck: retrieveFailedOutlierCheck(host),
+				Region:             host.GetLocality().GetRegion(),
+				Zone:               host.GetLocality().GetZone(),
+				SubZone:            host.GetLocality().GetSubZone(),
+				Weight:             host.GetWeight(),
+				Priority:           host.GetPriority(),
+				RqActive:           stats[hostStatRqActive],
+				RqError:            stats[hostStatRqError],
+				CxActive:           stats[hostStatCxActive],
 			})
 		}
 		edsInfo[cluster.Name] = edsClusterInfos
@@ -56,6 +99,97 @@ func ClustersToEDS(clusters *Cluster) []EDS {
 	return eds
 }
 
+// EndpointsToEDSInfo 从 config_dump?include_eds 中的端点生成EDS, 用于离线分析, 不包含端点的统计
+func EndpointsToEDSInfo(configDump *ConfigDump) EDSInfo {
+	edsInfo := make(EDSInfo)
+	assignments, err := configDump.GetEndpoints()
+	if err != nil {
+		return edsInfo
+	}
+	for _, assignment := range assignments {
+		edsClusterInfos := make([]EDSClusterInfo, 0)
+		for _, localityEndpoints := range assignment.GetEndpoints() {
+			locality := localityEndpoints.GetLocality()
+			for _, lbEndpoint := range localityEndpoints.GetLbEndpoints() {
+				addr := lbEndpoint.GetEndpoint().GetAddress()
+				address := addr.GetSocketAddress().GetAddress()
+				if addr.GetPipe() != nil {
+					address = "unix://" + addr.GetPipe().GetPath()
+				}
+				weight := uint32(1)
+				if lbEndpoint.GetLoadBalancingWeight() != nil {
+					weight = lbEndpoint.GetLoadBalancingWeight().GetValue()
+				}
+				edsClusterInfos = append(edsClusterInfos, EDSClusterInfo{
+					Address:  address,
+					Port:     int(addr.GetSocketAddress().GetPortValue()),
+					Status:   lbEndpoint.GetHealthStatus().String(),
+					Region:   locality.GetRegion(),
+					Zone:     locality.GetZone(),
+					SubZone:  locality.GetSubZone(),
+					Weight:   weight,
+					Priority: localityEndpoints.GetPriority(),
+				})
+			}
+		}
+		edsInfo[assignment.GetClusterName()] = edsClusterInfos
+	}
+	return edsInfo
+}
+
+// ClustersToEDSLocality 按优先级和地域汇总每个集群的端点, 用于确认地域故障转移和按地域分配流量是否生效
+func ClustersToEDSLocality(clusters *Cluster) EDSLocalityInfo {
+	localityInfo := make(EDSLocalityInfo)
+	for _, cluster := range clusters.ClusterStatuses {
+		localities := make([]EDSLocality, 0)
+		index := make(map[EDSLocality]int)
+		priorityWeight := make(map[uint32]uint32)
+		for _, host := range cluster.HostStatuses {
+			key := EDSLocality{
+				Region:   host.GetLocality().GetRegion(),
+				Zone:     host.GetLocality().GetZone(),
+				SubZone:  host.GetLocality().GetSubZone(),
+				Priority: host.GetPriority(),
+			}
+			i, ok := index[key]
+			if !ok {
+				i = len(localities)
+				index[key] = i
+				localities = append(localities, key)
+			}
+			stats := retrieveHostStats(host)
+			locality := &localities[i]
+			locality.Hosts++
+			if retrieveEndpointStatus(host) == corev3.HealthStatus_HEALTHY && !retrieveFailedOutlierCheck(host) {
+				locality.HealthyHosts++
+			}
+			locality.Weight += host.GetWeight()
+			locality.RqActive += stats[hostStatRqActive]
+			locality.RqError += stats[hostStatRqError]
+			locality.CxActive += stats[hostStatCxActive]
+			priorityWeight[host.GetPriority()] += host.GetWeight()
+		}
+		for i := range localities {
+			if total := priorityWeight[localities[i].Priority]; total > 0 {
+				localities[i].WeightPercent = float64(localities[i].Weight) * 100 / float64(total)
+			}
+		}
+		sort.SliceStable(localities, func(i, j int) bool {
+			return localities[i].Priority < localities[j].Priority
+		})
+		localityInfo[cluster.Name] = localities
+	}
+	return localityInfo
+}
+
+func retrieveHostStats(host *adminapi.HostStatus) map[string]uint64 {
+	stats := make(map[string]uint64)
+	for _, stat := range host.GetStats() {
+		stats[stat.GetName()] = stat.GetValue()
+	}
+	return stats
+}
+
 func retrieveEndpointAddress(host *adminapi.HostStatus) string {
 	addr := host.Address.GetSocketAddress()
 	if addr != nil {
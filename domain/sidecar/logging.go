@@ -0,0 +1,253 @@
+package sidecar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+	"github.com/shuxnhs/istio-dashboard/model"
+)
+
+// envoy 支持的日志级别
+var envoyLogLevels = map[string]bool{
+	"trace":    true,
+	"debug":    true,
+	"info":     true,
+	"warning":  true,
+	"error":    true,
+	"critical": true,
+	"off":      true,
+}
+
+type LogLevel struct {
+	Loggers  map[string]string `json:"loggers"`
+	RevertAt *time.Time        `json:"revertAt"`
+}
+
+const (
+	logRevertCheckPeriod = 10 * time.Second
+	// logRevertRetention 到期后超过该时间仍然恢复失败(如pod已经删除)时放弃
+	logRevertRetention = 24 * time.Hour
+)
+
+// logRevertsMu 同一个进程内修改日志级别和到期恢复串行执行, 避免读取和更新恢复记录之间被其他修改覆盖
+var logRevertsMu sync.Mutex
+
+// GetLogLevel 获取所有logger的日志级别
+func (s *Sidecar) GetLogLevel(namespace, pod string) (*LogLevel, error) {
+	out, err := s.EnvoyDo(context.TODO(), pod, namespace, http.MethodGet, "logging")
+	if err != nil {
+		return nil, err
+	}
+	logLevel := &LogLevel{Loggers: parseLogLevels(out)}
+
+	revert, err := model.LogLevelRevertDB.GetLogLevelRevert(s.cid, namespace, pod)
+	if err != nil && !errors.Is(err, model.LogLevelRevertNoExistErr) {
+		return nil, err
+	}
+	if revert != nil {
+		revertAt := time.Unix(revert.RevertTime, 0)
+		logLevel.RevertAt = &revertAt
+	}
+	return logLevel, nil
+}
+
+// SetLogLevel level 不为空时修改所有logger, loggers 修改指定logger;
+// revertAfter 大于0时到期自动恢复为修改前的级别, 为0时取消之前设置的自动恢复; 恢复记录保存在数据库中, 由 RunLogRevertController 执行
+func (s *Sidecar) SetLogLevel(namespace, pod, level string, loggers map[string]string, revertAfter time.Duration) (*LogLevel, error) {
+	if err := ValidateLogLevels(level, loggers); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.GetLogLevel(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.postLogLevel(namespace, pod, level, loggers); err != nil {
+		return nil, err
+	}
+	if err = s.saveLogRevert(namespace, pod, changedLoggers(previous.Loggers, level, loggers), revertAfter); err != nil {
+		return nil, err
+	}
+	return s.GetLogLevel(namespace, pod)
+}
+
+// saveLogRevert 多次修改时保留最早的级别, 恢复到第一次修改前的状态
+func (s *Sidecar) saveLogRevert(namespace, pod string, changed map[string]string, revertAfter time.Duration) error {
+	logRevertsMu.Lock()
+	defer logRevertsMu.Unlock()
+	revert, err := model.LogLevelRevertDB.GetLogLevelRevert(s.cid, namespace, pod)
+	if err != nil && !errors.Is(err, model.LogLevelRevertNoExistErr) {
+		return err
+	}
+	if revertAfter <= 0 {
+		if revert == nil {
+			return nil
+		}
+		_, err = model.LogLevelRevertDB.DeleteLogLevelRevert(revert.Id, 0)
+		return err
+	}
+
+	levels := changed
+	if revert != nil {
+		earliest := make(map[string]string)
+		if err = json.Unmarshal([]byte(revert.Levels), &earliest); err != nil {
+			return err
+		}
+		for name, lvl := range earliest {
+			levels[name] = lvl
+		}
+	}
+	data, err := json.Marshal(levels)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if revert != nil {
+		return model.LogLevelRevertDB.UpdateLogLevelRevert(revert.Id, map[string]interface{}{
+			"levels":      string(data),
+			"revert_time": now.Add(revertAfter).Unix(),
+			"update_time": now.Unix(),
+		})
+	}
+	return model.LogLevelRevertDB.CreateLogLevelRevert(&model.LogLevelRevert{
+		Cid:        s.cid,
+		Namespace:  namespace,
+		Pod:        pod,
+		Levels:     string(data),
+		RevertTime: now.Add(revertAfter).Unix(),
+		CreateTime: now.Unix(),
+		UpdateTime: now.Unix(),
+	})
+}
+
+// RunLogRevertController 启动时先恢复重启期间到期的日志级别, 之后定时检查; 恢复失败时保留记录, 下次继续重试
+func RunLogRevertController() {
+	revertDueLogLevels()
+	ticker := time.NewTicker(logRevertCheckPeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		revertDueLogLevels()
+	}
+}
+
+func revertDueLogLevels() {
+	reverts, err := model.LogLevelRevertDB.ListDueLogLevelRevert(time.Now().Unix())
+	if err != nil {
+		domainLog.Errorf("list due log level reverts err: %s", err)
+		return
+	}
+	for index := range *reverts {
+		revertLogLevel(&(*reverts)[index])
+	}
+}
+
+func revertLogLevel(revert *model.LogLevelRevert) {
+	logRevertsMu.Lock()
+	defer logRevertsMu.Unlock()
+	err := postLogRevert(revert)
+	if err != nil {
+		if time.Since(time.Unix(revert.RevertTime, 0)) < logRevertRetention {
+			domainLog.Warnf("revert log level of %s.%s err: %s, retrying", revert.Pod, revert.Namespace, err)
+			return
+		}
+		domainLog.Errorf("revert log level of %s.%s err: %s, giving up after %s", revert.Pod, revert.Namespace, err, logRevertRetention)
+	} else {
+		domainLog.Infof("revert log level of %s.%s to %s", revert.Pod, revert.Namespace, revert.Levels)
+	}
+	// 恢复时间被修改说明期间又修改了级别, 记录留给新的恢复时间
+	if _, err = model.LogLevelRevertDB.DeleteLogLevelRevert(revert.Id, revert.RevertTime); err != nil {
+		domainLog.Errorf("delete log level revert %d err: %s", revert.Id, err)
+	}
+}
+
+func postLogRevert(revert *model.LogLevelRevert) error {
+	levels := make(map[string]string)
+	if err := json.Unmarshal([]byte(revert.Levels), &levels); err != nil {
+		return err
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigByCid(revert.Cid)
+	if err != nil {
+		return err
+	}
+	s := NewSidecar(kubeConfig.Cid, kube.GetConfigStoreKubeConfig(kubeConfig))
+	return s.postLogLevel(revert.Namespace, revert.Pod, "", levels)
+}
+
+func (s *Sidecar) postLogLevel(namespace, pod, level string, loggers map[string]string) error {
+	if level != "" {
+		if _, err := s.EnvoyDo(context.TODO(), pod, namespace, http.MethodPost, "logging?level="+url.QueryEscape(level)); err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(loggers))
+	for name := range loggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := fmt.Sprintf("logging?%s=%s", url.QueryEscape(name), url.QueryEscape(loggers[name]))
+		if _, err := s.EnvoyDo(context.TODO(), pod, namespace, http.MethodPost, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ValidateLogLevels(level string, loggers map[string]string) error {
+	if level == "" && len(loggers) == 0 {
+		return fmt.Errorf("level or loggers is required")
+	}
+	if level != "" && !envoyLogLevels[level] {
+		return fmt.Errorf("unrecognized logging level: %s", level)
+	}
+	for name, lvl := range loggers {
+		if !envoyLogLevels[lvl] {
+			return fmt.Errorf("unrecognized logging level of %s: %s", name, lvl)
+		}
+	}
+	return nil
+}
+
+// changedLoggers 返回将被修改的logger及其修改前的级别
+func changedLoggers(previous map[string]string, level string, loggers map[string]string) map[string]string {
+	changed := make(map[string]string)
+	for name, lvl := range previous {
+		if _, ok := loggers[name]; ok || level != "" {
+			changed[name] = lvl
+		}
+	}
+	return changed
+}
+
+// parseLogLevels 解析envoy /logging 的输出
+//
+//	active loggers:
+//	  admin: info
+//	  router: debug
+func parseLogLevels(out []byte) map[string]string {
+	levels := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		pieces := strings.SplitN(line, ":", 2)
+		if len(pieces) != 2 || strings.TrimSpace(pieces[1]) == "" {
+			continue
+		}
+		levels[strings.TrimSpace(pieces[0])] = strings.TrimSpace(pieces[1])
+	}
+	return levels
+}
@@ -0,0 +1,112 @@
+package sidecar
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// bug-report 中边车信息的目录: bug-report/proxies/{namespace}/{pod}/config_dump?include_eds
+const bugReportProxiesDir = "proxies"
+
+var ErrConfigDumpTooLarge = errors.New("config dump exceeds the upload size limit")
+
+// OfflineConfigDump 上传的config_dump, 来自bug-report时包含所属的pod
+type OfflineConfigDump struct {
+	Namespace string
+	Pod       string
+	Config    []byte
+}
+
+// OfflineViews 离线config_dump的CDS/LDS/RDS/EDS视图, 与在线的视图一致
+type OfflineViews struct {
+	CDS []CDS   `json:"cds"`
+	LDS []LDS   `json:"lds"`
+	RDS []RDS   `json:"rds"`
+	EDS EDSInfo `json:"eds"`
+}
+
+// ParseOfflineConfigDump 解析上传的文件, 支持config_dump的json以及 istioctl bug-report 的tar.gz包;
+// bug-report中解压后的config_dump总大小超过 maxSize 时返回 ErrConfigDumpTooLarge
+func ParseOfflineConfigDump(data []byte, maxSize int64) ([]OfflineConfigDump, error) {
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		return parseBugReport(data, maxSize)
+	}
+	if _, err := NewConfigDump(data); err != nil {
+		return nil, err
+	}
+	return []OfflineConfigDump{{Config: data}}, nil
+}
+
+func parseBugReport(data []byte, maxSize int64) ([]OfflineConfigDump, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	dumps := make([]OfflineConfigDump, 0)
+	// remaining 剩余可以解压的大小, 避免大量小文件累计占用过多内存
+	remaining := maxSize
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasPrefix(path.Base(header.Name), "config_dump") {
+			continue
+		}
+		namespace, pod, ok := parseBugReportProxyPath(header.Name)
+		if !ok {
+			continue
+		}
+		if header.Size > remaining {
+			return nil, ErrConfigDumpTooLarge
+		}
+		config, err := io.ReadAll(io.LimitReader(tr, remaining+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(config)) > remaining {
+			return nil, ErrConfigDumpTooLarge
+		}
+		remaining -= int64(len(config))
+		if _, err = NewConfigDump(config); err != nil {
+			domainLog.Warnf("skip invalid config dump %s in bug report: %s", header.Name, err)
+			continue
+		}
+		dumps = append(dumps, OfflineConfigDump{Namespace: namespace, Pod: pod, Config: config})
+	}
+	if len(dumps) == 0 {
+		return nil, errors.New("no proxy config dump found in bug report")
+	}
+	return dumps, nil
+}
+
+// parseBugReportProxyPath 从 .../proxies/{namespace}/{pod}/config_dump 中解析命名空间和pod
+func parseBugReportProxyPath(name string) (string, string, bool) {
+	pieces := strings.Split(path.Clean(name), "/")
+	for i := len(pieces) - 4; i >= 0; i-- {
+		if pieces[i] == bugReportProxiesDir {
+			return pieces[i+1], pieces[i+2], true
+		}
+	}
+	return "", "", false
+}
+
+func ConfigDumpToViews(configDump *ConfigDump) *OfflineViews {
+	return &OfflineViews{
+		CDS: ClustersToCDS(configDump),
+		LDS: ClustersToLDS(configDump),
+		RDS: ClustersToRDS(configDump),
+		EDS: EndpointsToEDSInfo(configDump),
+	}
+}
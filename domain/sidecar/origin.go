@@ -0,0 +1,364 @@
+package sidecar
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	httpConnectionManager "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/host"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	gatewayapi "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+const (
+	OriginTypeCluster            = "cluster"
+	OriginTypeListener           = "listener"
+	OriginTypeFilterChain        = "filterChain"
+	OriginTypeFilter             = "filter"
+	OriginTypeRouteConfiguration = "routeConfiguration"
+	OriginTypeRoute              = "route"
+)
+
+// ConfigSource 生成envoy配置的istio资源, dashboard中没有该资源的接口时 Link 为空
+type ConfigSource struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Link      string `json:"link"`
+}
+
+// ConfigOrigin envoy配置对象及生成它的istio资源, Sources 为空表示istio默认生成的配置
+type ConfigOrigin struct {
+	Type    string         `json:"type"`
+	Name    string         `json:"name"`
+	Parent  string         `json:"parent,omitempty"`
+	Sources []ConfigSource `json:"sources"`
+}
+
+// istio由网关API资源转换出的VirtualService和Gateway的名称
+var (
+	autogeneratedHTTPRoute = regexp.MustCompile(`^(.+)-\d+-` + constants.KubernetesGatewayName + `$`)
+	autogeneratedTLSRoute  = regexp.MustCompile(`^(.+)-tls-\d+-` + constants.KubernetesGatewayName + `$`)
+	autogeneratedTCPRoute  = regexp.MustCompile(`^(.+)-tcp-` + constants.KubernetesGatewayName + `$`)
+	autogeneratedGateway   = regexp.MustCompile(`^(.+)-` + constants.KubernetesGatewayName + `-.+$`)
+)
+
+// GetConfigOrigins envoyFilters 为边车所在命名空间和根命名空间的EnvoyFilter, 用于找到被EnvoyFilter修改的配置
+func (s *Sidecar) GetConfigOrigins(namespace, pod string, envoyFilters []*v1alpha3.EnvoyFilter) ([]ConfigOrigin, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return ConfigDumpToOrigins(configDump, envoyFilters), nil
+}
+
+func ConfigDumpToOrigins(configDump *ConfigDump, envoyFilters []*v1alpha3.EnvoyFilter) []ConfigOrigin {
+	patches := newEnvoyFilterIndex(configDump, envoyFilters)
+	origins := make([]ConfigOrigin, 0)
+
+	if clusters, err := configDump.GetClusters(); err == nil {
+		for _, c := range clusters {
+			origins = append(origins, ConfigOrigin{
+				Type:    OriginTypeCluster,
+				Name:    c.GetName(),
+				Sources: appendSource(patches.cluster(c.GetName()), metadataConfigSource(c.GetMetadata())),
+			})
+		}
+	}
+
+	if listeners, err := configDump.GetListeners(); err == nil {
+		for _, l := range listeners {
+			origins = append(origins, listenerOrigins(l, patches)...)
+		}
+	}
+
+	if routes, err := configDump.GetRouters(); err == nil {
+		for _, r := range routes {
+			origins = append(origins, ConfigOrigin{
+				Type:    OriginTypeRouteConfiguration,
+				Name:    r.GetName(),
+				Sources: patches.routeConfiguration(r.GetName()),
+			})
+			for _, vh := range r.GetVirtualHosts() {
+				for _, rt := range vh.GetRoutes() {
+					origins = append(origins, ConfigOrigin{
+						Type:    OriginTypeRoute,
+						Name:    rt.GetName(),
+						Parent:  fmt.Sprintf("%s/%s", r.GetName(), vh.GetName()),
+						Sources: appendSource(make([]ConfigSource, 0), metadataConfigSource(rt.GetMetadata())),
+					})
+				}
+			}
+		}
+	}
+	return origins
+}
+
+// listenerOrigins 监听器、带有来源的过滤器链, 以及监听器中的网络和HTTP过滤器(同名的只保留一个)
+func listenerOrigins(l *listener.Listener, patches *envoyFilterIndex) []ConfigOrigin {
+	origins := []ConfigOrigin{{
+		Type:    OriginTypeListener,
+		Name:    l.GetName(),
+		Sources: patches.listener(l.GetName()),
+	}}
+
+	chains := l.GetFilterChains()
+	if l.GetDefaultFilterChain() != nil {
+		chains = append(chains, l.GetDefaultFilterChain())
+	}
+	matches := retrieveListenerMatches(l)
+	filters := make(map[string]bool)
+	for i, chain := range chains {
+		if source := metadataConfigSource(chain.GetMetadata()); source != nil {
+			name := chain.GetName()
+			if name == "" && i < len(matches) {
+				name = matches[i].match
+			}
+			origins = append(origins, ConfigOrigin{
+				Type:    OriginTypeFilterChain,
+				Name:    name,
+				Parent:  l.GetName(),
+				Sources: []ConfigSource{*source},
+			})
+		}
+
+		for _, filter := range chain.GetFilters() {
+			names := []string{filter.GetName()}
+			if hcm := retrieveHTTPConnectionManager(filter); hcm != nil {
+				for _, httpFilter := range hcm.GetHttpFilters() {
+					names = append(names, httpFilter.GetName())
+				}
+			}
+			for _, name := range names {
+				if filters[name] {
+					continue
+				}
+				filters[name] = true
+				origins = append(origins, ConfigOrigin{
+					Type:    OriginTypeFilter,
+					Name:    name,
+					Parent:  l.GetName(),
+					Sources: patches.filter(name),
+				})
+			}
+		}
+	}
+	return origins
+}
+
+func retrieveHTTPConnectionManager(filter *listener.Filter) *httpConnectionManager.HttpConnectionManager {
+	if filter.GetName() != wellknown.HTTPConnectionManager || filter.GetTypedConfig() == nil {
+		return nil
+	}
+	hcm := &httpConnectionManager.HttpConnectionManager{}
+	filter.GetTypedConfig().TypeUrl = "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager"
+	if err := filter.GetTypedConfig().UnmarshalTo(hcm); err != nil {
+		return nil
+	}
+	return hcm
+}
+
+// LinkConfigOrigins 补充配置来源在dashboard中的访问路径, 离线导入的config_dump没有对应的集群, 不生成链接
+func LinkConfigOrigins(kubeConfigId int64, origins []ConfigOrigin) {
+	for _, origin := range origins {
+		linkSources(kubeConfigId, origin.Sources)
+	}
+}
+
+func linkSources(kubeConfigId int64, sources []ConfigSource) {
+	for index := range sources {
+		source := &sources[index]
+		source.Link = istio.ResourceLink(kubeConfigId, source.Group, source.Kind, source.Namespace, source.Name)
+	}
+}
+
+// metadataConfigSource 解析istio写入的 filter_metadata.istio.config
+func metadataConfigSource(metadata *core.Metadata) *ConfigSource {
+	istioMetadata, ok := metadata.GetFilterMetadata()[util.IstioMetadataKey]
+	if !ok {
+		return nil
+	}
+	config, ok := istioMetadata.GetFields()["config"]
+	if !ok {
+		return nil
+	}
+	return parseConfigSource(config.GetStringValue())
+}
+
+// parseConfigSource 解析 /apis/{group}/{version}/namespaces/{namespace}/{kebab-kind}/{name},
+// istio由网关API转换出的资源还原为原始的HTTPRoute/TCPRoute/TLSRoute/Gateway
+func parseConfigSource(configPath string) *ConfigSource {
+	pieces := strings.Split(configPath, "/")
+	if len(pieces) != 8 || pieces[1] != "apis" || pieces[4] != "namespaces" {
+		return nil
+	}
+	source := &ConfigSource{
+		Group:     pieces[2],
+		Version:   pieces[3],
+		Kind:      kebabToKind(pieces[6]),
+		Namespace: pieces[5],
+		Name:      pieces[7],
+	}
+
+	if strings.Contains(source.Name, constants.KubernetesGatewayName) {
+		var kind string
+		var name []string
+		switch source.Kind {
+		case "VirtualService":
+			if name = autogeneratedTLSRoute.FindStringSubmatch(source.Name); name != nil {
+				kind = "TLSRoute"
+			} else if name = autogeneratedTCPRoute.FindStringSubmatch(source.Name); name != nil {
+				kind = "TCPRoute"
+			} else if name = autogeneratedHTTPRoute.FindStringSubmatch(source.Name); name != nil {
+				kind = "HTTPRoute"
+			}
+		case "Gateway":
+			if name = autogeneratedGateway.FindStringSubmatch(source.Name); name != nil {
+				kind = "Gateway"
+			}
+		}
+		if kind != "" {
+			source.Group = gatewayapi.GroupName
+			source.Version = gatewayapi.GroupVersion.Version
+			source.Kind = kind
+			source.Name = name[1]
+		}
+	}
+	return source
+}
+
+// kebabToKind virtual-service -> VirtualService
+func kebabToKind(kebab string) string {
+	var kind strings.Builder
+	for _, piece := range strings.Split(kebab, "-") {
+		if piece == "" {
+			continue
+		}
+		kind.WriteString(strings.ToUpper(piece[:1]) + piece[1:])
+	}
+	return kind.String()
+}
+
+func appendSource(sources []ConfigSource, source *ConfigSource) []ConfigSource {
+	if source != nil {
+		sources = append(sources, *source)
+	}
+	return sources
+}
+
+// envoyFilterIndex 按照被修改的配置对EnvoyFilter建立索引
+type envoyFilterIndex struct {
+	clusters            map[string][]ConfigSource
+	listeners           map[string][]ConfigSource
+	filters             map[string][]ConfigSource
+	routeConfigurations map[string][]ConfigSource
+}
+
+// newEnvoyFilterIndex 只保留对该边车生效的EnvoyFilter: 根命名空间或边车所在命名空间, 且workloadSelector匹配
+func newEnvoyFilterIndex(configDump *ConfigDump, envoyFilters []*v1alpha3.EnvoyFilter) *envoyFilterIndex {
+	index := &envoyFilterIndex{
+		clusters:            make(map[string][]ConfigSource),
+		listeners:           make(map[string][]ConfigSource),
+		filters:             make(map[string][]ConfigSource),
+		routeConfigurations: make(map[string][]ConfigSource),
+	}
+	bootstrap, err := ConfigDumpToBootstrap(configDump)
+	if err != nil {
+		return index
+	}
+
+	for _, ef := range envoyFilters {
+		if ef.Namespace != bootstrap.Namespace && ef.Namespace != istioNamespace {
+			continue
+		}
+		if selector := ef.Spec.GetWorkloadSelector().GetLabels(); len(selector) > 0 &&
+			!k8slabels.SelectorFromSet(selector).Matches(k8slabels.Set(bootstrap.Labels)) {
+			continue
+		}
+		source := ConfigSource{
+			Group:     v1alpha3.SchemeGroupVersion.Group,
+			Version:   v1alpha3.SchemeGroupVersion.Version,
+			Kind:      "EnvoyFilter",
+			Namespace: ef.Namespace,
+			Name:      ef.Name,
+		}
+		for _, patch := range ef.Spec.GetConfigPatches() {
+			index.add(patch, source)
+		}
+	}
+	return index
+}
+
+func (e *envoyFilterIndex) add(patch *networking.EnvoyFilter_EnvoyConfigObjectPatch, source ConfigSource) {
+	match := patch.GetMatch()
+	valueName := patch.GetPatch().GetValue().GetFields()["name"].GetStringValue()
+	switch patch.GetApplyTo() {
+	case networking.EnvoyFilter_CLUSTER:
+		cluster := match.GetCluster()
+		if cluster.GetName() != "" {
+			e.clusters[cluster.GetName()] = append(e.clusters[cluster.GetName()], source)
+		} else if cluster.GetService() != "" && cluster.GetPortNumber() != 0 {
+			name := model.BuildSubsetKey(model.TrafficDirectionOutbound, cluster.GetSubset(),
+				host.Name(cluster.GetService()), int(cluster.GetPortNumber()))
+			e.clusters[name] = append(e.clusters[name], source)
+		}
+		if patch.GetPatch().GetOperation() == networking.EnvoyFilter_Patch_ADD && valueName != "" {
+			e.clusters[valueName] = append(e.clusters[valueName], source)
+		}
+	case networking.EnvoyFilter_LISTENER:
+		if name := match.GetListener().GetName(); name != "" {
+			e.listeners[name] = append(e.listeners[name], source)
+		}
+		if patch.GetPatch().GetOperation() == networking.EnvoyFilter_Patch_ADD && valueName != "" {
+			e.listeners[valueName] = append(e.listeners[valueName], source)
+		}
+	case networking.EnvoyFilter_NETWORK_FILTER, networking.EnvoyFilter_HTTP_FILTER:
+		filter := match.GetListener().GetFilterChain().GetFilter()
+		name := filter.GetName()
+		if patch.GetApplyTo() == networking.EnvoyFilter_HTTP_FILTER {
+			name = filter.GetSubFilter().GetName()
+		}
+		switch patch.GetPatch().GetOperation() {
+		case networking.EnvoyFilter_Patch_MERGE, networking.EnvoyFilter_Patch_REPLACE:
+			if name != "" {
+				e.filters[name] = append(e.filters[name], source)
+			}
+		}
+		// 插入的新过滤器
+		if valueName != "" && valueName != name {
+			e.filters[valueName] = append(e.filters[valueName], source)
+		}
+	case networking.EnvoyFilter_ROUTE_CONFIGURATION, networking.EnvoyFilter_VIRTUAL_HOST, networking.EnvoyFilter_HTTP_ROUTE:
+		if name := match.GetRouteConfiguration().GetName(); name != "" {
+			e.routeConfigurations[name] = append(e.routeConfigurations[name], source)
+		}
+	}
+}
+
+func (e *envoyFilterIndex) cluster(name string) []ConfigSource {
+	return append(make([]ConfigSource, 0), e.clusters[name]...)
+}
+
+func (e *envoyFilterIndex) listener(name string) []ConfigSource {
+	return append(make([]ConfigSource, 0), e.listeners[name]...)
+}
+
+func (e *envoyFilterIndex) filter(name string) []ConfigSource {
+	return append(make([]ConfigSource, 0), e.filters[name]...)
+}
+
+func (e *envoyFilterIndex) routeConfiguration(name string) []ConfigSource {
+	return append(make([]ConfigSource, 0), e.routeConfigurations[name]...)
+}
@@ -94,13 +94,10 @@ func describeManagement(metadata *core.Metadata) string {
 	return renderConfig(config.GetStringValue())
 }
 
+// renderConfig 返回 name.namespace, 支持所有版本的istio资源以及网关API资源
 func renderConfig(configPath string) string {
-	if strings.HasPrefix(configPath, "/apis/networking.istio.io/v1alpha3/namespaces/") {
-		pieces := strings.Split(configPath, "/")
-		if len(pieces) != 8 {
-			return ""
-		}
-		return fmt.Sprintf("%s.%s", pieces[7], pieces[5])
+	if source := parseConfigSource(configPath); source != nil {
+		return fmt.Sprintf("%s.%s", source.Name, source.Namespace)
 	}
 	return "<unknown>"
 }
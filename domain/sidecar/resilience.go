@@ -0,0 +1,314 @@
+package sidecar
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+)
+
+// PolicyMismatch envoy中与策略不一致的字段, Field 为istio资源中的字段路径
+type PolicyMismatch struct {
+	Field    string      `json:"field"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+}
+
+// ClusterPolicySync 服务的出站集群是否已经使用 DestinationRule 中的连接池和异常检测
+type ClusterPolicySync struct {
+	Name            string           `json:"name"`
+	Port            int              `json:"port"`
+	DestinationRule string           `json:"destinationRule"`
+	Synced          bool             `json:"synced"`
+	Mismatches      []PolicyMismatch `json:"mismatches"`
+	Unverified      []string         `json:"unverified"`
+}
+
+// RoutePolicySync 路由到服务的envoy路由是否已经使用 VirtualService 中的重试和超时
+type RoutePolicySync struct {
+	RouteConfig string           `json:"routeConfig"`
+	VirtualHost string           `json:"virtualHost"`
+	Name        string           `json:"name"`
+	Synced      bool             `json:"synced"`
+	Mismatches  []PolicyMismatch `json:"mismatches"`
+	Unverified  []string         `json:"unverified"`
+}
+
+// ResilienceSync 边车中服务的集群和路由都与策略一致时 Synced 为true, 策略没有设置(包括已删除)的字段与istio生成的默认值比较;
+// 默认值来自istiod全局配置的字段(连接超时、路由超时)与内置默认值不同时无法确认是否生效, 放在 Unverified 中, 此时 Synced 为false;
+// maxRequestsPerConnection 和 idleTimeout 在集群的协议选项中, 端口级别和subset的策略可能覆盖服务的策略, 都不做比较;
+// 边车中没有服务的集群和路由时无法确认, Synced 为false, 原因放在 Reason 中
+type ResilienceSync struct {
+	Synced   bool                `json:"synced"`
+	Reason   string              `json:"reason,omitempty"`
+	Clusters []ClusterPolicySync `json:"clusters"`
+	Routes   []RoutePolicySync   `json:"routes"`
+}
+
+// CheckResilience 检查边车是否已经收到服务当前的弹性策略, 边车通常为调用该服务的客户端
+func (s *Sidecar) CheckResilience(namespace, pod string, policy *istio.ResiliencePolicy) (*ResilienceSync, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return CheckResilienceConfigDump(configDump, policy), nil
+}
+
+func CheckResilienceConfigDump(configDump *ConfigDump, policy *istio.ResiliencePolicy) *ResilienceSync {
+	serviceHost := host.Name(fmt.Sprintf("%s.%s.svc.cluster.local", policy.Service, policy.Namespace))
+	sync := &ResilienceSync{Synced: true, Clusters: make([]ClusterPolicySync, 0), Routes: make([]RoutePolicySync, 0)}
+
+	// ClustersToCDS 与 GetClusters 的顺序一致
+	clusters, _ := configDump.GetClusters()
+	for index, cds := range ClustersToCDS(configDump) {
+		if cds.Direction != model.TrafficDirectionOutbound || cds.FQDN != serviceHost || cds.Subset != "-" {
+			continue
+		}
+		clusterSync := ClusterPolicySync{
+			Name:            clusters[index].GetName(),
+			Port:            cds.Port,
+			DestinationRule: cds.DestinationRule,
+		}
+		check := newPolicyCheck()
+		expected := ""
+		if policy.DestinationRule != "" {
+			expected = fmt.Sprintf("%s.%s", policy.DestinationRule, policy.Namespace)
+		}
+		if cds.DestinationRule != expected {
+			check.mismatch("destinationRule", expected, cds.DestinationRule)
+		}
+		check.connectionPool(policy.ConnectionPool, clusters[index])
+		check.outlierDetection(policy.OutlierDetection, clusters[index])
+		clusterSync.Mismatches, clusterSync.Unverified = check.mismatches, check.unverified
+		clusterSync.Synced = check.synced()
+		sync.Synced = sync.Synced && clusterSync.Synced
+		sync.Clusters = append(sync.Clusters, clusterSync)
+	}
+
+	routeConfigs, _ := configDump.GetRouters()
+	for _, routeConfig := range routeConfigs {
+		for _, vh := range routeConfig.GetVirtualHosts() {
+			for _, rt := range vh.GetRoutes() {
+				routePolicy, ok := matchRoutePolicy(rt, policy, serviceHost)
+				if !ok {
+					continue
+				}
+				check := newPolicyCheck()
+				check.routePolicy(routePolicy, rt.GetRoute())
+				routeSync := RoutePolicySync{
+					RouteConfig: routeConfig.GetName(),
+					VirtualHost: vh.GetName(),
+					Name:        rt.GetName(),
+					Synced:      check.synced(),
+					Mismatches:  check.mismatches,
+					Unverified:  check.unverified,
+				}
+				sync.Synced = sync.Synced && routeSync.Synced
+				sync.Routes = append(sync.Routes, routeSync)
+			}
+		}
+	}
+	if len(sync.Clusters) == 0 && len(sync.Routes) == 0 {
+		sync.Synced = false
+		sync.Reason = fmt.Sprintf("no outbound cluster or route for %s in the config dump, "+
+			"the sidecar may not have received the service or is out of its sidecar egress scope", serviceHost)
+	}
+	return sync
+}
+
+// matchRoutePolicy 找到envoy路由对应的 VirtualService 路由, istio生成的路由名为 http路由名 或 http路由名.匹配条件名;
+// 没有名称时只有一条路由到该服务的http路由才能对应上
+func matchRoutePolicy(rt *route.Route, policy *istio.ResiliencePolicy, serviceHost host.Name) (istio.RoutePolicy, bool) {
+	if !routesToHost(rt.GetRoute(), serviceHost) {
+		return istio.RoutePolicy{}, false
+	}
+	source := metadataConfigSource(rt.GetMetadata())
+	if source == nil || source.Kind != "VirtualService" || source.Name != policy.VirtualService || source.Namespace != policy.Namespace {
+		return istio.RoutePolicy{}, false
+	}
+	for _, routePolicy := range policy.Routes {
+		if routePolicy.Name != "" && (rt.GetName() == routePolicy.Name || strings.HasPrefix(rt.GetName(), routePolicy.Name+".")) {
+			return routePolicy, true
+		}
+	}
+	if len(policy.Routes) == 1 && policy.Routes[0].Name == "" {
+		return policy.Routes[0], true
+	}
+	return istio.RoutePolicy{}, false
+}
+
+func routesToHost(action *route.RouteAction, serviceHost host.Name) bool {
+	names := make([]string, 0)
+	if action.GetCluster() != "" {
+		names = append(names, action.GetCluster())
+	}
+	for _, weighted := range action.GetWeightedClusters().GetClusters() {
+		names = append(names, weighted.GetName())
+	}
+	if len(names) == 0 {
+		return false
+	}
+	for _, name := range names {
+		direction, _, fqdn, _ := parseClusterName(name)
+		if direction != model.TrafficDirectionOutbound || fqdn != serviceHost {
+			return false
+		}
+	}
+	return true
+}
+
+// istio生成envoy配置时使用的默认值, 连接超时和路由超时可以被istiod的全局配置修改
+const (
+	defaultThresholdValue = math.MaxUint32
+	defaultRetryAttempts  = 2
+	defaultConnectTimeout = 10 * time.Second
+	defaultRouteTimeout   = 0
+)
+
+// policyCheck 收集一个集群或一条路由与策略比较的结果
+type policyCheck struct {
+	mismatches []PolicyMismatch
+	unverified []string
+}
+
+func newPolicyCheck() *policyCheck {
+	return &policyCheck{mismatches: make([]PolicyMismatch, 0), unverified: make([]string, 0)}
+}
+
+func (c *policyCheck) synced() bool {
+	return len(c.mismatches) == 0 && len(c.unverified) == 0
+}
+
+func (c *policyCheck) mismatch(field string, expected, actual interface{}) {
+	c.mismatches = append(c.mismatches, PolicyMismatch{Field: field, Expected: expected, Actual: actual})
+}
+
+// connectionPool 没有连接池时istio不设置熔断阈值; 有连接池时没有设置的阈值为istio的默认值
+func (c *policyCheck) connectionPool(pool *istio.ConnectionPool, cl *cluster.Cluster) {
+	threshold := defaultThreshold(cl.GetCircuitBreakers())
+	if pool == nil {
+		if threshold != nil {
+			c.mismatch("connectionPool", nil, threshold)
+		}
+		c.defaultDuration("connectionPool.tcp.connectTimeout", defaultConnectTimeout, cl.GetConnectTimeout())
+		return
+	}
+	tcp, http := pool.Tcp, pool.Http
+	if tcp == nil {
+		tcp = &istio.TCPSettings{}
+	}
+	if http == nil {
+		http = &istio.HTTPSettings{}
+	}
+	c.threshold("connectionPool.tcp.maxConnections", tcp.MaxConnections, threshold.GetMaxConnections().GetValue())
+	if tcp.ConnectTimeout == "" {
+		c.defaultDuration("connectionPool.tcp.connectTimeout", defaultConnectTimeout, cl.GetConnectTimeout())
+	} else {
+		c.duration("connectionPool.tcp.connectTimeout", tcp.ConnectTimeout, cl.GetConnectTimeout())
+	}
+	c.threshold("connectionPool.http.http1MaxPendingRequests", http.Http1MaxPendingRequests, threshold.GetMaxPendingRequests().GetValue())
+	c.threshold("connectionPool.http.http2MaxRequests", http.Http2MaxRequests, threshold.GetMaxRequests().GetValue())
+	c.threshold("connectionPool.http.maxRetries", http.MaxRetries, threshold.GetMaxRetries().GetValue())
+}
+
+// outlierDetection 没有异常检测时集群没有 outlier_detection; 有异常检测时没有设置的字段istio也不设置
+func (c *policyCheck) outlierDetection(od *istio.OutlierDetection, cl *cluster.Cluster) {
+	outlier := cl.GetOutlierDetection()
+	if od == nil {
+		if outlier != nil {
+			c.mismatch("outlierDetection", nil, outlier)
+		}
+		return
+	}
+	c.uint("outlierDetection.consecutive5xxErrors", od.Consecutive5xxErrors, outlier.GetConsecutive_5Xx().GetValue())
+	c.uint("outlierDetection.consecutiveGatewayErrors", od.ConsecutiveGatewayErrors, outlier.GetConsecutiveGatewayFailure().GetValue())
+	c.duration("outlierDetection.interval", od.Interval, outlier.GetInterval())
+	c.duration("outlierDetection.baseEjectionTime", od.BaseEjectionTime, outlier.GetBaseEjectionTime())
+	c.uint("outlierDetection.maxEjectionPercent", uint32(od.MaxEjectionPercent), outlier.GetMaxEjectionPercent().GetValue())
+	actual := cl.GetCommonLbConfig().GetHealthyPanicThreshold().GetValue()
+	if actual != float64(od.MinHealthPercent) {
+		c.mismatch("outlierDetection.minHealthPercent", od.MinHealthPercent, actual)
+	}
+}
+
+// routePolicy 重试条件中的状态码会被istio拆分到 retriable_status_codes, 只比较重试次数和每次的超时;
+// 没有设置重试时istio使用默认的重试策略
+func (c *policyCheck) routePolicy(routePolicy istio.RoutePolicy, action *route.RouteAction) {
+	retries := routePolicy.Retries
+	if retries == nil {
+		retries = &istio.RetryPolicy{Attempts: defaultRetryAttempts}
+	}
+	c.uint("retries.attempts", uint32(retries.Attempts), action.GetRetryPolicy().GetNumRetries().GetValue())
+	if retries.Attempts > 0 {
+		c.duration("retries.perTryTimeout", retries.PerTryTimeout, action.GetRetryPolicy().GetPerTryTimeout())
+	}
+	if routePolicy.Timeout == "" {
+		c.defaultDuration("timeout", defaultRouteTimeout, action.GetTimeout())
+	} else {
+		c.duration("timeout", routePolicy.Timeout, action.GetTimeout())
+	}
+}
+
+// defaultThreshold istio只设置默认优先级的熔断阈值
+func defaultThreshold(circuitBreakers *cluster.CircuitBreakers) *cluster.CircuitBreakers_Thresholds {
+	for _, threshold := range circuitBreakers.GetThresholds() {
+		if threshold.GetPriority() == core.RoutingPriority_DEFAULT {
+			return threshold
+		}
+	}
+	return nil
+}
+
+// threshold expected 为0时表示策略没有设置该阈值, istio使用默认值
+func (c *policyCheck) threshold(field string, expected int32, actual uint32) {
+	want := uint32(defaultThresholdValue)
+	if expected != 0 {
+		want = uint32(expected)
+	}
+	if want != actual {
+		c.mismatch(field, want, actual)
+	}
+}
+
+func (c *policyCheck) uint(field string, expected, actual uint32) {
+	if expected != actual {
+		c.mismatch(field, expected, actual)
+	}
+}
+
+// duration expected 为空时表示策略没有设置该字段, istio也不设置
+func (c *policyCheck) duration(field, expected string, actual *durationpb.Duration) {
+	want := time.Duration(0)
+	if expected != "" {
+		var err error
+		if want, err = time.ParseDuration(expected); err != nil {
+			return
+		}
+	}
+	if want != actual.AsDuration() {
+		c.mismatch(field, want.String(), durationString(actual))
+	}
+}
+
+// defaultDuration 策略没有设置的字段使用istiod的全局配置, 与内置默认值不同时可能是旧的配置也可能是修改过的全局配置
+func (c *policyCheck) defaultDuration(field string, defaultValue time.Duration, actual *durationpb.Duration) {
+	if actual.AsDuration() != defaultValue {
+		c.unverified = append(c.unverified, field)
+	}
+}
+
+func durationString(d *durationpb.Duration) string {
+	if d == nil {
+		return ""
+	}
+	return d.AsDuration().String()
+}
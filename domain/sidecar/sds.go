@@ -0,0 +1,115 @@
+package sidecar
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/golang/protobuf/ptypes/any"
+)
+
+const (
+	SecretTypeCertChain = "Cert Chain"
+	SecretTypeCA        = "CA"
+
+	SecretStatusActive  = "ACTIVE"
+	SecretStatusWarming = "WARMING"
+	SecretStatusStatic  = "STATIC"
+)
+
+// SDS 等同于 istioctl proxy-config secret
+type SDS struct {
+	Name            string    `json:"name"`
+	Type            string    `json:"type"`
+	Status          string    `json:"status"`
+	Identity        []string  `json:"identity"`
+	SerialNumber    string    `json:"serialNumber"`
+	Issuer          string    `json:"issuer"`
+	Subject         string    `json:"subject"`
+	NotBefore       time.Time `json:"notBefore"`
+	NotAfter        time.Time `json:"notAfter"`
+	DaysUntilExpiry int       `json:"daysUntilExpiry"`
+	Valid           bool      `json:"valid"`
+	Error           string    `json:"error,omitempty"`
+}
+
+func SecretsToSDS(configDump *ConfigDump) []SDS {
+	sds := make([]SDS, 0)
+	secretDump, err := configDump.GetSecretConfigDump()
+	if err != nil {
+		return sds
+	}
+	for _, s := range secretDump.StaticSecrets {
+		sds = append(sds, describeSecret(s.GetName(), SecretStatusStatic, s.GetSecret()))
+	}
+	for _, s := range secretDump.DynamicActiveSecrets {
+		sds = append(sds, describeSecret(s.GetName(), SecretStatusActive, s.GetSecret()))
+	}
+	for _, s := range secretDump.DynamicWarmingSecrets {
+		sds = append(sds, describeSecret(s.GetName(), SecretStatusWarming, s.GetSecret()))
+	}
+	return sds
+}
+
+func describeSecret(name, status string, secretAny *any.Any) SDS {
+	item := SDS{Name: name, Status: status, Identity: make([]string, 0)}
+	if secretAny == nil {
+		item.Error = "secret is empty"
+		return item
+	}
+	secret, err := NewSecret(secretAny)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+
+	var source *core.DataSource
+	if secret.GetTlsCertificate() != nil {
+		item.Type = SecretTypeCertChain
+		source = secret.GetTlsCertificate().GetCertificateChain()
+	} else if secret.GetValidationContext() != nil {
+		item.Type = SecretTypeCA
+		source = secret.GetValidationContext().GetTrustedCa()
+	}
+	data := source.GetInlineBytes()
+	if len(data) == 0 {
+		data = []byte(source.GetInlineString())
+	}
+
+	cert, err := parseFirstCertificate(data)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	for _, uri := range cert.URIs {
+		item.Identity = append(item.Identity, uri.String())
+	}
+	item.SerialNumber = fmt.Sprintf("%x", cert.SerialNumber)
+	item.Issuer = cert.Issuer.String()
+	item.Subject = cert.Subject.String()
+	item.NotBefore = cert.NotBefore
+	item.NotAfter = cert.NotAfter
+	item.DaysUntilExpiry = int(math.Floor(time.Until(cert.NotAfter).Hours() / 24))
+	item.Valid = time.Now().After(cert.NotBefore) && time.Now().Before(cert.NotAfter)
+	return item
+}
+
+// 证书链的第一个证书为工作负载证书, CA包的第一个证书为根证书
+func parseFirstCertificate(data []byte) (*x509.Certificate, error) {
+	for len(data) > 0 {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		return x509.ParseCertificate(block.Bytes)
+	}
+	return nil, errors.New("no certificate found in secret")
+}
@@ -2,6 +2,7 @@ package sidecar
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,61 +10,167 @@ import (
 
 	"github.com/shuxnhs/istio-dashboard/domain/kube"
 
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	"istio.io/istio/pkg/util/protomarshal"
+	"istio.io/pkg/log"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
 
 const istioNamespace = "istio-system"
 
+var domainLog = log.RegisterScope("sidecar-domain", "sidecar-domain debugging", 0)
+
 type Sidecar struct {
+	cid     string
 	config  *rest.Config
 	cli     *kubernetes.Clientset
 	restCli *rest.RESTClient
 }
 
-func NewSidecar(config *rest.Config) *Sidecar {
-	return &Sidecar{config: config, cli: kube.NewClientSet(config), restCli: kube.NewRestClient(config)}
+// NewSidecar cid 为集群id, 用于复用到该集群pod的端口转发
+func NewSidecar(cid string, config *rest.Config) *Sidecar {
+	return &Sidecar{cid: cid, config: config, cli: kube.NewClientSet(config), restCli: kube.NewRestClient(config)}
 }
 
-func (s *Sidecar) Check(namespace, pod string) {
-	//path := "config_dump"
-	//config, err := s.EnvoyDo(context.TODO(), pod, namespace, "GET", path)
-	//if err != nil {
-	//	return nil, err
-	//}
-	//
-	//path = fmt.Sprintf("/debug/config_dump?proxyID=%s.%s", pod, namespace)
-	//istiodDumps, err := s.AllDiscoveryDo(context.TODO(), istioNamespace, path)
+func (s *Sidecar) Check(namespace, pod string) (*SyncCheck, error) {
+	proxyID := fmt.Sprintf("%s.%s", pod, namespace)
+	config, err := s.EnvoyDo(context.TODO(), pod, namespace, "GET", "config_dump?include_eds")
+	if err != nil {
+		return nil, err
+	}
+	envoyDump, err := NewConfigDump(config)
+	if err != nil {
+		return nil, err
+	}
 
-	//statuses, err := s.AllDiscoveryDo(context.TODO(), istioNamespace, "/debug/syncz")
-	//if err != nil {
-	//
-	//}
+	proxyStatuses, err := s.GetProxyStatus(namespace, false)
+	if err != nil {
+		return nil, err
+	}
+	var proxyStatus *ProxyStatus
+	for i := range proxyStatuses {
+		if proxyStatuses[i].ProxyID == proxyID {
+			proxyStatus = &proxyStatuses[i]
+			break
+		}
+	}
 
+	path := fmt.Sprintf("/debug/config_dump?proxyID=%s", proxyID)
+	istiodDumps, err := s.AllDiscoveryDo(context.TODO(), istioNamespace, path)
+	if err != nil {
+		return nil, err
+	}
+	istiodDump, err := selectIstiodConfigDump(istiodDumps, proxyStatus)
+	if err != nil {
+		return nil, err
+	}
+	// istiod的config_dump不包含端点, 从edsz获取下发给代理的端点
+	path = fmt.Sprintf("/debug/edsz?proxyID=%s", proxyID)
+	istiodEndpointDumps, err := s.AllDiscoveryDo(context.TODO(), istioNamespace, path)
+	if err != nil {
+		return nil, err
+	}
+	istiodEndpoints, err := selectIstiodEndpoints(istiodEndpointDumps, proxyStatus)
+	if err != nil {
+		return nil, err
+	}
+	return CompareConfigDump(proxyID, proxyStatus, istiodDump, envoyDump, istiodEndpoints), nil
 }
 
-func (s *Sidecar) GetEDS(namespace, pod string) (EDSInfo, error) {
-	path := "clusters?format=json"
-	config, err := s.EnvoyDo(context.TODO(), pod, namespace, "GET", path)
+// 优先使用代理所连接的istiod的配置, 未连接的istiod返回的不是config_dump
+func selectIstiodConfigDump(istiodDumps map[string][]byte, proxyStatus *ProxyStatus) (*ConfigDump, error) {
+	if proxyStatus != nil {
+		if dump, ok := istiodDumps[proxyStatus.Istiod]; ok {
+			if configDump, err := NewConfigDump(dump); err == nil {
+				return configDump, nil
+			}
+		}
+	}
+	for _, dump := range istiodDumps {
+		if configDump, err := NewConfigDump(dump); err == nil {
+			return configDump, nil
+		}
+	}
+	return nil, errors.New("unable to find config dump in Istiod responses")
+}
+
+// selectIstiodEndpoints 与 selectIstiodConfigDump 相同, 优先使用代理所连接的istiod返回的端点
+func selectIstiodEndpoints(istiodDumps map[string][]byte, proxyStatus *ProxyStatus) ([]*endpoint.ClusterLoadAssignment, error) {
+	if proxyStatus != nil {
+		if dump, ok := istiodDumps[proxyStatus.Istiod]; ok {
+			if assignments, err := parseEdsz(dump); err == nil {
+				return assignments, nil
+			}
+		}
+	}
+	for _, dump := range istiodDumps {
+		if assignments, err := parseEdsz(dump); err == nil {
+			return assignments, nil
+		}
+	}
+	return nil, errors.New("unable to find endpoints in Istiod responses")
+}
+
+// parseEdsz 解析 /debug/edsz 返回的 ClusterLoadAssignment 数组
+func parseEdsz(dump []byte) ([]*endpoint.ClusterLoadAssignment, error) {
+	items := make([]json.RawMessage, 0)
+	if err := json.Unmarshal(dump, &items); err != nil {
+		return nil, err
+	}
+	assignments := make([]*endpoint.ClusterLoadAssignment, 0, len(items))
+	for _, item := range items {
+		assignment := &endpoint.ClusterLoadAssignment{}
+		if err := protomarshal.UnmarshalAllowUnknown(item, assignment); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments, nil
+}
+
+// GetProxyStatus 获取网格内所有代理的同步状态, 等同于 istioctl proxy-status
+func (s *Sidecar) GetProxyStatus(namespace string, staleOnly bool) ([]ProxyStatus, error) {
+	statuses, err := s.AllDiscoveryDo(context.TODO(), istioNamespace, "/debug/syncz")
 	if err != nil {
 		return nil, err
 	}
-	cluster, err := NewCluster(config)
+	proxyStatuses, err := SyncStatusesToProxyStatus(statuses)
+	if err != nil {
+		return nil, err
+	}
+	return FilterProxyStatus(proxyStatuses, namespace, staleOnly), nil
+}
+
+func (s *Sidecar) GetEDS(namespace, pod string) (EDSInfo, error) {
+	cluster, err := s.getClusters(namespace, pod)
 	if err != nil {
 		return nil, err
 	}
 	return ClustersToEDSInfo(cluster), nil
 }
 
-func (s *Sidecar) GetCDS(namespace, pod string) ([]CDS, error) {
-	path := "config_dump"
+func (s *Sidecar) GetEDSLocality(namespace, pod string) (EDSLocalityInfo, error) {
+	cluster, err := s.getClusters(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return ClustersToEDSLocality(cluster), nil
+}
+
+// getClusters 获取envoy /clusters 中的端点状态, 端点统计实时变化, 不做缓存
+func (s *Sidecar) getClusters(namespace, pod string) (*Cluster, error) {
+	path := "clusters?format=json"
 	config, err := s.EnvoyDo(context.TODO(), pod, namespace, "GET", path)
 	if err != nil {
 		return nil, err
 	}
-	configDump, err := NewConfigDump(config)
+	return NewCluster(config)
+}
+
+func (s *Sidecar) GetCDS(namespace, pod string) ([]CDS, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
 	if err != nil {
 		return nil, err
 	}
@@ -71,29 +178,47 @@ func (s *Sidecar) GetCDS(namespace, pod string) ([]CDS, error) {
 }
 
 func (s *Sidecar) GetLDS(namespace, pod string) ([]LDS, error) {
-	path := "config_dump"
-	config, err := s.EnvoyDo(context.TODO(), pod, namespace, "GET", path)
+	configDump, err := s.getConfigDump(namespace, pod)
 	if err != nil {
 		return nil, err
 	}
-	configDump, err := NewConfigDump(config)
+	return ClustersToLDS(configDump), nil
+}
+
+func (s *Sidecar) GetRDS(namespace, pod string) (interface{}, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
 	if err != nil {
 		return nil, err
 	}
-	return ClustersToLDS(configDump), nil
+	return ClustersToRDS(configDump), nil
 }
 
-func (s *Sidecar) GetRDS(namespace, pod string) (interface{}, error) {
-	path := "config_dump"
-	config, err := s.EnvoyDo(context.TODO(), pod, namespace, "GET", path)
+func (s *Sidecar) GetSDS(namespace, pod string) ([]SDS, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return SecretsToSDS(configDump), nil
+}
+
+func (s *Sidecar) GetBootstrap(namespace, pod string) (*Bootstrap, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
 	if err != nil {
 		return nil, err
 	}
-	configDump, err := NewConfigDump(config)
+	return ConfigDumpToBootstrap(configDump)
+}
+
+// getConfigDump 短时间内的重复请求使用缓存的config_dump
+func (s *Sidecar) getConfigDump(namespace, pod string) (*ConfigDump, error) {
+	key := configDumpKey{clusterID: s.cid, namespace: namespace, pod: pod}
+	config, err := configDumps.get(key, func() ([]byte, error) {
+		return s.EnvoyDo(context.TODO(), pod, namespace, "GET", "config_dump")
+	})
 	if err != nil {
 		return nil, err
 	}
-	return ClustersToRDS(configDump), nil
+	return NewConfigDump(config)
 }
 
 func (s *Sidecar) EnvoyDo(ctx context.Context, podName, podNamespace, method, path string) ([]byte, error) {
@@ -102,10 +227,8 @@ func (s *Sidecar) EnvoyDo(ctx context.Context, podName, podNamespace, method, pa
 
 func (s *Sidecar) AllDiscoveryDo(ctx context.Context, istiodNamespace, path string) (map[string][]byte, error) {
 	istiods, err := s.cli.CoreV1().Pods(istiodNamespace).List(ctx, metav1.ListOptions{
-		LabelSelector: fields.SelectorFromSet(map[string]string{
-			"labelSelector": "app=istiod",
-			"fieldSelector": "status.phase=Running",
-		}).String(),
+		LabelSelector: "app=istiod",
+		FieldSelector: "status.phase=Running",
 	})
 	if err != nil {
 		return nil, err
@@ -136,20 +259,19 @@ func (s *Sidecar) portForwardRequest(ctx context.Context, podName, podNamespace,
 		return fmt.Errorf("failure running port forward process: %v", err)
 	}
 
-	fw, err := kube.NewPortForwarder(s.config, podName, podNamespace, "127.0.0.1", 0, port)
+	key := kube.PortForwarderKey{ClusterID: s.cid, Namespace: podNamespace, Pod: podName, Port: port}
+	fw, err := kube.DefaultPortForwarderPool.Get(key, s.config)
 	if err != nil {
-		return nil, err
-	}
-	if err = fw.Start(); err != nil {
 		return nil, formatError(err)
 	}
-	defer fw.Close()
 	req, err := http.NewRequest(method, fmt.Sprintf("http://%s/%s", fw.Address(), path), nil)
 	if err != nil {
 		return nil, formatError(err)
 	}
 	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
+		// 端口转发可能已经失效(如pod重建), 移出连接池
+		kube.DefaultPortForwarderPool.Evict(key, fw)
 		return nil, formatError(err)
 	}
 	defer resp.Body.Close()
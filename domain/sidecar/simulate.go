@@ -0,0 +1,704 @@
+package sidecar
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	tcpProxy "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	SimulateStageListener           = "listener"
+	SimulateStageFilterChain        = "filterChain"
+	SimulateStageFilter             = "filter"
+	SimulateStageRouteConfiguration = "routeConfiguration"
+	SimulateStageVirtualHost        = "virtualHost"
+	SimulateStageRoute              = "route"
+
+	SimulateResultRouted         = "routed"
+	SimulateResultRedirect       = "redirect"
+	SimulateResultDirectResponse = "directResponse"
+	SimulateResultNoListener     = "noListener"
+	SimulateResultNoFilterChain  = "noFilterChain"
+	SimulateResultNoRoute        = "noRoute"
+	SimulateResultUnsupported    = "unsupported"
+
+	virtualOutboundListener = "virtualOutbound"
+)
+
+// SimulateRequest 模拟的请求, DestinationIP 为空时按 0.0.0.0_{port} 监听器处理, TLS 表示应用自己发起TLS, 按SNI(Host)匹配
+type SimulateRequest struct {
+	Host          string            `json:"host"`
+	Port          uint32            `json:"port"`
+	DestinationIP string            `json:"destinationIp"`
+	Path          string            `json:"path"`
+	Method        string            `json:"method"`
+	Headers       map[string]string `json:"headers"`
+	TLS           bool              `json:"tls"`
+	SourceLabels  map[string]string `json:"sourceLabels"`
+}
+
+// SimulateStep 请求经过的每一步及匹配到的istio资源
+type SimulateStep struct {
+	Stage   string         `json:"stage"`
+	Name    string         `json:"name"`
+	Detail  string         `json:"detail,omitempty"`
+	Sources []ConfigSource `json:"sources"`
+}
+
+type SimulateCluster struct {
+	Name      string           `json:"name"`
+	Weight    uint32           `json:"weight"`
+	Sources   []ConfigSource   `json:"sources"`
+	Endpoints []EDSClusterInfo `json:"endpoints"`
+}
+
+// SimulateResult 请求的完整路由路径: 监听器 -> 过滤器链 -> HCM -> 路由配置 -> 虚拟主机 -> 路由 -> 集群 -> 端点
+type SimulateResult struct {
+	Result   string            `json:"result"`
+	Steps    []SimulateStep    `json:"steps"`
+	Clusters []SimulateCluster `json:"clusters"`
+	Notes    []string          `json:"notes"`
+}
+
+// LinkSources 补充配置来源在dashboard中的访问路径
+func (r *SimulateResult) LinkSources(kubeConfigId int64) {
+	for _, step := range r.Steps {
+		linkSources(kubeConfigId, step.Sources)
+	}
+	for _, cluster := range r.Clusters {
+		linkSources(kubeConfigId, cluster.Sources)
+	}
+}
+
+// Simulate 模拟从该边车发出的请求会被路由到哪里
+func (s *Sidecar) Simulate(namespace, pod string, req SimulateRequest) (*SimulateResult, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := s.getClusters(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return SimulateRequestPath(configDump, ClustersToEDSInfo(clusters), req), nil
+}
+
+// SimulateRequestPath eds 为空时使用 config_dump 中的端点, 可用于离线的config_dump
+func SimulateRequestPath(configDump *ConfigDump, eds EDSInfo, req SimulateRequest) *SimulateResult {
+	sim := &simulation{
+		configDump: configDump,
+		eds:        eds,
+		req:        req,
+		result: &SimulateResult{
+			Steps:    make([]SimulateStep, 0),
+			Clusters: make([]SimulateCluster, 0),
+			Notes:    make([]string, 0),
+		},
+	}
+	if len(sim.eds) == 0 {
+		sim.eds = EndpointsToEDSInfo(configDump)
+	}
+	sim.checkSourceLabels()
+	sim.run()
+	return sim.result
+}
+
+type simulation struct {
+	configDump *ConfigDump
+	eds        EDSInfo
+	req        SimulateRequest
+	result     *SimulateResult
+}
+
+func (sim *simulation) step(stage, name, detail string, sources []ConfigSource) {
+	if sources == nil {
+		sources = make([]ConfigSource, 0)
+	}
+	sim.result.Steps = append(sim.result.Steps, SimulateStep{Stage: stage, Name: name, Detail: detail, Sources: sources})
+}
+
+func (sim *simulation) note(format string, args ...interface{}) {
+	sim.result.Notes = append(sim.result.Notes, fmt.Sprintf(format, args...))
+}
+
+// istio在生成配置时已经根据边车自身的标签处理了 sourceLabels, 只能提示与边车标签不一致
+func (sim *simulation) checkSourceLabels() {
+	if len(sim.req.SourceLabels) == 0 {
+		return
+	}
+	bootstrap, err := ConfigDumpToBootstrap(sim.configDump)
+	if err != nil {
+		return
+	}
+	if !k8slabels.SelectorFromSet(sim.req.SourceLabels).Matches(k8slabels.Set(bootstrap.Labels)) {
+		sim.note("source labels %v do not match the labels of this proxy %v, "+
+			"VirtualService sourceLabels are evaluated by istiod for the proxy's own labels", sim.req.SourceLabels, bootstrap.Labels)
+	}
+}
+
+func (sim *simulation) run() {
+	listeners, err := sim.configDump.GetListeners()
+	if err != nil {
+		sim.result.Result = SimulateResultNoListener
+		sim.note("%s", err)
+		return
+	}
+	l, detail := sim.selectListener(listeners)
+	if l == nil {
+		sim.result.Result = SimulateResultNoListener
+		return
+	}
+	sim.step(SimulateStageListener, l.GetName(), detail, nil)
+
+	chain := sim.selectFilterChain(l)
+	if chain == nil {
+		sim.result.Result = SimulateResultNoFilterChain
+		return
+	}
+	sim.step(SimulateStageFilterChain, chain.GetName(), describeFilterChainMatch(chain.GetFilterChainMatch()),
+		appendSource(nil, metadataConfigSource(chain.GetMetadata())))
+
+	for _, filter := range chain.GetFilters() {
+		if hcm := retrieveHTTPConnectionManager(filter); hcm != nil {
+			if hcm.GetRouteConfig() != nil {
+				sim.step(SimulateStageFilter, filter.GetName(), "inline route configuration", nil)
+				sim.routeHTTP(hcm.GetRouteConfig())
+				return
+			}
+			name := hcm.GetRds().GetRouteConfigName()
+			sim.step(SimulateStageFilter, filter.GetName(), fmt.Sprintf("RDS: %s", name), nil)
+			routes, _ := sim.configDump.GetRouters()
+			for _, rc := range routes {
+				if rc.GetName() == name {
+					sim.routeHTTP(rc)
+					return
+				}
+			}
+			sim.result.Result = SimulateResultNoRoute
+			sim.note("route configuration %s not found in config dump", name)
+			return
+		}
+		if filter.GetName() == wellknown.TCPProxy {
+			sim.routeTCP(filter)
+			return
+		}
+	}
+	sim.result.Result = SimulateResultUnsupported
+	sim.note("filter chain has no http_connection_manager or tcp_proxy filter")
+}
+
+// selectListener 优先匹配目标ip和端口的监听器, 其次 0.0.0.0_{port}, 都没有时流量由 virtualOutbound 处理
+func (sim *simulation) selectListener(listeners []*listener.Listener) (*listener.Listener, string) {
+	var wildcard, virtualOutbound *listener.Listener
+	for _, l := range listeners {
+		addr := l.GetAddress().GetSocketAddress()
+		if l.GetName() == virtualOutboundListener {
+			virtualOutbound = l
+			continue
+		}
+		if addr.GetPortValue() != sim.req.Port {
+			continue
+		}
+		if sim.req.DestinationIP != "" && addr.GetAddress() == sim.req.DestinationIP {
+			return l, fmt.Sprintf("matched %s:%d", sim.req.DestinationIP, sim.req.Port)
+		}
+		if addr.GetAddress() == "0.0.0.0" || addr.GetAddress() == "::" {
+			wildcard = l
+		}
+	}
+	if wildcard != nil {
+		return wildcard, fmt.Sprintf("matched wildcard address on port %d", sim.req.Port)
+	}
+	if virtualOutbound != nil {
+		return virtualOutbound, fmt.Sprintf("no listener on port %d, handled by %s", sim.req.Port, virtualOutboundListener)
+	}
+	sim.note("no listener found for port %d", sim.req.Port)
+	return nil, ""
+}
+
+// selectFilterChain 按envoy的顺序逐项筛选: 目标端口、目标ip、SNI、传输协议、应用协议, 每项优先选择最具体的匹配
+func (sim *simulation) selectFilterChain(l *listener.Listener) *listener.FilterChain {
+	transport, alpn := "raw_buffer", "http/1.1"
+	if sim.req.TLS {
+		transport, alpn = "tls", ""
+	}
+	ip := net.ParseIP(sim.req.DestinationIP)
+
+	chains := l.GetFilterChains()
+	criteria := []func(m *listener.FilterChainMatch) (int, bool){
+		func(m *listener.FilterChainMatch) (int, bool) {
+			if m.GetDestinationPort() == nil {
+				return 0, true
+			}
+			return 1, m.GetDestinationPort().GetValue() == sim.req.Port
+		},
+		func(m *listener.FilterChainMatch) (int, bool) {
+			if len(m.GetPrefixRanges()) == 0 {
+				return 0, true
+			}
+			best, ok := 0, false
+			for _, r := range m.GetPrefixRanges() {
+				_, cidr, err := net.ParseCIDR(fmt.Sprintf("%s/%d", r.GetAddressPrefix(), r.GetPrefixLen().GetValue()))
+				if err == nil && ip != nil && cidr.Contains(ip) {
+					ok = true
+					if size, _ := cidr.Mask.Size(); size+1 > best {
+						best = size + 1
+					}
+				}
+			}
+			return best, ok
+		},
+		func(m *listener.FilterChainMatch) (int, bool) {
+			if len(m.GetServerNames()) == 0 {
+				return 0, true
+			}
+			if !sim.req.TLS {
+				return 0, false
+			}
+			return matchServerNames(m.GetServerNames(), sim.req.Host)
+		},
+		func(m *listener.FilterChainMatch) (int, bool) {
+			if m.GetTransportProtocol() == "" {
+				return 0, true
+			}
+			return 1, m.GetTransportProtocol() == transport
+		},
+		func(m *listener.FilterChainMatch) (int, bool) {
+			if len(m.GetApplicationProtocols()) == 0 {
+				return 0, true
+			}
+			for _, p := range m.GetApplicationProtocols() {
+				if p == alpn {
+					return 1, true
+				}
+			}
+			return 0, false
+		},
+	}
+	for _, criterion := range criteria {
+		chains = narrowFilterChains(chains, criterion)
+	}
+	if len(chains) > 0 {
+		return chains[0]
+	}
+	if l.GetDefaultFilterChain() != nil {
+		sim.note("no filter chain matched, using default filter chain")
+		return l.GetDefaultFilterChain()
+	}
+	sim.note("no filter chain matched on listener %s", l.GetName())
+	return nil
+}
+
+// narrowFilterChains 只保留匹配程度最高的过滤器链
+func narrowFilterChains(chains []*listener.FilterChain, criterion func(m *listener.FilterChainMatch) (int, bool)) []*listener.FilterChain {
+	best := -1
+	matched := make([]*listener.FilterChain, 0)
+	for _, chain := range chains {
+		level, ok := criterion(chain.GetFilterChainMatch())
+		if !ok || level < best {
+			continue
+		}
+		if level > best {
+			best = level
+			matched = matched[:0]
+		}
+		matched = append(matched, chain)
+	}
+	return matched
+}
+
+// matchServerNames 精确匹配优先, 其次最长的通配符后缀
+func matchServerNames(serverNames []string, sni string) (int, bool) {
+	best, ok := 0, false
+	for _, name := range serverNames {
+		if name == sni {
+			return len(sni) + 1, true
+		}
+		if strings.HasPrefix(name, "*.") && strings.HasSuffix(sni, name[1:]) && len(name) > best {
+			best, ok = len(name), true
+		}
+	}
+	return best, ok
+}
+
+func describeFilterChainMatch(m *listener.FilterChainMatch) string {
+	if m == nil {
+		return "ALL"
+	}
+	descriptions := make([]string, 0)
+	if m.GetDestinationPort() != nil {
+		descriptions = append(descriptions, fmt.Sprintf("port: %d", m.GetDestinationPort().GetValue()))
+	}
+	if len(m.GetServerNames()) > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("SNI: %s", strings.Join(m.GetServerNames(), ",")))
+	}
+	if m.GetTransportProtocol() != "" {
+		descriptions = append(descriptions, fmt.Sprintf("Trans: %s", m.GetTransportProtocol()))
+	}
+	if len(m.GetApplicationProtocols()) > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("App: %s", strings.Join(m.GetApplicationProtocols(), ",")))
+	}
+	if len(descriptions) == 0 {
+		return "ALL"
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+func (sim *simulation) routeTCP(filter *listener.Filter) {
+	tp := &tcpProxy.TcpProxy{}
+	filter.GetTypedConfig().TypeUrl = "type.googleapis.com/envoy.extensions.filters.network.tcp_proxy.v3.TcpProxy"
+	if err := filter.GetTypedConfig().UnmarshalTo(tp); err != nil {
+		sim.result.Result = SimulateResultUnsupported
+		sim.note("%s", err)
+		return
+	}
+	sim.step(SimulateStageFilter, filter.GetName(), "TCP proxy", nil)
+	sim.result.Result = SimulateResultRouted
+	if tp.GetCluster() != "" {
+		sim.addCluster(tp.GetCluster(), 100)
+		return
+	}
+	for _, wc := range tp.GetWeightedClusters().GetClusters() {
+		sim.addCluster(wc.GetName(), wc.GetWeight())
+	}
+}
+
+func (sim *simulation) routeHTTP(rc *route.RouteConfiguration) {
+	sim.step(SimulateStageRouteConfiguration, rc.GetName(), "", nil)
+
+	authority := sim.req.Host
+	if h, ok := sim.header(":authority"); ok {
+		authority = h
+	}
+	vh := selectVirtualHost(rc.GetVirtualHosts(), authority)
+	if vh == nil {
+		sim.result.Result = SimulateResultNoRoute
+		sim.note("no virtual host in %s matches %s", rc.GetName(), authority)
+		return
+	}
+	sim.step(SimulateStageVirtualHost, vh.GetName(), strings.Join(vh.GetDomains(), ","), nil)
+
+	for _, r := range vh.GetRoutes() {
+		if !sim.matchRoute(r.GetMatch()) {
+			continue
+		}
+		sim.step(SimulateStageRoute, r.GetName(), describeRouteMatch(r.GetMatch()),
+			appendSource(nil, metadataConfigSource(r.GetMetadata())))
+		switch action := r.GetAction().(type) {
+		case *route.Route_Route:
+			sim.result.Result = SimulateResultRouted
+			sim.routeAction(action.Route)
+		case *route.Route_Redirect:
+			sim.result.Result = SimulateResultRedirect
+			sim.note("redirect to %s%s", action.Redirect.GetHostRedirect(), action.Redirect.GetPathRedirect())
+		case *route.Route_DirectResponse:
+			sim.result.Result = SimulateResultDirectResponse
+			sim.note("direct response with status %d", action.DirectResponse.GetStatus())
+		default:
+			sim.result.Result = SimulateResultUnsupported
+			sim.note("unsupported route action %T", action)
+		}
+		return
+	}
+	sim.result.Result = SimulateResultNoRoute
+	sim.note("no route in virtual host %s matches the request, envoy responds 404", vh.GetName())
+}
+
+func (sim *simulation) routeAction(action *route.RouteAction) {
+	switch specifier := action.GetClusterSpecifier().(type) {
+	case *route.RouteAction_Cluster:
+		sim.addCluster(specifier.Cluster, 100)
+	case *route.RouteAction_WeightedClusters:
+		for _, wc := range specifier.WeightedClusters.GetClusters() {
+			sim.addCluster(wc.GetName(), wc.GetWeight().GetValue())
+		}
+	case *route.RouteAction_ClusterHeader:
+		sim.note("cluster is taken from request header %s", specifier.ClusterHeader)
+	}
+	if action.GetTimeout() != nil {
+		sim.note("route timeout: %s", action.GetTimeout().AsDuration())
+	}
+	if policy := action.GetRetryPolicy(); policy != nil {
+		sim.note("retry: %d attempts on %s", policy.GetNumRetries().GetValue(), policy.GetRetryOn())
+	}
+	for _, mirror := range action.GetRequestMirrorPolicies() {
+		sim.note("mirrored to %s", mirror.GetCluster())
+	}
+}
+
+func (sim *simulation) addCluster(name string, weight uint32) {
+	var sources []ConfigSource
+	clusters, _ := sim.configDump.GetClusters()
+	for _, c := range clusters {
+		if c.GetName() == name {
+			sources = appendSource(nil, metadataConfigSource(c.GetMetadata()))
+			if c.GetType() != cluster.Cluster_EDS {
+				sim.note("cluster %s is of type %s", name, c.GetType())
+			}
+			break
+		}
+	}
+	if sources == nil {
+		sources = make([]ConfigSource, 0)
+	}
+	endpoints, ok := sim.eds[name]
+	if !ok {
+		endpoints = make([]EDSClusterInfo, 0)
+	}
+	sim.result.Clusters = append(sim.result.Clusters, SimulateCluster{
+		Name:      name,
+		Weight:    weight,
+		Sources:   sources,
+		Endpoints: endpoints,
+	})
+}
+
+// selectVirtualHost 与envoy一致: 精确匹配, 最长的后缀通配(*.foo), 最长的前缀通配(foo.*), 最后是 *
+func selectVirtualHost(vhosts []*route.VirtualHost, authority string) *route.VirtualHost {
+	authority = strings.ToLower(authority)
+	var suffix, prefix, catchAll *route.VirtualHost
+	suffixLen, prefixLen := 0, 0
+	for _, vh := range vhosts {
+		for _, domain := range vh.GetDomains() {
+			domain = strings.ToLower(domain)
+			switch {
+			case domain == authority:
+				return vh
+			case domain == "*":
+				if catchAll == nil {
+					catchAll = vh
+				}
+			case strings.HasPrefix(domain, "*") && strings.HasSuffix(authority, domain[1:]) && len(domain) > suffixLen:
+				suffix, suffixLen = vh, len(domain)
+			case strings.HasSuffix(domain, "*") && strings.HasPrefix(authority, domain[:len(domain)-1]) && len(domain) > prefixLen:
+				prefix, prefixLen = vh, len(domain)
+			}
+		}
+	}
+	if suffix != nil {
+		return suffix
+	}
+	if prefix != nil {
+		return prefix
+	}
+	return catchAll
+}
+
+func (sim *simulation) header(name string) (string, bool) {
+	switch name {
+	case ":authority", "host":
+		for k, v := range sim.req.Headers {
+			if strings.EqualFold(k, "host") || k == ":authority" {
+				return v, true
+			}
+		}
+		return "", false
+	case ":method":
+		if sim.req.Method == "" {
+			return "GET", true
+		}
+		return strings.ToUpper(sim.req.Method), true
+	case ":path":
+		return sim.requestPath(), true
+	case ":scheme":
+		return "http", true
+	}
+	for k, v := range sim.req.Headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func (sim *simulation) requestPath() string {
+	if sim.req.Path == "" {
+		return "/"
+	}
+	return sim.req.Path
+}
+
+func (sim *simulation) matchRoute(m *route.RouteMatch) bool {
+	fullPath := sim.requestPath()
+	path, rawQuery := fullPath, ""
+	if i := strings.Index(fullPath, "?"); i >= 0 {
+		path, rawQuery = fullPath[:i], fullPath[i+1:]
+	}
+	caseSensitive := m.GetCaseSensitive() == nil || m.GetCaseSensitive().GetValue()
+
+	switch specifier := m.GetPathSpecifier().(type) {
+	case *route.RouteMatch_Prefix:
+		if !hasPrefix(path, specifier.Prefix, caseSensitive) {
+			return false
+		}
+	case *route.RouteMatch_Path:
+		if !equal(path, specifier.Path, caseSensitive) {
+			return false
+		}
+	case *route.RouteMatch_SafeRegex:
+		if !fullMatch(specifier.SafeRegex.GetRegex(), path) {
+			return false
+		}
+	case *route.RouteMatch_PathSeparatedPrefix:
+		p := specifier.PathSeparatedPrefix
+		if !equal(path, p, caseSensitive) && !hasPrefix(path, p+"/", caseSensitive) {
+			return false
+		}
+	default:
+		sim.note("route match %T is not supported by the simulator", specifier)
+		return false
+	}
+
+	for _, hm := range m.GetHeaders() {
+		value, present := sim.header(hm.GetName())
+		if matchHeader(hm, value, present) == hm.GetInvertMatch() {
+			return false
+		}
+	}
+
+	query, _ := url.ParseQuery(rawQuery)
+	for _, qm := range m.GetQueryParameters() {
+		values, present := query[qm.GetName()]
+		switch specifier := qm.GetQueryParameterMatchSpecifier().(type) {
+		case *route.QueryParameterMatcher_PresentMatch:
+			if present != specifier.PresentMatch {
+				return false
+			}
+		case *route.QueryParameterMatcher_StringMatch:
+			if !present || !matchString(specifier.StringMatch, values[0]) {
+				return false
+			}
+		}
+	}
+	if m.GetRuntimeFraction() != nil {
+		sim.note("route %s has a runtime fraction, assumed to match", describeRouteMatch(m))
+	}
+	return true
+}
+
+func matchHeader(hm *route.HeaderMatcher, value string, present bool) bool {
+	switch specifier := hm.GetHeaderMatchSpecifier().(type) {
+	case *route.HeaderMatcher_PresentMatch:
+		return present == specifier.PresentMatch
+	case nil:
+		return present
+	}
+	if !present {
+		return false
+	}
+	switch specifier := hm.GetHeaderMatchSpecifier().(type) {
+	case *route.HeaderMatcher_ExactMatch:
+		return value == specifier.ExactMatch
+	case *route.HeaderMatcher_PrefixMatch:
+		return strings.HasPrefix(value, specifier.PrefixMatch)
+	case *route.HeaderMatcher_SuffixMatch:
+		return strings.HasSuffix(value, specifier.SuffixMatch)
+	case *route.HeaderMatcher_ContainsMatch:
+		return strings.Contains(value, specifier.ContainsMatch)
+	case *route.HeaderMatcher_SafeRegexMatch:
+		return fullMatch(specifier.SafeRegexMatch.GetRegex(), value)
+	case *route.HeaderMatcher_StringMatch:
+		return matchString(specifier.StringMatch, value)
+	case *route.HeaderMatcher_RangeMatch:
+		var n int64
+		if _, err := fmt.Sscanf(value, "%d", &n); err != nil {
+			return false
+		}
+		return n >= specifier.RangeMatch.GetStart() && n < specifier.RangeMatch.GetEnd()
+	}
+	return false
+}
+
+func matchString(m *matcher.StringMatcher, value string) bool {
+	caseSensitive := !m.GetIgnoreCase()
+	switch specifier := m.GetMatchPattern().(type) {
+	case *matcher.StringMatcher_Exact:
+		return equal(value, specifier.Exact, caseSensitive)
+	case *matcher.StringMatcher_Prefix:
+		return hasPrefix(value, specifier.Prefix, caseSensitive)
+	case *matcher.StringMatcher_Suffix:
+		if caseSensitive {
+			return strings.HasSuffix(value, specifier.Suffix)
+		}
+		return strings.HasSuffix(strings.ToLower(value), strings.ToLower(specifier.Suffix))
+	case *matcher.StringMatcher_Contains:
+		if caseSensitive {
+			return strings.Contains(value, specifier.Contains)
+		}
+		return strings.Contains(strings.ToLower(value), strings.ToLower(specifier.Contains))
+	case *matcher.StringMatcher_SafeRegex:
+		return fullMatch(specifier.SafeRegex.GetRegex(), value)
+	}
+	return false
+}
+
+// 编译后的正则按表达式缓存, 无效的表达式缓存为nil; 超过上限时整体清空, 避免无限增长
+const regexCacheLimit = 1024
+
+var (
+	regexCacheMu sync.Mutex
+	regexCache   = make(map[string]*regexp.Regexp)
+)
+
+// fullMatch envoy的正则需要完整匹配, RE2语法与go一致
+func fullMatch(expr, value string) bool {
+	re := compileFullMatch(expr)
+	if re == nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+func compileFullMatch(expr string) *regexp.Regexp {
+	regexCacheMu.Lock()
+	defer regexCacheMu.Unlock()
+	if re, ok := regexCache[expr]; ok {
+		return re
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		re = nil
+	}
+	if len(regexCache) >= regexCacheLimit {
+		regexCache = make(map[string]*regexp.Regexp)
+	}
+	regexCache[expr] = re
+	return re
+}
+
+func hasPrefix(s, prefix string, caseSensitive bool) bool {
+	if caseSensitive {
+		return strings.HasPrefix(s, prefix)
+	}
+	return strings.HasPrefix(strings.ToLower(s), strings.ToLower(prefix))
+}
+
+func equal(a, b string, caseSensitive bool) bool {
+	if caseSensitive {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
+// describeRouteMatch 与 describeMatch 相比包含header和query参数
+func describeRouteMatch(m *route.RouteMatch) string {
+	conds := []string{describeMatch(m)}
+	for _, hm := range m.GetHeaders() {
+		conds = append(conds, fmt.Sprintf("header %s", hm.GetName()))
+	}
+	for _, qm := range m.GetQueryParameters() {
+		conds = append(conds, fmt.Sprintf("query %s", qm.GetName()))
+	}
+	return strings.Join(conds, " ")
+}
@@ -0,0 +1,357 @@
+package sidecar
+
+import (
+	"testing"
+
+	admin "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	httpConnectionManager "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/ptypes/any"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func mustAny(t *testing.T, m proto.Message) *any.Any {
+	t.Helper()
+	a, err := anypb.New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func testConfigDump(t *testing.T, listeners []*listener.Listener, routes []*route.RouteConfiguration) *ConfigDump {
+	t.Helper()
+	ld := &admin.ListenersConfigDump{}
+	for _, l := range listeners {
+		ld.DynamicListeners = append(ld.DynamicListeners, &admin.ListenersConfigDump_DynamicListener{
+			Name:        l.GetName(),
+			ActiveState: &admin.ListenersConfigDump_DynamicListenerState{Listener: mustAny(t, l)},
+		})
+	}
+	rd := &admin.RoutesConfigDump{}
+	for _, rc := range routes {
+		rd.DynamicRouteConfigs = append(rd.DynamicRouteConfigs, &admin.RoutesConfigDump_DynamicRouteConfig{
+			RouteConfig: mustAny(t, rc),
+		})
+	}
+	return &ConfigDump{&admin.ConfigDump{Configs: []*any.Any{mustAny(t, ld), mustAny(t, rd)}}}
+}
+
+func testRDSChain(t *testing.T, name, rds string, m *listener.FilterChainMatch) *listener.FilterChain {
+	hcm := &httpConnectionManager.HttpConnectionManager{
+		RouteSpecifier: &httpConnectionManager.HttpConnectionManager_Rds{
+			Rds: &httpConnectionManager.Rds{RouteConfigName: rds},
+		},
+	}
+	return &listener.FilterChain{
+		Name:             name,
+		FilterChainMatch: m,
+		Filters: []*listener.Filter{{
+			Name:       wellknown.HTTPConnectionManager,
+			ConfigType: &listener.Filter_TypedConfig{TypedConfig: mustAny(t, hcm)},
+		}},
+	}
+}
+
+func testListener(name, address string, port uint32, chains []*listener.FilterChain, defaultChain *listener.FilterChain) *listener.Listener {
+	return &listener.Listener{
+		Name: name,
+		Address: &core.Address{Address: &core.Address_SocketAddress{SocketAddress: &core.SocketAddress{
+			Address:       address,
+			PortSpecifier: &core.SocketAddress_PortValue{PortValue: port},
+		}}},
+		FilterChains:       chains,
+		DefaultFilterChain: defaultChain,
+	}
+}
+
+func testVirtualHost(name string, domains ...string) *route.VirtualHost {
+	return &route.VirtualHost{
+		Name:    name,
+		Domains: domains,
+		Routes: []*route.Route{{
+			Name:  "default",
+			Match: &route.RouteMatch{PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"}},
+			Action: &route.Route_Route{Route: &route.RouteAction{
+				ClusterSpecifier: &route.RouteAction_Cluster{Cluster: name},
+			}},
+		}},
+	}
+}
+
+func stepName(result *SimulateResult, stage string) string {
+	for _, step := range result.Steps {
+		if step.Stage == stage {
+			return step.Name
+		}
+	}
+	return ""
+}
+
+func TestSimulateRequestPathFilterChain(t *testing.T) {
+	listeners := []*listener.Listener{
+		testListener("0.0.0.0_8080", "0.0.0.0", 8080, []*listener.FilterChain{
+			testRDSChain(t, "raw", "8080", &listener.FilterChainMatch{TransportProtocol: "raw_buffer"}),
+			testRDSChain(t, "http", "8080", &listener.FilterChainMatch{
+				TransportProtocol:    "raw_buffer",
+				ApplicationProtocols: []string{"http/1.0", "http/1.1", "h2c"},
+			}),
+			testRDSChain(t, "tls-wildcard", "8080", &listener.FilterChainMatch{
+				TransportProtocol: "tls",
+				ServerNames:       []string{"*.example.com"},
+			}),
+			testRDSChain(t, "tls-exact", "8080", &listener.FilterChainMatch{
+				TransportProtocol: "tls",
+				ServerNames:       []string{"foo.example.com"},
+			}),
+		}, testRDSChain(t, "default", "8080", nil)),
+		testListener("10.0.0.1_8080", "10.0.0.1", 8080, []*listener.FilterChain{
+			testRDSChain(t, "ip-specific", "8080", nil),
+		}, nil),
+		testListener(virtualOutboundListener, "0.0.0.0", 15001, []*listener.FilterChain{
+			testRDSChain(t, "passthrough", "8080", nil),
+			testRDSChain(t, "port-9090", "8080", &listener.FilterChainMatch{
+				DestinationPort: wrapperspb.UInt32(9090),
+			}),
+			testRDSChain(t, "cidr-16", "8080", &listener.FilterChainMatch{
+				PrefixRanges: []*core.CidrRange{{AddressPrefix: "10.1.0.0", PrefixLen: wrapperspb.UInt32(16)}},
+			}),
+			testRDSChain(t, "cidr-24", "8080", &listener.FilterChainMatch{
+				PrefixRanges: []*core.CidrRange{{AddressPrefix: "10.1.2.0", PrefixLen: wrapperspb.UInt32(24)}},
+			}),
+		}, nil),
+	}
+	routes := []*route.RouteConfiguration{{
+		Name:         "8080",
+		VirtualHosts: []*route.VirtualHost{testVirtualHost("allow_any", "*")},
+	}}
+	configDump := testConfigDump(t, listeners, routes)
+
+	cases := []struct {
+		name     string
+		req      SimulateRequest
+		listener string
+		chain    string
+		result   string
+	}{
+		{
+			name:     "plain http prefers the chain with application protocols",
+			req:      SimulateRequest{Host: "foo.example.com", Port: 8080},
+			listener: "0.0.0.0_8080",
+			chain:    "http",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "tls prefers the exact server name",
+			req:      SimulateRequest{Host: "foo.example.com", Port: 8080, TLS: true},
+			listener: "0.0.0.0_8080",
+			chain:    "tls-exact",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "tls falls back to the wildcard server name",
+			req:      SimulateRequest{Host: "bar.example.com", Port: 8080, TLS: true},
+			listener: "0.0.0.0_8080",
+			chain:    "tls-wildcard",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "tls without a matching server name uses the default chain",
+			req:      SimulateRequest{Host: "foo.other.com", Port: 8080, TLS: true},
+			listener: "0.0.0.0_8080",
+			chain:    "default",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "destination ip listener wins over the wildcard listener",
+			req:      SimulateRequest{Host: "foo.example.com", Port: 8080, DestinationIP: "10.0.0.1"},
+			listener: "10.0.0.1_8080",
+			chain:    "ip-specific",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "destination port is matched before prefix ranges",
+			req:      SimulateRequest{Host: "foo", Port: 9090, DestinationIP: "10.1.2.3"},
+			listener: virtualOutboundListener,
+			chain:    "port-9090",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "longest prefix range wins",
+			req:      SimulateRequest{Host: "foo", Port: 7070, DestinationIP: "10.1.2.3"},
+			listener: virtualOutboundListener,
+			chain:    "cidr-24",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "shorter prefix range when the longer one does not contain the ip",
+			req:      SimulateRequest{Host: "foo", Port: 7070, DestinationIP: "10.1.3.3"},
+			listener: virtualOutboundListener,
+			chain:    "cidr-16",
+			result:   SimulateResultRouted,
+		},
+		{
+			name:     "chain without match criteria catches the rest",
+			req:      SimulateRequest{Host: "foo", Port: 7070, DestinationIP: "192.168.0.1"},
+			listener: virtualOutboundListener,
+			chain:    "passthrough",
+			result:   SimulateResultRouted,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := SimulateRequestPath(configDump, EDSInfo{}, c.req)
+			if got := stepName(result, SimulateStageListener); got != c.listener {
+				t.Errorf("listener = %q, want %q", got, c.listener)
+			}
+			if got := stepName(result, SimulateStageFilterChain); got != c.chain {
+				t.Errorf("filter chain = %q, want %q", got, c.chain)
+			}
+			if result.Result != c.result {
+				t.Errorf("result = %q, want %q, notes: %v", result.Result, c.result, result.Notes)
+			}
+		})
+	}
+}
+
+func TestSimulateRequestPathNoListener(t *testing.T) {
+	configDump := testConfigDump(t, []*listener.Listener{
+		testListener("0.0.0.0_8080", "0.0.0.0", 8080, nil, nil),
+	}, nil)
+	result := SimulateRequestPath(configDump, EDSInfo{}, SimulateRequest{Host: "foo", Port: 9090})
+	if result.Result != SimulateResultNoListener {
+		t.Errorf("result = %q, want %q", result.Result, SimulateResultNoListener)
+	}
+}
+
+func TestSimulateRequestPathVirtualHost(t *testing.T) {
+	listeners := []*listener.Listener{
+		testListener("0.0.0.0_80", "0.0.0.0", 80, []*listener.FilterChain{
+			testRDSChain(t, "http", "80", nil),
+		}, nil),
+		testListener("0.0.0.0_81", "0.0.0.0", 81, []*listener.FilterChain{
+			testRDSChain(t, "http", "81", nil),
+		}, nil),
+	}
+	routes := []*route.RouteConfiguration{
+		{
+			Name: "80",
+			VirtualHosts: []*route.VirtualHost{
+				testVirtualHost("catch-all", "*"),
+				testVirtualHost("prefix", "foo.*"),
+				testVirtualHost("suffix", "*.example.com"),
+				testVirtualHost("longer-suffix", "*.bar.example.com"),
+				testVirtualHost("exact", "foo.example.com", "foo.example.com:80"),
+			},
+		},
+		{
+			Name:         "81",
+			VirtualHosts: []*route.VirtualHost{testVirtualHost("exact", "foo.example.com")},
+		},
+	}
+	configDump := testConfigDump(t, listeners, routes)
+
+	cases := []struct {
+		name        string
+		req         SimulateRequest
+		virtualHost string
+		result      string
+	}{
+		{
+			name:        "exact domain",
+			req:         SimulateRequest{Host: "foo.example.com", Port: 80},
+			virtualHost: "exact",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "exact domain is case insensitive and may carry the port",
+			req:         SimulateRequest{Host: "FOO.example.com:80", Port: 80},
+			virtualHost: "exact",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "longest suffix wildcard",
+			req:         SimulateRequest{Host: "a.bar.example.com", Port: 80},
+			virtualHost: "longer-suffix",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "suffix wildcard",
+			req:         SimulateRequest{Host: "a.example.com", Port: 80},
+			virtualHost: "suffix",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "suffix wildcard wins over prefix wildcard",
+			req:         SimulateRequest{Host: "foo.a.example.com", Port: 80},
+			virtualHost: "suffix",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "prefix wildcard",
+			req:         SimulateRequest{Host: "foo.other", Port: 80},
+			virtualHost: "prefix",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "catch all",
+			req:         SimulateRequest{Host: "other", Port: 80},
+			virtualHost: "catch-all",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "authority header overrides host",
+			req:         SimulateRequest{Host: "other", Port: 80, Headers: map[string]string{"Host": "foo.example.com"}},
+			virtualHost: "exact",
+			result:      SimulateResultRouted,
+		},
+		{
+			name:        "no matching virtual host",
+			req:         SimulateRequest{Host: "other", Port: 81},
+			virtualHost: "",
+			result:      SimulateResultNoRoute,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := SimulateRequestPath(configDump, EDSInfo{}, c.req)
+			if got := stepName(result, SimulateStageVirtualHost); got != c.virtualHost {
+				t.Errorf("virtual host = %q, want %q", got, c.virtualHost)
+			}
+			if result.Result != c.result {
+				t.Errorf("result = %q, want %q, notes: %v", result.Result, c.result, result.Notes)
+			}
+			if c.result == SimulateResultRouted && (len(result.Clusters) != 1 || result.Clusters[0].Name != c.virtualHost) {
+				t.Errorf("clusters = %v, want %s", result.Clusters, c.virtualHost)
+			}
+		})
+	}
+}
+
+func TestFullMatch(t *testing.T) {
+	cases := []struct {
+		expr  string
+		value string
+		want  bool
+	}{
+		{expr: "/api/v[0-9]+", value: "/api/v1", want: true},
+		{expr: "/api/v[0-9]+", value: "/api/v1/users", want: false},
+		{expr: "/api/v[0-9]+", value: "/prefix/api/v1", want: false},
+		{expr: "a|b", value: "b", want: true},
+		{expr: "a|b", value: "ab", want: false},
+		{expr: "(", value: "(", want: false},
+	}
+	for _, c := range cases {
+		// 第二次调用命中缓存, 结果必须一致
+		for i := 0; i < 2; i++ {
+			if got := fullMatch(c.expr, c.value); got != c.want {
+				t.Errorf("fullMatch(%q, %q) = %v, want %v", c.expr, c.value, got, c.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package sidecar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+)
+
+// GetRawConfigDump 获取最新的config_dump原始内容, 不使用缓存, 用于保存快照
+func (s *Sidecar) GetRawConfigDump(namespace, pod string) ([]byte, error) {
+	config, err := s.EnvoyDo(context.TODO(), pod, namespace, "GET", "config_dump")
+	if err != nil {
+		return nil, err
+	}
+	// 确认是合法的config_dump
+	if _, err = NewConfigDump(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// DiffSnapshot 比较快照与边车当前的配置
+func (s *Sidecar) DiffSnapshot(snapshot *ConfigDump, namespace, pod string) (*ConfigDiff, error) {
+	configDump, err := s.getConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return DiffConfigDump(snapshot, configDump), nil
+}
+
+func CompressConfigDump(config []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(config); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecompressConfigDump 解压并解析快照中的config_dump
+func DecompressConfigDump(compressed []byte) (*ConfigDump, error) {
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	config, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewConfigDump(config)
+}
@@ -0,0 +1,336 @@
+package sidecar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+)
+
+const (
+	StatsFormatJSON       = "json"
+	StatsFormatPrometheus = "prometheus"
+
+	StatTypeCounter   = "counter"
+	StatTypeGauge     = "gauge"
+	StatTypeHistogram = "histogram"
+	StatTypeUntyped   = "untyped"
+)
+
+// istio bootstrap 中 stats_tags 定义的标签, 老版本envoy使用默认的 envoy. 前缀
+var (
+	clusterNameLabels     = []string{"cluster_name", "envoy_cluster_name"}
+	listenerAddressLabels = []string{"listener_address", "envoy_listener_address"}
+)
+
+// json格式的指标名没有提取标签, 使用与istio bootstrap 中 cluster_name 和 listener_address 相同的正则拆分
+var (
+	clusterStatPattern  = regexp.MustCompile(`^cluster\.(.+?(?:\..+?\.svc\.cluster\.local)?)\.(.+)$`)
+	listenerStatPattern = regexp.MustCompile(`^listener\.((?:[_.[:digit:]]*|[_\[\]aAbBcCdDeEfF[:digit:]]*))\.(.+)$`)
+)
+
+type Stat struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Labels    map[string]string `json:"labels"`
+	Value     float64           `json:"value"`
+	Histogram *HistogramStat    `json:"histogram,omitempty"`
+}
+
+type HistogramStat struct {
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+}
+
+type ClusterStats struct {
+	Name      string                 `json:"name"`
+	FQDN      host.Name              `json:"fqdn"`
+	Port      int                    `json:"port"`
+	Subset    string                 `json:"subset"`
+	Direction model.TrafficDirection `json:"direction"`
+	Stats     []Stat                 `json:"stats"`
+}
+
+type ListenerStats struct {
+	Address string `json:"address"`
+	Stats   []Stat `json:"stats"`
+}
+
+// Stats envoy指标按集群和监听器分组, 不属于二者的放在 Others
+type Stats struct {
+	Clusters  []ClusterStats  `json:"clusters"`
+	Listeners []ListenerStats `json:"listeners"`
+	Others    []Stat          `json:"others"`
+}
+
+// GetStats format为空时解析 /stats/prometheus 并按集群和监听器分组, json时解析 /stats?format=json 并同样分组,
+// prometheus返回envoy的原始输出; filter 为正则表达式
+func (s *Sidecar) GetStats(namespace, pod, format, filter string) (interface{}, error) {
+	query := url.Values{}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	switch format {
+	case StatsFormatJSON:
+		query.Set("format", "json")
+		out, err := s.EnvoyDo(context.TODO(), pod, namespace, http.MethodGet, "stats?"+query.Encode())
+		if err != nil {
+			return nil, err
+		}
+		return JSONToStats(out)
+	case StatsFormatPrometheus:
+		path := "stats/prometheus"
+		if len(query) != 0 {
+			path += "?" + query.Encode()
+		}
+		out, err := s.EnvoyDo(context.TODO(), pod, namespace, http.MethodGet, path)
+		if err != nil {
+			return nil, err
+		}
+		return string(out), nil
+	}
+
+	var re *regexp.Regexp
+	if filter != "" {
+		var err error
+		if re, err = regexp.Compile(filter); err != nil {
+			return nil, err
+		}
+	}
+	out, err := s.EnvoyDo(context.TODO(), pod, namespace, http.MethodGet, "stats/prometheus")
+	if err != nil {
+		return nil, err
+	}
+	return PrometheusToStats(out, re)
+}
+
+// PrometheusToStats 解析 /stats/prometheus 的输出, filter 不为空时只保留名称或所属集群、监听器匹配的指标
+func PrometheusToStats(out []byte, filter *regexp.Regexp) (*Stats, error) {
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]Stat, 0)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			stat := newStat(family, metric)
+			clusterName := firstLabel(stat.Labels, clusterNameLabels)
+			listenerAddress := firstLabel(stat.Labels, listenerAddressLabels)
+			if filter != nil && !filter.MatchString(stat.Name) &&
+				!(clusterName != "" && filter.MatchString(clusterName)) &&
+				!(listenerAddress != "" && filter.MatchString(listenerAddress)) {
+				continue
+			}
+			all = append(all, stat)
+		}
+	}
+	return groupStats(all), nil
+}
+
+// envoyJSONStats /stats?format=json 的输出, counter和gauge的value为数字, text readout为字符串;
+// 所有直方图在同一项的 histograms 中, 每个分位数对应 supported_quantiles 中的一项
+type envoyJSONStats struct {
+	Stats []struct {
+		Name       string      `json:"name"`
+		Value      interface{} `json:"value"`
+		Histograms *struct {
+			SupportedQuantiles []float64 `json:"supported_quantiles"`
+			ComputedQuantiles  []struct {
+				Name   string `json:"name"`
+				Values []struct {
+					Cumulative *float64 `json:"cumulative"`
+				} `json:"values"`
+			} `json:"computed_quantiles"`
+		} `json:"histograms"`
+	} `json:"stats"`
+}
+
+// JSONToStats 解析 /stats?format=json 的输出并按集群和监听器分组;
+// json中不区分counter和gauge, 类型为untyped; 直方图只有分位数, 没有样本数和总和; text readout 不是数值, 不返回
+func JSONToStats(out []byte) (*Stats, error) {
+	raw := &envoyJSONStats{}
+	if err := json.Unmarshal(out, raw); err != nil {
+		return nil, err
+	}
+
+	all := make([]Stat, 0, len(raw.Stats))
+	for _, item := range raw.Stats {
+		if item.Histograms == nil {
+			if value, ok := item.Value.(float64); ok {
+				stat := newJSONStat(item.Name, StatTypeUntyped)
+				stat.Value = value
+				all = append(all, stat)
+			}
+			continue
+		}
+		quantiles := item.Histograms.SupportedQuantiles
+		for _, computed := range item.Histograms.ComputedQuantiles {
+			cumulative := make(map[float64]float64)
+			for index, value := range computed.Values {
+				if index < len(quantiles) && value.Cumulative != nil {
+					cumulative[quantiles[index]] = *value.Cumulative
+				}
+			}
+			stat := newJSONStat(computed.Name, StatTypeHistogram)
+			stat.Histogram = &HistogramStat{P50: cumulative[50], P90: cumulative[90], P99: cumulative[99]}
+			all = append(all, stat)
+		}
+	}
+	return groupStats(all), nil
+}
+
+// newJSONStat 把集群和监听器从指标名中拆出来作为标签, 与prometheus格式中的标签一致
+func newJSONStat(name, statType string) Stat {
+	stat := Stat{Name: name, Type: statType, Labels: make(map[string]string)}
+	if match := clusterStatPattern.FindStringSubmatch(name); match != nil {
+		stat.Name = "cluster." + match[2]
+		stat.Labels[clusterNameLabels[0]] = match[1]
+	} else if match := listenerStatPattern.FindStringSubmatch(name); match != nil {
+		stat.Name = "listener." + match[2]
+		stat.Labels[listenerAddressLabels[0]] = match[1]
+	}
+	return stat
+}
+
+// groupStats 按集群和监听器标签分组, 不属于二者的放在 Others
+func groupStats(all []Stat) *Stats {
+	clusters := make(map[string]*ClusterStats)
+	listeners := make(map[string]*ListenerStats)
+	stats := &Stats{
+		Clusters:  make([]ClusterStats, 0),
+		Listeners: make([]ListenerStats, 0),
+		Others:    make([]Stat, 0),
+	}
+	for _, stat := range all {
+		clusterName := firstLabel(stat.Labels, clusterNameLabels)
+		listenerAddress := firstLabel(stat.Labels, listenerAddressLabels)
+		switch {
+		case clusterName != "":
+			cs, ok := clusters[clusterName]
+			if !ok {
+				direction, subset, fqdn, port := parseClusterName(clusterName)
+				cs = &ClusterStats{
+					Name:      clusterName,
+					FQDN:      fqdn,
+					Port:      port,
+					Subset:    subset,
+					Direction: direction,
+					Stats:     make([]Stat, 0),
+				}
+				clusters[clusterName] = cs
+			}
+			cs.Stats = append(cs.Stats, stat)
+		case listenerAddress != "":
+			ls, ok := listeners[listenerAddress]
+			if !ok {
+				ls = &ListenerStats{Address: listenerAddress, Stats: make([]Stat, 0)}
+				listeners[listenerAddress] = ls
+			}
+			ls.Stats = append(ls.Stats, stat)
+		default:
+			stats.Others = append(stats.Others, stat)
+		}
+	}
+
+	for _, cs := range clusters {
+		sortStats(cs.Stats)
+		stats.Clusters = append(stats.Clusters, *cs)
+	}
+	for _, ls := range listeners {
+		sortStats(ls.Stats)
+		stats.Listeners = append(stats.Listeners, *ls)
+	}
+	sort.Slice(stats.Clusters, func(i, j int) bool {
+		return stats.Clusters[i].Name < stats.Clusters[j].Name
+	})
+	sort.Slice(stats.Listeners, func(i, j int) bool {
+		return stats.Listeners[i].Address < stats.Listeners[j].Address
+	})
+	sortStats(stats.Others)
+	return stats
+}
+
+func newStat(family *dto.MetricFamily, metric *dto.Metric) Stat {
+	stat := Stat{Name: family.GetName(), Labels: make(map[string]string)}
+	for _, label := range metric.GetLabel() {
+		stat.Labels[label.GetName()] = label.GetValue()
+	}
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		stat.Type = StatTypeCounter
+		stat.Value = metric.GetCounter().GetValue()
+	case dto.MetricType_GAUGE:
+		stat.Type = StatTypeGauge
+		stat.Value = metric.GetGauge().GetValue()
+	case dto.MetricType_HISTOGRAM:
+		stat.Type = StatTypeHistogram
+		h := metric.GetHistogram()
+		stat.Value = float64(h.GetSampleCount())
+		stat.Histogram = &HistogramStat{
+			Count: h.GetSampleCount(),
+			Sum:   h.GetSampleSum(),
+			P50:   histogramQuantile(0.5, h),
+			P90:   histogramQuantile(0.9, h),
+			P99:   histogramQuantile(0.99, h),
+		}
+	default:
+		stat.Type = StatTypeUntyped
+		stat.Value = metric.GetUntyped().GetValue()
+	}
+	return stat
+}
+
+// histogramQuantile 根据桶线性插值计算分位数, 与prometheus的histogram_quantile一致
+func histogramQuantile(q float64, h *dto.Histogram) float64 {
+	buckets := h.GetBucket()
+	if len(buckets) == 0 || h.GetSampleCount() == 0 {
+		return 0
+	}
+	rank := q * float64(h.GetSampleCount())
+	var lowerBound float64
+	var lowerCount uint64
+	for _, b := range buckets {
+		if float64(b.GetCumulativeCount()) >= rank {
+			if math.IsInf(b.GetUpperBound(), 1) {
+				return lowerBound
+			}
+			count := b.GetCumulativeCount() - lowerCount
+			if count == 0 {
+				return b.GetUpperBound()
+			}
+			return lowerBound + (b.GetUpperBound()-lowerBound)*(rank-float64(lowerCount))/float64(count)
+		}
+		lowerBound = b.GetUpperBound()
+		lowerCount = b.GetCumulativeCount()
+	}
+	return lowerBound
+}
+
+func firstLabel(labels map[string]string, names []string) string {
+	for _, name := range names {
+		if v, ok := labels[name]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+func sortStats(stats []Stat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+}
@@ -0,0 +1,146 @@
+package sidecar
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+const (
+	XdsSynced         = "SYNCED"
+	XdsStale          = "STALE"
+	XdsStaleNeverAck  = "STALE (Never Acknowledged)"
+	XdsNotSent        = "NOT SENT"
+	XdsUnknownVersion = "*"
+)
+
+// SyncStatus istiod /debug/syncz 返回的单个代理同步状态, 与 istio.io/istio/pilot/pkg/xds.SyncStatus 一致
+type SyncStatus struct {
+	ClusterID            string `json:"cluster_id,omitempty"`
+	ProxyID              string `json:"proxy,omitempty"`
+	ProxyVersion         string `json:"proxy_version,omitempty"`
+	IstioVersion         string `json:"istio_version,omitempty"`
+	ClusterSent          string `json:"cluster_sent,omitempty"`
+	ClusterAcked         string `json:"cluster_acked,omitempty"`
+	ListenerSent         string `json:"listener_sent,omitempty"`
+	ListenerAcked        string `json:"listener_acked,omitempty"`
+	RouteSent            string `json:"route_sent,omitempty"`
+	RouteAcked           string `json:"route_acked,omitempty"`
+	EndpointSent         string `json:"endpoint_sent,omitempty"`
+	EndpointAcked        string `json:"endpoint_acked,omitempty"`
+	ExtesionConfigSent   string `json:"extensionconfig_sent,omitempty"`
+	ExtensionConfigAcked string `json:"extensionconfig_acked,omitempty"`
+}
+
+type XdsStatus struct {
+	Status string `json:"status"`
+	Sent   string `json:"sent"`
+	Acked  string `json:"acked"`
+}
+
+type ProxyStatus struct {
+	ProxyID      string    `json:"proxyId"`
+	ClusterID    string    `json:"clusterId"`
+	Istiod       string    `json:"istiod"`
+	IstioVersion string    `json:"istioVersion"`
+	CDS          XdsStatus `json:"cds"`
+	LDS          XdsStatus `json:"lds"`
+	EDS          XdsStatus `json:"eds"`
+	RDS          XdsStatus `json:"rds"`
+	ECDS         XdsStatus `json:"ecds"`
+}
+
+// SyncStatusesToProxyStatus 汇总所有istiod实例的syncz结果, 与 istioctl proxy-status 的输出一致
+func SyncStatusesToProxyStatus(statuses map[string][]byte) ([]ProxyStatus, error) {
+	proxyStatuses := make([]ProxyStatus, 0)
+	for istiod, status := range statuses {
+		syncStatuses := make([]SyncStatus, 0)
+		if err := json.Unmarshal(status, &syncStatuses); err != nil {
+			return nil, err
+		}
+		for _, ss := range syncStatuses {
+			proxyStatuses = append(proxyStatuses, syncStatusToProxyStatus(istiod, ss))
+		}
+	}
+	sort.Slice(proxyStatuses, func(i, j int) bool {
+		if proxyStatuses[i].ClusterID != proxyStatuses[j].ClusterID {
+			return proxyStatuses[i].ClusterID < proxyStatuses[j].ClusterID
+		}
+		return proxyStatuses[i].ProxyID < proxyStatuses[j].ProxyID
+	})
+	return proxyStatuses, nil
+}
+
+func syncStatusToProxyStatus(istiod string, ss SyncStatus) ProxyStatus {
+	version := ss.IstioVersion
+	if version == "" {
+		// 老版本的istiod没有返回istio版本, 使用代理版本代替
+		version = ss.ProxyVersion + XdsUnknownVersion
+	}
+	return ProxyStatus{
+		ProxyID:      ss.ProxyID,
+		ClusterID:    ss.ClusterID,
+		Istiod:       istiod,
+		IstioVersion: version,
+		CDS:          newXdsStatus(ss.ClusterSent, ss.ClusterAcked),
+		LDS:          newXdsStatus(ss.ListenerSent, ss.ListenerAcked),
+		EDS:          newXdsStatus(ss.EndpointSent, ss.EndpointAcked),
+		RDS:          newXdsStatus(ss.RouteSent, ss.RouteAcked),
+		ECDS:         newXdsStatus(ss.ExtesionConfigSent, ss.ExtensionConfigAcked),
+	}
+}
+
+func newXdsStatus(sent, acked string) XdsStatus {
+	return XdsStatus{
+		Status: xdsStatus(sent, acked),
+		Sent:   sent,
+		Acked:  acked,
+	}
+}
+
+func xdsStatus(sent, acked string) string {
+	if sent == "" {
+		return XdsNotSent
+	}
+	if sent == acked {
+		return XdsSynced
+	}
+	// acked will be empty string when there is never Acknowledged
+	if acked == "" {
+		return XdsStaleNeverAck
+	}
+	// Since the Nonce changes to uuid, so there is no more any time diff info
+	return XdsStale
+}
+
+// FilterProxyStatus 按命名空间过滤, staleOnly 为true时只保留存在未同步配置的代理
+func FilterProxyStatus(proxyStatuses []ProxyStatus, namespace string, staleOnly bool) []ProxyStatus {
+	filtered := make([]ProxyStatus, 0)
+	for _, ps := range proxyStatuses {
+		if namespace != "" && ps.Namespace() != namespace {
+			continue
+		}
+		if staleOnly && !ps.IsStale() {
+			continue
+		}
+		filtered = append(filtered, ps)
+	}
+	return filtered
+}
+
+// Namespace proxyID 的格式为 pod.namespace
+func (p ProxyStatus) Namespace() string {
+	if i := strings.LastIndex(p.ProxyID, "."); i >= 0 {
+		return p.ProxyID[i+1:]
+	}
+	return ""
+}
+
+func (p ProxyStatus) IsStale() bool {
+	for _, xs := range []XdsStatus{p.CDS, p.LDS, p.EDS, p.RDS, p.ECDS} {
+		if strings.HasPrefix(xs.Status, XdsStale) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+package sidecar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kube"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EndpointMissingInKubernetes envoy中存在, 但k8s的EndpointSlice中已经没有的端点
+	EndpointMissingInKubernetes = "MissingInKubernetes"
+	// EndpointMissingInEnvoy k8s中已就绪, 但envoy中没有的端点
+	EndpointMissingInEnvoy = "MissingInEnvoy"
+)
+
+// EndpointWorkload EDS端点及其对应的pod、节点和工作负载
+type EndpointWorkload struct {
+	Cluster            string `json:"cluster"`
+	Address            string `json:"address"`
+	Port               int    `json:"port"`
+	Status             string `json:"status"`
+	FailedOutlierCheck bool   `json:"filedOutlierCheck"`
+	Pod                string `json:"pod"`
+	Namespace          string `json:"namespace"`
+	Node               string `json:"node"`
+	Region             string `json:"region"`
+	Zone               string `json:"zone"`
+	WorkloadKind       string `json:"workloadKind"`
+	Workload           string `json:"workload"`
+	Version            string `json:"version"`
+	Mismatch           string `json:"mismatch,omitempty"`
+}
+
+type serviceKey struct {
+	namespace string
+	name      string
+}
+
+// kubeEndpoint EndpointSlice中的端点
+type kubeEndpoint struct {
+	address string
+	port    int
+	ready   bool
+	pod     *corev1.Pod
+	zone    string
+}
+
+// endpointResolver 缓存一次请求中用到的k8s资源
+type endpointResolver struct {
+	cli      *kube.Service
+	podCli   *kube.Pod
+	services map[serviceKey][]discoveryv1.EndpointSlice
+	ports    map[serviceKey][]corev1.ServicePort
+	pods     map[string]map[string]*corev1.Pod
+	podsByIP map[string]*corev1.Pod
+	nodes    map[string]map[string]string
+	loaded   map[string]bool
+}
+
+// GetEndpointWorkloads 将EDS端点与k8s的Pod和EndpointSlice关联, 并标记二者不一致的端点
+func (s *Sidecar) GetEndpointWorkloads(namespace, pod string) ([]EndpointWorkload, error) {
+	clusters, err := s.getClusters(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &endpointResolver{
+		cli:      kube.NewService(s.cli),
+		podCli:   kube.NewPod(s.cli),
+		services: make(map[serviceKey][]discoveryv1.EndpointSlice),
+		ports:    make(map[serviceKey][]corev1.ServicePort),
+		pods:     make(map[string]map[string]*corev1.Pod),
+		podsByIP: make(map[string]*corev1.Pod),
+		nodes:    make(map[string]map[string]string),
+		loaded:   make(map[string]bool),
+	}
+	nodes, err := kube.NewNode(s.cli).ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes.Items {
+		resolver.nodes[node.Name] = node.Labels
+	}
+
+	edsInfo := ClustersToEDSInfo(clusters)
+	clusterNames := make([]string, 0, len(edsInfo))
+	for clusterName := range edsInfo {
+		clusterNames = append(clusterNames, clusterName)
+		// 先加载所有k8s服务所在命名空间的pod, 非k8s服务的端点也可以按ip关联
+		_, _, fqdn, _ := parseClusterName(clusterName)
+		if key, ok := kubeServiceKey(string(fqdn)); ok {
+			if err = resolver.loadService(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	sort.Strings(clusterNames)
+
+	workloads := make([]EndpointWorkload, 0)
+	for _, clusterName := range clusterNames {
+		items, err := resolver.resolveCluster(clusterName, edsInfo[clusterName])
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, items...)
+	}
+	return workloads, nil
+}
+
+func (r *endpointResolver) resolveCluster(clusterName string, endpoints []EDSClusterInfo) ([]EndpointWorkload, error) {
+	workloads := make([]EndpointWorkload, 0, len(endpoints))
+	_, subset, fqdn, port := parseClusterName(clusterName)
+	key, ok := kubeServiceKey(string(fqdn))
+	if !ok {
+		// ServiceEntry等非k8s服务, 只能通过pod ip关联
+		for _, ep := range endpoints {
+			workloads = append(workloads, r.newEndpointWorkload(clusterName, ep, r.podsByIP[ep.Address], ""))
+		}
+		return workloads, nil
+	}
+
+	kubeEndpoints, err := r.kubeEndpoints(key, port)
+	if err != nil {
+		return nil, err
+	}
+	envoyEndpoints := make(map[string]bool)
+	for _, ep := range endpoints {
+		address := fmt.Sprintf("%s:%d", ep.Address, ep.Port)
+		envoyEndpoints[address] = true
+		mismatch := ""
+		kep, found := kubeEndpoints[address]
+		if !found && subset == "-" {
+			mismatch = EndpointMissingInKubernetes
+		}
+		pod := r.podsByIP[ep.Address]
+		if found && kep.pod != nil {
+			pod = kep.pod
+		}
+		workload := r.newEndpointWorkload(clusterName, ep, pod, mismatch)
+		if workload.Zone == "" && found {
+			workload.Zone = kep.zone
+		}
+		workloads = append(workloads, workload)
+	}
+
+	// subset集群只包含部分端点, 不检查k8s中多出的端点
+	if subset != "-" {
+		return workloads, nil
+	}
+	addresses := make([]string, 0, len(kubeEndpoints))
+	for address := range kubeEndpoints {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		kep := kubeEndpoints[address]
+		if envoyEndpoints[address] || !kep.ready {
+			continue
+		}
+		workload := r.newEndpointWorkload(clusterName, EDSClusterInfo{Address: kep.address, Port: kep.port},
+			kep.pod, EndpointMissingInEnvoy)
+		if workload.Zone == "" {
+			workload.Zone = kep.zone
+		}
+		workloads = append(workloads, workload)
+	}
+	return workloads, nil
+}
+
+func (r *endpointResolver) newEndpointWorkload(cluster string, ep EDSClusterInfo, pod *corev1.Pod, mismatch string) EndpointWorkload {
+	workload := EndpointWorkload{
+		Cluster:            cluster,
+		Address:            ep.Address,
+		Port:               ep.Port,
+		Status:             ep.Status,
+		FailedOutlierCheck: ep.FailedOutlierCheck,
+		Mismatch:           mismatch,
+	}
+	if pod == nil {
+		return workload
+	}
+	workload.Pod = pod.Name
+	workload.Namespace = pod.Namespace
+	workload.Node = pod.Spec.NodeName
+	workload.WorkloadKind, workload.Workload = podWorkload(pod)
+	workload.Version = pod.Labels["version"]
+	if workload.Version == "" {
+		workload.Version = pod.Labels["app.kubernetes.io/version"]
+	}
+	if labels, ok := r.nodes[pod.Spec.NodeName]; ok {
+		workload.Region = labels[corev1.LabelTopologyRegion]
+		workload.Zone = labels[corev1.LabelTopologyZone]
+	}
+	return workload
+}
+
+// kubeEndpoints 返回服务端口对应的所有端点, key为 ip:targetPort
+func (r *endpointResolver) kubeEndpoints(key serviceKey, servicePort int) (map[string]kubeEndpoint, error) {
+	if err := r.loadService(key); err != nil {
+		return nil, err
+	}
+	portName, found := "", false
+	for _, p := range r.ports[key] {
+		if int(p.Port) == servicePort {
+			portName, found = p.Name, true
+			break
+		}
+	}
+
+	endpoints := make(map[string]kubeEndpoint)
+	if !found {
+		return endpoints, nil
+	}
+	for _, slice := range r.services[key] {
+		for _, p := range slice.Ports {
+			if p.Port == nil || (p.Name != nil && *p.Name != portName) || (p.Name == nil && portName != "") {
+				continue
+			}
+			for _, ep := range slice.Endpoints {
+				var pod *corev1.Pod
+				if ep.TargetRef != nil && ep.TargetRef.Kind == "Pod" {
+					pod = r.pods[ep.TargetRef.Namespace][ep.TargetRef.Name]
+				}
+				zone := ""
+				if ep.Zone != nil {
+					zone = *ep.Zone
+				}
+				for _, address := range ep.Addresses {
+					endpoints[fmt.Sprintf("%s:%d", address, *p.Port)] = kubeEndpoint{
+						address: address,
+						port:    int(*p.Port),
+						ready:   ep.Conditions.Ready == nil || *ep.Conditions.Ready,
+						pod:     pod,
+						zone:    zone,
+					}
+				}
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+func (r *endpointResolver) loadService(key serviceKey) error {
+	if _, ok := r.services[key]; ok {
+		return nil
+	}
+	if err := r.loadPods(key.namespace); err != nil {
+		return err
+	}
+	service, err := r.cli.GetService(key.namespace, key.name)
+	if apierrors.IsNotFound(err) {
+		// 服务已被删除, envoy中的端点都会被标记为不一致
+		domainLog.Debugf("service %s.%s not found", key.name, key.namespace)
+		r.services[key] = make([]discoveryv1.EndpointSlice, 0)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.ports[key] = service.Spec.Ports
+	slices, err := r.cli.ListEndpointSlices(key.namespace, key.name)
+	if err != nil {
+		return err
+	}
+	r.services[key] = slices.Items
+	return nil
+}
+
+func (r *endpointResolver) loadPods(namespace string) error {
+	if r.loaded[namespace] {
+		return nil
+	}
+	r.loaded[namespace] = true
+	pods, err := r.podCli.ListPods(namespace, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	r.pods[namespace] = make(map[string]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		r.pods[namespace][pod.Name] = pod
+		// hostNetwork的pod与节点共用ip, 不用于按ip关联
+		if pod.Status.PodIP != "" && !pod.Spec.HostNetwork {
+			r.podsByIP[pod.Status.PodIP] = pod
+		}
+	}
+	return nil
+}
+
+// kubeServiceKey 解析k8s服务的域名 {service}.{namespace}.svc.{domain}
+func kubeServiceKey(fqdn string) (serviceKey, bool) {
+	pieces := strings.Split(fqdn, ".")
+	if len(pieces) < 4 || pieces[2] != "svc" {
+		return serviceKey{}, false
+	}
+	return serviceKey{namespace: pieces[1], name: pieces[0]}, true
+}
+
+// podWorkload 返回pod所属的工作负载, ReplicaSet 还原为 Deployment
+func podWorkload(pod *corev1.Pod) (string, string) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return "Pod", pod.Name
+	}
+	if owner.Kind == "ReplicaSet" {
+		if hash, ok := pod.Labels["pod-template-hash"]; ok && strings.HasSuffix(owner.Name, "-"+hash) {
+			return "Deployment", strings.TrimSuffix(owner.Name, "-"+hash)
+		}
+	}
+	return owner.Kind, owner.Name
+}
@@ -0,0 +1,253 @@
+package traffic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"istio.io/pkg/log"
+)
+
+var domainLog = log.RegisterScope("traffic-domain", "traffic-domain debugging", 0)
+
+const (
+	DefaultVersionLabel = "version"
+	minStepInterval     = 30 * time.Second
+)
+
+var ErrRolloutActive = errors.New("service already has a running or paused rollout")
+
+// RolloutStatusError 发布当前的状态不允许该操作
+type RolloutStatusError struct {
+	Status string
+	Action string
+}
+
+func (e *RolloutStatusError) Error() string {
+	return fmt.Sprintf("can not %s a %s rollout", e.Action, e.Status)
+}
+
+// CanaryConfig Steps 为每一步金丝雀版本的权重, 必须递增且最后一步为100; 阈值为0时不检查
+type CanaryConfig struct {
+	Namespace     string
+	Service       string
+	VersionLabel  string
+	StableVersion string
+	CanaryVersion string
+	Steps         []int
+	StepInterval  time.Duration
+	MaxErrorRate  float64
+	MaxP99Latency float64
+}
+
+func (c *CanaryConfig) Validate() error {
+	if c.Namespace == "" || c.Service == "" {
+		return errors.New("namespace and service are required")
+	}
+	// 指标按 destination_version 过滤, istio只从pod的version标签生成该值, 使用其他标签区分版本时无法检查金丝雀版本的指标
+	if c.VersionLabel != "" && c.VersionLabel != DefaultVersionLabel {
+		return fmt.Errorf("versionLabel must be %q, the canary metrics are filtered by destination_version which istio reads from the pod label %q",
+			DefaultVersionLabel, DefaultVersionLabel)
+	}
+	if c.StableVersion == "" || c.CanaryVersion == "" || c.StableVersion == c.CanaryVersion {
+		return errors.New("stableVersion and canaryVersion are required and must be different")
+	}
+	if len(c.Steps) == 0 || c.Steps[len(c.Steps)-1] != 100 {
+		return errors.New("steps must end with 100")
+	}
+	for i, step := range c.Steps {
+		if step <= 0 || step > 100 || (i > 0 && step <= c.Steps[i-1]) {
+			return fmt.Errorf("steps must be increasing weights in (0, 100], got %v", c.Steps)
+		}
+	}
+	if c.StepInterval < minStepInterval {
+		return fmt.Errorf("stepInterval must be at least %s", minStepInterval)
+	}
+	if c.MaxErrorRate < 0 || c.MaxErrorRate > 1 {
+		return errors.New("maxErrorRate must be in [0, 1]")
+	}
+	if c.MaxP99Latency < 0 {
+		return errors.New("maxP99Latency must not be negative")
+	}
+	return nil
+}
+
+// rolloutLocks 同一个发布的操作(接口调用和后台检查)串行执行, 避免权重被并发修改
+var rolloutLocks = newIdLocks()
+
+func lockRollout(id int64) func() {
+	return rolloutLocks.lock(id)
+}
+
+// startLocks 同一个服务同时只能有一个进行中的发布, 检查和创建记录之间不能插入其他发布
+var startLocks = newServiceLocks()
+
+func FormatSteps(steps []int) string {
+	items := make([]string, 0, len(steps))
+	for _, step := range steps {
+		items = append(items, strconv.Itoa(step))
+	}
+	return strings.Join(items, ",")
+}
+
+func ParseSteps(steps string) []int {
+	weights := make([]int, 0)
+	for _, item := range strings.Split(steps, ",") {
+		if weight, err := strconv.Atoi(strings.TrimSpace(item)); err == nil {
+			weights = append(weights, weight)
+		}
+	}
+	return weights
+}
+
+// StartCanary 创建两个版本的subset并把第一步的流量切到金丝雀版本, 之后由 RunCanaryController 按步骤推进
+func StartCanary(kubeConfig *model.KubeConfig, config CanaryConfig) (*model.CanaryRollout, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.VersionLabel == "" {
+		config.VersionLabel = DefaultVersionLabel
+	}
+	unlockService := startLocks.lock(kubeConfig.Id, config.Namespace, config.Service)
+	defer unlockService()
+	count, err := model.CanaryRolloutDB.CountActiveCanaryRollout(kubeConfig.Id, config.Namespace, config.Service)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrRolloutActive
+	}
+
+	istioClient, err := getIstioClient(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rollout := &model.CanaryRollout{
+		KubeConfigId:  kubeConfig.Id,
+		Cid:           kubeConfig.Cid,
+		Namespace:     config.Namespace,
+		Service:       config.Service,
+		VersionLabel:  config.VersionLabel,
+		StableVersion: config.StableVersion,
+		CanaryVersion: config.CanaryVersion,
+		Steps:         FormatSteps(config.Steps),
+		StepInterval:  int64(config.StepInterval / time.Second),
+		MaxErrorRate:  config.MaxErrorRate,
+		MaxP99Latency: config.MaxP99Latency,
+		CurrentStep:   0,
+		CanaryWeight:  config.Steps[0],
+		Status:        model.RolloutStatusRunning,
+		Message:       fmt.Sprintf("shifted %d%% traffic to %s", config.Steps[0], config.CanaryVersion),
+		NextCheckTime: now.Add(config.StepInterval).Unix(),
+		CreateTime:    now.Unix(),
+		UpdateTime:    now.Unix(),
+	}
+	if rollout.DestinationRule, err = ensureSubsets(istioClient, rollout); err != nil {
+		return nil, err
+	}
+	if rollout.VirtualService, err = findVirtualService(istioClient, rollout); err != nil {
+		return nil, err
+	}
+	// 先记录再切流量, 切换失败时记录为已终止, 避免流量已经切换但没有记录
+	if err = model.CanaryRolloutDB.CreateCanaryRollout(rollout); err != nil {
+		return nil, err
+	}
+	unlock := lockRollout(rollout.Id)
+	defer unlock()
+	if err = applyWeights(istioClient, rollout, rollout.CanaryWeight); err != nil {
+		rollout.Status = model.RolloutStatusAborted
+		rollout.Message = fmt.Sprintf("shift traffic failed: %s", err)
+		rollout.CanaryWeight = 0
+		_, _ = model.CanaryRolloutDB.UpdateCanaryRollout(rollout.Id, model.RolloutStatusRunning, map[string]interface{}{
+			"status":        rollout.Status,
+			"message":       rollout.Message,
+			"canary_weight": rollout.CanaryWeight,
+			"update_time":   time.Now().Unix(),
+		})
+		return nil, err
+	}
+	return rollout, nil
+}
+
+// PauseCanary 暂停后不再检查指标和推进权重, 保持当前的权重
+func PauseCanary(id int64) (*model.CanaryRollout, error) {
+	unlock := lockRollout(id)
+	defer unlock()
+	rollout, err := model.CanaryRolloutDB.GetCanaryRolloutById(id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Status != model.RolloutStatusRunning {
+		return nil, &RolloutStatusError{Status: rollout.Status, Action: "pause"}
+	}
+	return updateRollout(rollout, map[string]interface{}{
+		"status":  model.RolloutStatusPaused,
+		"message": fmt.Sprintf("paused at %d%%", rollout.CanaryWeight),
+	})
+}
+
+// ResumeCanary 恢复后重新观察一个周期再推进到下一步
+func ResumeCanary(id int64) (*model.CanaryRollout, error) {
+	unlock := lockRollout(id)
+	defer unlock()
+	rollout, err := model.CanaryRolloutDB.GetCanaryRolloutById(id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Status != model.RolloutStatusPaused {
+		return nil, &RolloutStatusError{Status: rollout.Status, Action: "resume"}
+	}
+	return updateRollout(rollout, map[string]interface{}{
+		"status":          model.RolloutStatusRunning,
+		"message":         fmt.Sprintf("resumed at %d%%", rollout.CanaryWeight),
+		"next_check_time": time.Now().Unix() + rollout.StepInterval,
+	})
+}
+
+// AbortCanary 终止发布, 所有流量切回稳定版本
+func AbortCanary(id int64) (*model.CanaryRollout, error) {
+	unlock := lockRollout(id)
+	defer unlock()
+	rollout, err := model.CanaryRolloutDB.GetCanaryRolloutById(id)
+	if err != nil {
+		return nil, err
+	}
+	if rollout.Status != model.RolloutStatusRunning && rollout.Status != model.RolloutStatusPaused {
+		return nil, &RolloutStatusError{Status: rollout.Status, Action: "abort"}
+	}
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(rollout.KubeConfigId)
+	if err != nil {
+		return nil, err
+	}
+	istioClient, err := getIstioClient(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = applyWeights(istioClient, rollout, 0); err != nil {
+		return nil, err
+	}
+	return updateRollout(rollout, map[string]interface{}{
+		"status":        model.RolloutStatusAborted,
+		"message":       fmt.Sprintf("aborted at %d%%, all traffic shifted back to %s", rollout.CanaryWeight, rollout.StableVersion),
+		"canary_weight": 0,
+	})
+}
+
+// updateRollout 只有状态没有被其他实例修改时才会更新
+func updateRollout(rollout *model.CanaryRollout, fields map[string]interface{}) (*model.CanaryRollout, error) {
+	fields["update_time"] = time.Now().Unix()
+	updated, err := model.CanaryRolloutDB.UpdateCanaryRollout(rollout.Id, rollout.Status, fields)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("rollout %d was modified concurrently", rollout.Id)
+	}
+	return model.CanaryRolloutDB.GetCanaryRolloutById(rollout.Id)
+}
@@ -0,0 +1,114 @@
+package traffic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kiali"
+	"github.com/shuxnhs/istio-dashboard/model"
+)
+
+const (
+	canaryCheckPeriod    = 10 * time.Second
+	metricsRetryInterval = 30 * time.Second
+)
+
+// RunCanaryController 定时检查到期的发布: 指标正常时推进到下一步, 超过阈值时回滚到上一步的权重
+func RunCanaryController() {
+	ticker := time.NewTicker(canaryCheckPeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		rollouts, err := model.CanaryRolloutDB.ListDueCanaryRollout(time.Now().Unix())
+		if err != nil {
+			domainLog.Errorf("list due canary rollouts err: %s", err)
+			continue
+		}
+		for _, rollout := range *rollouts {
+			reconcileCanaryRollout(rollout.Id)
+		}
+	}
+}
+
+func reconcileCanaryRollout(id int64) {
+	unlock := lockRollout(id)
+	defer unlock()
+	rollout, err := model.CanaryRolloutDB.GetCanaryRolloutById(id)
+	if err != nil {
+		domainLog.Errorf("get canary rollout %d err: %s", id, err)
+		return
+	}
+	// 等待锁的时候可能已经被暂停、终止或者由其他实例处理
+	if rollout.Status != model.RolloutStatusRunning || rollout.NextCheckTime > time.Now().Unix() {
+		return
+	}
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(rollout.KubeConfigId)
+	if err != nil {
+		retryRollout(rollout, fmt.Sprintf("get kube config err: %s", err))
+		return
+	}
+	kialiClient := kiali.NewKialiClient(kubeConfig)
+	if kialiClient == nil {
+		retryRollout(rollout, "connect to kiali failed")
+		return
+	}
+	metrics, err := getCanaryMetrics(kialiClient, rollout)
+	if err != nil {
+		retryRollout(rollout, err.Error())
+		return
+	}
+	reason := metrics.breach(rollout)
+	// 没有流量时无法判断金丝雀版本是否正常, 保持当前权重继续等待
+	if reason == "" && metrics.RequestRate == 0 {
+		retryRollout(rollout, fmt.Sprintf("no traffic to %s at %d%%, waiting", rollout.CanaryVersion, rollout.CanaryWeight))
+		return
+	}
+
+	istioClient, err := getIstioClient(kubeConfig)
+	if err != nil {
+		retryRollout(rollout, err.Error())
+		return
+	}
+
+	steps := ParseSteps(rollout.Steps)
+	fields := map[string]interface{}{}
+	switch {
+	case reason != "":
+		if err = applyWeights(istioClient, rollout, rollout.PreviousWeight); err != nil {
+			retryRollout(rollout, fmt.Sprintf("%s, roll back err: %s", reason, err))
+			return
+		}
+		fields["status"] = model.RolloutStatusRolledBack
+		fields["canary_weight"] = rollout.PreviousWeight
+		fields["message"] = fmt.Sprintf("%s, rolled back to %d%%", reason, rollout.PreviousWeight)
+	case rollout.CurrentStep >= len(steps)-1:
+		fields["status"] = model.RolloutStatusSucceeded
+		fields["message"] = fmt.Sprintf("all traffic shifted to %s", rollout.CanaryVersion)
+	default:
+		next := steps[rollout.CurrentStep+1]
+		if err = applyWeights(istioClient, rollout, next); err != nil {
+			retryRollout(rollout, fmt.Sprintf("shift traffic to %d%% err: %s", next, err))
+			return
+		}
+		fields["current_step"] = rollout.CurrentStep + 1
+		fields["previous_weight"] = rollout.CanaryWeight
+		fields["canary_weight"] = next
+		fields["next_check_time"] = time.Now().Unix() + rollout.StepInterval
+		fields["message"] = fmt.Sprintf("error rate %.2f%%, p99 latency %.0fms, shifted %d%% traffic to %s",
+			metrics.ErrorRate*100, metrics.P99Latency, next, rollout.CanaryVersion)
+	}
+	if _, err = updateRollout(rollout, fields); err != nil {
+		domainLog.Errorf("update canary rollout %d err: %s", rollout.Id, err)
+	}
+}
+
+// retryRollout 保持当前步骤, 稍后重新检查
+func retryRollout(rollout *model.CanaryRollout, message string) {
+	domainLog.Warnf("canary rollout %d: %s", rollout.Id, message)
+	_, err := updateRollout(rollout, map[string]interface{}{
+		"message":         message,
+		"next_check_time": time.Now().Add(metricsRetryInterval).Unix(),
+	})
+	if err != nil {
+		domainLog.Errorf("update canary rollout %d err: %s", rollout.Id, err)
+	}
+}
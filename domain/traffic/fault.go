@@ -0,0 +1,266 @@
+package traffic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+const (
+	faultCheckPeriod = 10 * time.Second
+	maxFaultTTL      = 24 * time.Hour
+)
+
+var ErrExperimentActive = errors.New("service already has a running fault experiment")
+
+// ExperimentStatusError 实验当前的状态不允许该操作
+type ExperimentStatusError struct {
+	Status string
+	Action string
+}
+
+func (e *ExperimentStatusError) Error() string {
+	return fmt.Sprintf("can not %s a %s experiment", e.Action, e.Status)
+}
+
+// FaultConfig Match 为空时作用于服务的所有请求, Delay 和 AbortStatus 至少设置一个, 到期后自动删除故障
+type FaultConfig struct {
+	Namespace   string
+	Service     string
+	Match       istio.RouteMatch
+	Percentage  float64
+	Delay       time.Duration
+	AbortStatus int
+	TTL         time.Duration
+}
+
+func (c *FaultConfig) Validate() error {
+	if c.Namespace == "" || c.Service == "" {
+		return errors.New("namespace and service are required")
+	}
+	if c.Percentage <= 0 || c.Percentage > 100 {
+		return errors.New("percentage must be in (0, 100]")
+	}
+	if c.Delay == 0 && c.AbortStatus == 0 {
+		return errors.New("at least one of delay and abortStatus is required")
+	}
+	if c.Delay < 0 {
+		return errors.New("delay must not be negative")
+	}
+	if c.AbortStatus != 0 && (c.AbortStatus < 200 || c.AbortStatus > 599) {
+		return errors.New("abortStatus must be a http status code")
+	}
+	if c.TTL <= 0 || c.TTL > maxFaultTTL {
+		return fmt.Errorf("ttl must be in (0, %s]", maxFaultTTL)
+	}
+	return c.Match.Validate()
+}
+
+// experimentLocks 同一个实验的停止和到期删除串行执行
+var experimentLocks = newIdLocks()
+
+// experimentStartLocks 同一个服务同时只能有一个运行中的实验, 检查和创建记录之间不能插入其他实验
+var experimentStartLocks = newServiceLocks()
+
+// faultRouteName 注入故障的http路由名
+func faultRouteName(id int64) string {
+	return fmt.Sprintf("fault-%d", id)
+}
+
+func httpFault(experiment *model.FaultExperiment) *networking.HTTPFaultInjection {
+	fault := &networking.HTTPFaultInjection{}
+	if experiment.Delay > 0 {
+		fault.Delay = &networking.HTTPFaultInjection_Delay{
+			Percentage:    &networking.Percent{Value: experiment.Percentage},
+			HttpDelayType: &networking.HTTPFaultInjection_Delay_FixedDelay{FixedDelay: durationpb.New(time.Duration(experiment.Delay) * time.Millisecond)},
+		}
+	}
+	if experiment.AbortStatus > 0 {
+		fault.Abort = &networking.HTTPFaultInjection_Abort{
+			Percentage: &networking.Percent{Value: experiment.Percentage},
+			ErrorType:  &networking.HTTPFaultInjection_Abort_HttpStatus{HttpStatus: int32(experiment.AbortStatus)},
+		}
+	}
+	return fault
+}
+
+// StartFaultExperiment 在服务的 VirtualService 中注入故障并记录实验, 到期后由 RunFaultController 删除
+func StartFaultExperiment(kubeConfig *model.KubeConfig, config FaultConfig) (*model.FaultExperiment, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	unlockService := experimentStartLocks.lock(kubeConfig.Id, config.Namespace, config.Service)
+	defer unlockService()
+	count, err := model.FaultExperimentDB.CountRunningFaultExperiment(kubeConfig.Id, config.Namespace, config.Service)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrExperimentActive
+	}
+	match, err := json.Marshal(config.Match)
+	if err != nil {
+		return nil, err
+	}
+
+	istioClient, err := getIstioClient(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	vs, err := istioClient.FindServiceVirtualService(config.Namespace, config.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	experiment := &model.FaultExperiment{
+		KubeConfigId:   kubeConfig.Id,
+		Cid:            kubeConfig.Cid,
+		Namespace:      config.Namespace,
+		Service:        config.Service,
+		VirtualService: config.Service,
+		RouteMatch:     string(match),
+		Percentage:     config.Percentage,
+		Delay:          config.Delay.Milliseconds(),
+		AbortStatus:    config.AbortStatus,
+		Ttl:            int64(config.TTL / time.Second),
+		Status:         model.ExperimentStatusRunning,
+		ExpireTime:     now.Add(config.TTL).Unix(),
+		CreateTime:     now.Unix(),
+		UpdateTime:     now.Unix(),
+	}
+	if vs != nil {
+		experiment.VirtualService = vs.Name
+	}
+	experiment.Message = fmt.Sprintf("fault injected into virtualservice %s until %s",
+		experiment.VirtualService, time.Unix(experiment.ExpireTime, 0).Format(time.RFC3339))
+	// 先记录再注入, 保证注入的故障都能被到期删除
+	if err = model.FaultExperimentDB.CreateFaultExperiment(experiment); err != nil {
+		return nil, err
+	}
+	unlock := experimentLocks.lock(experiment.Id)
+	defer unlock()
+	created, err := istioClient.InjectFault(experiment.Namespace, experiment.VirtualService, experiment.Service,
+		faultRouteName(experiment.Id), &config.Match, httpFault(experiment))
+	if err != nil {
+		_, _ = model.FaultExperimentDB.UpdateFaultExperiment(experiment.Id, model.ExperimentStatusRunning, map[string]interface{}{
+			"status":      model.ExperimentStatusFailed,
+			"message":     fmt.Sprintf("inject fault failed: %s", err),
+			"update_time": time.Now().Unix(),
+		})
+		return nil, err
+	}
+	if created {
+		// 只有确实由实验新建的 VirtualService 才在结束时删除
+		experiment.CreatedVirtualService = true
+		_, err = model.FaultExperimentDB.UpdateFaultExperiment(experiment.Id, model.ExperimentStatusRunning, map[string]interface{}{
+			"created_virtual_service": true,
+			"update_time":             time.Now().Unix(),
+		})
+		if err != nil {
+			domainLog.Warnf("fault experiment %d: record created virtualservice err: %s", experiment.Id, err)
+		}
+	}
+	return experiment, nil
+}
+
+// StopFaultExperiment 提前结束实验并删除故障
+func StopFaultExperiment(id int64) (*model.FaultExperiment, error) {
+	unlock := experimentLocks.lock(id)
+	defer unlock()
+	experiment, err := model.FaultExperimentDB.GetFaultExperimentById(id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != model.ExperimentStatusRunning {
+		return nil, &ExperimentStatusError{Status: experiment.Status, Action: "stop"}
+	}
+	if err = removeFault(experiment); err != nil {
+		return nil, err
+	}
+	return updateExperiment(experiment, map[string]interface{}{
+		"status":  model.ExperimentStatusStopped,
+		"message": "fault removed manually",
+	})
+}
+
+// RunFaultController 定时删除到期实验的故障, 删除失败时保持运行状态, 下次继续重试
+func RunFaultController() {
+	ticker := time.NewTicker(faultCheckPeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		experiments, err := model.FaultExperimentDB.ListExpiredFaultExperiment(time.Now().Unix())
+		if err != nil {
+			domainLog.Errorf("list expired fault experiments err: %s", err)
+			continue
+		}
+		for _, experiment := range *experiments {
+			expireFaultExperiment(experiment.Id)
+		}
+	}
+}
+
+func expireFaultExperiment(id int64) {
+	unlock := experimentLocks.lock(id)
+	defer unlock()
+	experiment, err := model.FaultExperimentDB.GetFaultExperimentById(id)
+	if err != nil {
+		domainLog.Errorf("get fault experiment %d err: %s", id, err)
+		return
+	}
+	// 等待锁的时候可能已经被停止或者由其他实例处理
+	if experiment.Status != model.ExperimentStatusRunning || experiment.ExpireTime > time.Now().Unix() {
+		return
+	}
+	fields := map[string]interface{}{
+		"status":  model.ExperimentStatusExpired,
+		"message": "fault removed after ttl",
+	}
+	if err = removeFault(experiment); err != nil {
+		domainLog.Warnf("fault experiment %d: remove fault err: %s", id, err)
+		fields = map[string]interface{}{"message": fmt.Sprintf("remove fault err: %s, retrying", err)}
+	}
+	if _, err = updateExperiment(experiment, fields); err != nil {
+		domainLog.Errorf("update fault experiment %d err: %s", id, err)
+	}
+}
+
+func removeFault(experiment *model.FaultExperiment) error {
+	kubeConfig, err := model.KubeConfigDB.GetKubeConfigById(experiment.KubeConfigId)
+	if err != nil {
+		return err
+	}
+	istioClient, err := getIstioClient(kubeConfig)
+	if err != nil {
+		return err
+	}
+	return istioClient.RemoveFault(experiment.Namespace, experiment.VirtualService,
+		faultRouteName(experiment.Id), experiment.CreatedVirtualService)
+}
+
+// updateExperiment 只有状态没有被其他实例修改时才会更新
+func updateExperiment(experiment *model.FaultExperiment, fields map[string]interface{}) (*model.FaultExperiment, error) {
+	fields["update_time"] = time.Now().Unix()
+	updated, err := model.FaultExperimentDB.UpdateFaultExperiment(experiment.Id, experiment.Status, fields)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, fmt.Errorf("experiment %d was modified concurrently", experiment.Id)
+	}
+	return model.FaultExperimentDB.GetFaultExperimentById(experiment.Id)
+}
+
+// ParseRouteMatch 解析记录中的匹配条件
+func ParseRouteMatch(match string) istio.RouteMatch {
+	routeMatch := istio.RouteMatch{}
+	_ = json.Unmarshal([]byte(match), &routeMatch)
+	return routeMatch
+}
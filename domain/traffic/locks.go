@@ -0,0 +1,53 @@
+package traffic
+
+import (
+	"fmt"
+	"sync"
+)
+
+// idLocks 按记录id加锁
+type idLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*sync.Mutex
+}
+
+func newIdLocks() *idLocks {
+	return &idLocks{locks: make(map[int64]*sync.Mutex)}
+}
+
+// lock 返回解锁的函数
+func (l *idLocks) lock(id int64) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		l.locks[id] = lock
+	}
+	l.mu.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+// serviceLocks 按集群和服务加锁, 检查服务没有进行中的任务和创建任务需要在同一个锁内完成
+type serviceLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newServiceLocks() *serviceLocks {
+	return &serviceLocks{locks: make(map[string]*sync.Mutex)}
+}
+
+// lock 返回解锁的函数
+func (l *serviceLocks) lock(kubeConfigId int64, namespace, service string) func() {
+	key := fmt.Sprintf("%d/%s/%s", kubeConfigId, namespace, service)
+	l.mu.Lock()
+	lock, ok := l.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		l.locks[key] = lock
+	}
+	l.mu.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
@@ -0,0 +1,100 @@
+package traffic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/shuxnhs/istio-dashboard/domain/kiali"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	"github.com/kiali/kiali/models"
+)
+
+const (
+	metricRequestCount    = "request_count"
+	metricRequestErrors   = "request_error_count"
+	metricRequestDuration = "request_duration_millis"
+	quantileP99           = "0.99"
+)
+
+var errMetricsUnavailable = errors.New("metrics unavailable from kiali")
+
+// TrafficMetrics 一个时间窗口内的入流量指标, RequestRate 为每秒请求数
+type TrafficMetrics struct {
+	RequestRate float64 `json:"requestRate"`
+	ErrorRate   float64 `json:"errorRate"`
+	P99Latency  float64 `json:"p99Latency"`
+}
+
+// getCanaryMetrics 通过kiali查询金丝雀版本作为目标端上报的入流量指标, 时间范围为一个观察周期
+func getCanaryMetrics(kialiClient *kiali.Client, rollout *model.CanaryRollout) (*TrafficMetrics, error) {
+	metrics, err := getServiceMetrics(kialiClient, rollout.Namespace, rollout.Service, rollout.CanaryVersion, rollout.StepInterval, nil)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeMetrics(metrics, nil), nil
+}
+
+// getServiceMetrics 查询服务作为目标端上报的入流量指标, version 为空时不过滤版本, interval 单位为秒
+func getServiceMetrics(kialiClient *kiali.Client, namespace, service, version string, interval int64, byLabels []string) (models.MetricsMap, error) {
+	intervalStr := strconv.FormatInt(interval, 10)
+	metrics := kialiClient.GetServicesMetrics(namespace, service, "false", "inbound", intervalStr,
+		"rate", intervalStr+"s", "", "destination", intervalStr, version, byLabels,
+		[]string{metricRequestCount, metricRequestErrors, metricRequestDuration}, []string{quantileP99})
+	if len(metrics) == 0 {
+		return nil, errMetricsUnavailable
+	}
+	return metrics, nil
+}
+
+// summarizeMetrics keep 不为空时只合并标签满足条件的序列
+func summarizeMetrics(metrics models.MetricsMap, keep func(labels map[string]string) bool) *TrafficMetrics {
+	requests := lastValue(metrics[metricRequestCount], "", sum, keep)
+	return &TrafficMetrics{
+		RequestRate: requests,
+		ErrorRate:   ratio(lastValue(metrics[metricRequestErrors], "", sum, keep), requests),
+		P99Latency:  lastValue(metrics[metricRequestDuration], quantileP99, max, keep),
+	}
+}
+
+// breach 超过阈值时返回原因, 阈值为0时不检查
+func (m *TrafficMetrics) breach(rollout *model.CanaryRollout) string {
+	if rollout.MaxErrorRate > 0 && m.ErrorRate > rollout.MaxErrorRate {
+		return fmt.Sprintf("error rate %.2f%% exceeds %.2f%%", m.ErrorRate*100, rollout.MaxErrorRate*100)
+	}
+	if rollout.MaxP99Latency > 0 && m.P99Latency > rollout.MaxP99Latency {
+		return fmt.Sprintf("p99 latency %.0fms exceeds %.0fms", m.P99Latency, rollout.MaxP99Latency)
+	}
+	return ""
+}
+
+func sum(a, b float64) float64 {
+	return a + b
+}
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// lastValue 合并各个序列的最后一个数据点, 按响应码等标签拆分的序列需要相加
+func lastValue(series []models.Metric, stat string, merge func(a, b float64) float64, keep func(labels map[string]string) bool) float64 {
+	value := 0.0
+	for _, metric := range series {
+		if metric.Stat != stat || len(metric.Datapoints) == 0 || (keep != nil && !keep(metric.Labels)) {
+			continue
+		}
+		value = merge(value, metric.Datapoints[len(metric.Datapoints)-1].Value)
+	}
+	return value
+}
+
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
@@ -0,0 +1,146 @@
+package traffic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	"github.com/shuxnhs/istio-dashboard/domain/kiali"
+	"github.com/shuxnhs/istio-dashboard/model"
+)
+
+const (
+	labelDestinationVersion = "destination_version"
+	MaxMirrorInterval       = 6 * 3600
+)
+
+var ErrNoMirror = errors.New("service has no mirrored http route")
+
+// MirrorComparison 同一个时间窗口内主流量和镜像流量的指标, 都由接收请求的边车上报, 镜像请求的响应虽然被丢弃但仍会被统计;
+// SuccessRateDelta 为镜像流量减去主流量的成功率, 为负数时说明镜像版本的错误更多, 任意一边没有流量时为0
+type MirrorComparison struct {
+	Namespace           string             `json:"namespace"`
+	Service             string             `json:"service"`
+	Route               string             `json:"route"`
+	Mirror              istio.MirrorTarget `json:"mirror"`
+	Interval            int64              `json:"interval"`
+	Primary             *TrafficMetrics    `json:"primary"`
+	Mirrored            *TrafficMetrics    `json:"mirrored"`
+	PrimarySuccessRate  float64            `json:"primarySuccessRate"`
+	MirroredSuccessRate float64            `json:"mirroredSuccessRate"`
+	SuccessRateDelta    float64            `json:"successRateDelta"`
+}
+
+// CompareMirror 通过kiali对比服务的主流量和镜像流量, route 为空时使用第一条有镜像的路由, interval 单位为秒;
+// 镜像到服务自身的subset时按 destination_version 区分两部分流量, 所以subset需要有 version 标签; 镜像到其他服务时该服务的全部入流量都算作镜像流量
+func CompareMirror(kubeConfig *model.KubeConfig, namespace, service, route string, interval int64) (*MirrorComparison, error) {
+	if interval <= 0 || interval > MaxMirrorInterval {
+		return nil, fmt.Errorf("interval must be in (0, %d]", MaxMirrorInterval)
+	}
+	istioClient, err := getIstioClient(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := istioClient.GetMirror(namespace, service)
+	if err != nil {
+		return nil, err
+	}
+	var mirrorRoute *istio.MirrorRoute
+	for index := range policy.Routes {
+		if policy.Routes[index].Mirror != nil && (route == "" || policy.Routes[index].Name == route) {
+			mirrorRoute = &policy.Routes[index]
+			break
+		}
+	}
+	if mirrorRoute == nil {
+		return nil, ErrNoMirror
+	}
+	mirror := mirrorRoute.Mirror
+
+	mirrorNamespace, mirrorService, ok := meshService(namespace, mirror.Host)
+	if !ok {
+		return nil, fmt.Errorf("mirror host %s is not a kubernetes service name or name.namespace.svc.cluster.local, kiali has no metrics for it", mirror.Host)
+	}
+	version := ""
+	if mirror.Subset != "" {
+		labels, err := istioClient.SubsetLabels(mirrorNamespace, mirrorService, mirror.Subset)
+		if err != nil {
+			return nil, err
+		}
+		if labels == nil {
+			return nil, fmt.Errorf("subset %s of %s is not defined in any destinationrule", mirror.Subset, mirror.Host)
+		}
+		version = labels["version"]
+	}
+	sameService := mirrorNamespace == namespace && mirrorService == service
+	if sameService && version == "" {
+		return nil, fmt.Errorf("subset %s of %s has no version label, mirrored traffic can not be told apart", mirror.Subset, mirror.Host)
+	}
+
+	kialiClient := kiali.NewKialiClient(kubeConfig)
+	if kialiClient == nil {
+		return nil, errors.New("connect to kiali failed")
+	}
+	comparison := &MirrorComparison{
+		Namespace: namespace,
+		Service:   service,
+		Route:     mirrorRoute.Name,
+		Mirror:    *mirror,
+		Interval:  interval,
+	}
+	if sameService {
+		metrics, err := getServiceMetrics(kialiClient, namespace, service, "", interval, []string{labelDestinationVersion})
+		if err != nil {
+			return nil, err
+		}
+		comparison.Primary = summarizeMetrics(metrics, func(labels map[string]string) bool {
+			return labels[labelDestinationVersion] != version
+		})
+		comparison.Mirrored = summarizeMetrics(metrics, func(labels map[string]string) bool {
+			return labels[labelDestinationVersion] == version
+		})
+	} else {
+		primary, err := getServiceMetrics(kialiClient, namespace, service, "", interval, nil)
+		if err != nil {
+			return nil, err
+		}
+		mirrored, err := getServiceMetrics(kialiClient, mirrorNamespace, mirrorService, version, interval, nil)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Primary = summarizeMetrics(primary, nil)
+		comparison.Mirrored = summarizeMetrics(mirrored, nil)
+	}
+	comparison.PrimarySuccessRate = successRate(comparison.Primary)
+	comparison.MirroredSuccessRate = successRate(comparison.Mirrored)
+	if comparison.Primary.RequestRate > 0 && comparison.Mirrored.RequestRate > 0 {
+		comparison.SuccessRateDelta = comparison.MirroredSuccessRate - comparison.PrimarySuccessRate
+	}
+	return comparison, nil
+}
+
+// meshService 按istio补全短域名的规则解析host, 只有 name 和 name.namespace.svc.cluster.local 指向k8s服务;
+// istio不会补全 name.namespace, 这类域名和其他带"."的域名一样需要由 ServiceEntry 定义
+func meshService(namespace, host string) (string, string, bool) {
+	if !strings.Contains(host, ".") {
+		return namespace, host, !strings.HasPrefix(host, "*")
+	}
+	name := strings.TrimSuffix(host, ".svc.cluster.local")
+	if name == host {
+		return "", "", false
+	}
+	pieces := strings.Split(name, ".")
+	if len(pieces) != 2 {
+		return "", "", false
+	}
+	return pieces[1], pieces[0], true
+}
+
+// successRate 没有流量时为0
+func successRate(metrics *TrafficMetrics) float64 {
+	if metrics.RequestRate == 0 {
+		return 0
+	}
+	return 1 - metrics.ErrorRate
+}
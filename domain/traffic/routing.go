@@ -0,0 +1,183 @@
+package traffic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shuxnhs/istio-dashboard/domain/istio"
+	"github.com/shuxnhs/istio-dashboard/model"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const cacheSyncTimeout = 30 * time.Second
+
+// getIstioClient 使用集群共享的客户端, 等待informer同步完成, 避免根据不完整的缓存修改资源
+func getIstioClient(kubeConfig *model.KubeConfig) (*istio.IstioClient, error) {
+	istioClient := istio.GetIstioClient(kubeConfig)
+	if istioClient == nil {
+		return nil, fmt.Errorf("connect to cluster %s failed", kubeConfig.Cid)
+	}
+	if err := istioClient.WaitForCacheSync(cacheSyncTimeout); err != nil {
+		return nil, err
+	}
+	return istioClient, nil
+}
+
+// routesToService 所有目标都指向该服务
+func routesToService(hosts []string, namespace, service string) bool {
+	if len(hosts) == 0 {
+		return false
+	}
+	for _, host := range hosts {
+		if !istio.IsServiceHost(namespace, host, service) {
+			return false
+		}
+	}
+	return true
+}
+
+// ensureSubsets 在服务的 DestinationRule 中补充稳定版本和金丝雀版本的subset, 没有时新建一个同名的 DestinationRule
+func ensureSubsets(cli *istio.IstioClient, rollout *model.CanaryRollout) (string, error) {
+	dr, err := cli.FindServiceDestinationRule(rollout.Namespace, rollout.Service)
+	if err != nil {
+		return "", err
+	}
+	if dr != nil {
+		dr = dr.DeepCopy()
+	} else {
+		dr = &v1alpha3.DestinationRule{
+			ObjectMeta: metav1.ObjectMeta{Name: rollout.Service, Namespace: rollout.Namespace},
+		}
+		dr.Spec.Host = rollout.Service
+	}
+
+	for _, version := range []string{rollout.StableVersion, rollout.CanaryVersion} {
+		exist := false
+		for _, subset := range dr.Spec.GetSubsets() {
+			if subset.GetName() == version {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			dr.Spec.Subsets = append(dr.Spec.Subsets, &networking.Subset{
+				Name:   version,
+				Labels: map[string]string{rollout.VersionLabel: version},
+			})
+		}
+	}
+	if err = istio.NewDestinationRule(cli).DoCreateOrUpdate(dr); err != nil {
+		return "", err
+	}
+	return dr.Name, nil
+}
+
+// findVirtualService 使用作用于mesh且包含该服务域名的 VirtualService, 没有时使用与服务同名的 VirtualService
+func findVirtualService(cli *istio.IstioClient, rollout *model.CanaryRollout) (string, error) {
+	vs, err := cli.FindServiceVirtualService(rollout.Namespace, rollout.Service)
+	if err != nil || vs == nil {
+		return rollout.Service, err
+	}
+	return vs.Name, nil
+}
+
+// applyWeights 把指向该服务的默认路由和发布修改过的路由改为稳定版本和金丝雀版本按权重分流, 其余路由保持不变
+func applyWeights(cli *istio.IstioClient, rollout *model.CanaryRollout, canaryWeight int) error {
+	virtualService := istio.NewVirtualService(cli)
+	vs, err := virtualService.Get(rollout.Namespace, rollout.VirtualService)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		vs = &v1alpha3.VirtualService{
+			ObjectMeta: metav1.ObjectMeta{Name: rollout.VirtualService, Namespace: rollout.Namespace},
+		}
+		vs.Spec.Hosts = []string{rollout.Service}
+		vs.Spec.Http = []*networking.HTTPRoute{{}}
+	} else {
+		vs = vs.DeepCopy()
+	}
+
+	if setRouteWeights(vs, rollout, canaryWeight) == 0 {
+		return fmt.Errorf("virtualservice %s/%s has no route to service %s", vs.Namespace, vs.Name, rollout.Service)
+	}
+	return virtualService.DoCreateOrUpdate(vs)
+}
+
+// setRouteWeights 返回修改的路由数量, 没有目标的http路由视为新建的默认路由;
+// 只修改没有匹配条件的默认路由和之前的发布修改过的路由, 按header等条件指定版本的路由和故障注入的路由保持不变
+func setRouteWeights(vs *v1alpha3.VirtualService, rollout *model.CanaryRollout, canaryWeight int) int {
+	changed := 0
+	for _, http := range vs.Spec.GetHttp() {
+		if http.GetRedirect() != nil || http.GetDelegate() != nil || http.GetFault() != nil {
+			continue
+		}
+		destinations := make([]*networking.Destination, 0, len(http.GetRoute()))
+		for _, route := range http.GetRoute() {
+			destinations = append(destinations, route.GetDestination())
+		}
+		if len(destinations) > 0 && !ownsRoute(len(http.GetMatch()) == 0, destinations, rollout) {
+			continue
+		}
+		var port *networking.PortSelector
+		if len(destinations) > 0 {
+			port = destinations[0].GetPort()
+		}
+		http.Route = []*networking.HTTPRouteDestination{
+			{Destination: versionDestination(rollout, rollout.StableVersion, port), Weight: int32(100 - canaryWeight)},
+			{Destination: versionDestination(rollout, rollout.CanaryVersion, port), Weight: int32(canaryWeight)},
+		}
+		changed++
+	}
+	for _, tcp := range vs.Spec.GetTcp() {
+		destinations := make([]*networking.Destination, 0, len(tcp.GetRoute()))
+		for _, route := range tcp.GetRoute() {
+			destinations = append(destinations, route.GetDestination())
+		}
+		if !ownsRoute(len(tcp.GetMatch()) == 0, destinations, rollout) {
+			continue
+		}
+		port := destinations[0].GetPort()
+		tcp.Route = []*networking.RouteDestination{
+			{Destination: versionDestination(rollout, rollout.StableVersion, port), Weight: int32(100 - canaryWeight)},
+			{Destination: versionDestination(rollout, rollout.CanaryVersion, port), Weight: int32(canaryWeight)},
+		}
+		changed++
+	}
+	return changed
+}
+
+// ownsRoute 路由的所有目标都指向该服务, 并且是没有匹配条件的默认路由, 或者目标只有稳定版本和金丝雀版本(由发布修改过)
+func ownsRoute(catchAll bool, destinations []*networking.Destination, rollout *model.CanaryRollout) bool {
+	hosts := make([]string, 0, len(destinations))
+	for _, destination := range destinations {
+		hosts = append(hosts, destination.GetHost())
+	}
+	if !routesToService(hosts, rollout.Namespace, rollout.Service) {
+		return false
+	}
+	if catchAll {
+		return true
+	}
+	for _, destination := range destinations {
+		if destination.GetSubset() != rollout.StableVersion && destination.GetSubset() != rollout.CanaryVersion {
+			return false
+		}
+	}
+	return len(destinations) == 2 && destinations[0].GetSubset() != destinations[1].GetSubset()
+}
+
+func versionDestination(rollout *model.CanaryRollout, version string, port *networking.PortSelector) *networking.Destination {
+	destination := &networking.Destination{
+		Host:   rollout.Service,
+		Subset: version,
+	}
+	if port != nil {
+		destination.Port = &networking.PortSelector{Number: port.GetNumber()}
+	}
+	return destination
+}
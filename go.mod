@@ -10,12 +10,17 @@ require (
 	github.com/gin-gonic/gin v1.7.7
 	github.com/golang/protobuf v1.5.2
 	github.com/kiali/kiali v1.49.0
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/prometheus/client_model v0.2.0
+	github.com/prometheus/common v0.33.0
 	github.com/spf13/viper v1.11.0
 	github.com/swaggo/files v0.0.0-20190704085106-630677cd5c14
 	github.com/swaggo/gin-swagger v1.2.0
 	github.com/swaggo/swag v1.6.7
+	google.golang.org/protobuf v1.28.0
 	gorm.io/driver/mysql v1.3.3
 	gorm.io/gorm v1.23.4
+	istio.io/api v0.0.0-20220415145822-bfb8bb7bb3e2
 	istio.io/client-go v1.13.2
 	istio.io/istio v0.0.0-20220415183222-f611f67505bb
 	istio.io/pkg v0.0.0-20220413132305-0219672e2d79
@@ -23,5 +28,6 @@ require (
 	k8s.io/apiextensions-apiserver v0.23.5
 	k8s.io/apimachinery v0.23.5
 	k8s.io/client-go v0.23.5
-	sigs.k8s.io/gateway-api v0.4.2
+	sigs.k8s.io/gateway-api v0.4.1-0.20220411164207-d6bbc338d351
+	sigs.k8s.io/yaml v1.3.0
 )
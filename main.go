@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"github.com/shuxnhs/istio-dashboard/config"
+	"github.com/shuxnhs/istio-dashboard/domain/sidecar"
+	"github.com/shuxnhs/istio-dashboard/domain/traffic"
 	"github.com/shuxnhs/istio-dashboard/log"
 	"github.com/shuxnhs/istio-dashboard/model"
 	"github.com/shuxnhs/istio-dashboard/server"
@@ -28,6 +30,11 @@ func main() {
 	log.InitializeLog()
 	model.InitializeDatebase()
 
+	// 金丝雀发布、故障注入实验和边车日志级别恢复的后台检查
+	go traffic.RunCanaryController()
+	go traffic.RunFaultController()
+	go sidecar.RunLogRevertController()
+
 	// 装载路由
 	r := server.NewRouter()
 	r.Run(":" + fmt.Sprint(config.Config.ListenPort))
@@ -16,13 +16,28 @@ import (
 var DB *gorm.DB
 
 var (
-	KubeConfigDB *KubeConfig
+	KubeConfigDB      *KubeConfig
+	ConfigSnapshotDB  *ConfigSnapshot
+	OfflineDumpDB     *OfflineDump
+	CanaryRolloutDB   *CanaryRollout
+	FaultExperimentDB *FaultExperiment
+	LogLevelRevertDB  *LogLevelRevert
 )
 
 // list add table name
 const (
 	// kubeConfig
 	KubeConfigTableName = "kube_config"
+	// configSnapshot
+	ConfigSnapshotTableName = "config_snapshot"
+	// offlineDump
+	OfflineDumpTableName = "offline_dump"
+	// canaryRollout
+	CanaryRolloutTableName = "canary_rollout"
+	// faultExperiment
+	FaultExperimentTableName = "fault_experiment"
+	// logLevelRevert
+	LogLevelRevertTableName = "log_level_revert"
 )
 
 // soft-delete
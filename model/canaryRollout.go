@@ -0,0 +1,140 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// canary rollout status
+const (
+	RolloutStatusRunning    = "running"
+	RolloutStatusPaused     = "paused"
+	RolloutStatusSucceeded  = "succeeded"
+	RolloutStatusRolledBack = "rolledback"
+	RolloutStatusAborted    = "aborted"
+)
+
+// CanaryRollout 金丝雀发布, Steps 为逗号分隔的金丝雀版本权重, CurrentStep 为当前所在步骤的下标
+type CanaryRollout struct {
+	Id              int64   `gorm:"primary_key;column:id"`
+	KubeConfigId    int64   `gorm:"column:kube_config_id"`
+	Cid             string  `gorm:"column:cid"`
+	Namespace       string  `gorm:"column:namespace"`
+	Service         string  `gorm:"column:service"`
+	VersionLabel    string  `gorm:"column:version_label"`
+	StableVersion   string  `gorm:"column:stable_version"`
+	CanaryVersion   string  `gorm:"column:canary_version"`
+	VirtualService  string  `gorm:"column:virtual_service"`
+	DestinationRule string  `gorm:"column:destination_rule"`
+	Steps           string  `gorm:"column:steps"`
+	StepInterval    int64   `gorm:"column:step_interval"`
+	MaxErrorRate    float64 `gorm:"column:max_error_rate"`
+	MaxP99Latency   float64 `gorm:"column:max_p99_latency"`
+	CurrentStep     int     `gorm:"column:current_step"`
+	CanaryWeight    int     `gorm:"column:canary_weight"`
+	PreviousWeight  int     `gorm:"column:previous_weight"`
+	Status          string  `gorm:"column:status"`
+	Message         string  `gorm:"column:message"`
+	NextCheckTime   int64   `gorm:"column:next_check_time"`
+	CreateTime      int64   `gorm:"column:create_time"`
+	UpdateTime      int64   `gorm:"column:update_time"`
+}
+
+var CanaryRolloutNoExistErr = errors.New("canary-rollout no exist")
+
+func (c *CanaryRollout) TableName() string {
+	return CanaryRolloutTableName
+}
+
+// ListCanaryRollout 获取集群的发布列表, namespace 为空时不过滤, 按创建时间倒序
+func (c *CanaryRollout) ListCanaryRollout(kubeConfigId int64, namespace string) (*[]CanaryRollout, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		where := map[string]interface{}{"kube_config_id": kubeConfigId}
+		if namespace != "" {
+			where["namespace"] = namespace
+		}
+		return db.Where(where).Order("create_time desc")
+	}
+	rollouts, err := NewDataModel().GetList(NewCanaryRolloutModel(), whereScopes, []string{"*"})
+	if err != nil {
+		return nil, err
+	}
+	return rollouts.(*[]CanaryRollout), err
+}
+
+// ListDueCanaryRollout 获取运行中且到了检查时间的发布
+func (c *CanaryRollout) ListDueCanaryRollout(now int64) (*[]CanaryRollout, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? and next_check_time <= ?", RolloutStatusRunning, now)
+	}
+	rollouts, err := NewDataModel().GetList(NewCanaryRolloutModel(), whereScopes, []string{"*"})
+	if err != nil {
+		return nil, err
+	}
+	return rollouts.(*[]CanaryRollout), err
+}
+
+// CountActiveCanaryRollout 服务上运行中或暂停的发布数量, 同一个服务同时只能有一个发布
+func (c *CanaryRollout) CountActiveCanaryRollout(kubeConfigId int64, namespace, service string) (int64, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("kube_config_id = ? and namespace = ? and service = ? and status in ?",
+			kubeConfigId, namespace, service, []string{RolloutStatusRunning, RolloutStatusPaused})
+	}
+	return NewDataModel().Count(NewCanaryRolloutModel(), whereScopes)
+}
+
+// GetCanaryRolloutById 根据id获取发布
+func (c *CanaryRollout) GetCanaryRolloutById(id int64) (*CanaryRollout, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where(map[string]interface{}{"id": id})
+	}
+	data, err := NewDataModel().GetData(NewCanaryRolloutModel(), whereScopes, []string{"*"})
+	if err == nil {
+		rollout, ok := data.(*CanaryRollout)
+		if !ok || rollout.Id == 0 {
+			return nil, CanaryRolloutNoExistErr
+		} else {
+			return rollout, nil
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// CreateCanaryRollout 新增发布
+func (c *CanaryRollout) CreateCanaryRollout(rollout *CanaryRollout) error {
+	_, err := NewDataModel().Insert(rollout)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateCanaryRollout 更新发布状态, 只有当前状态为 status 时才会更新, 返回是否更新成功
+func (c *CanaryRollout) UpdateCanaryRollout(id int64, status string, rollout map[string]interface{}) (bool, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ? and status = ?", id, status)
+	}
+	rowsAffected, err := NewDataModel().UpdateAll(NewCanaryRolloutModel(), whereScopes, rollout)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// CanaryRolloutModel @业务模型
+type CanaryRolloutModel struct {
+	CanaryRollout
+}
+
+func NewCanaryRolloutModel() *CanaryRolloutModel {
+	return &CanaryRolloutModel{CanaryRollout{}}
+}
+
+func (c *CanaryRolloutModel) GetTableStruct(isSlice bool) interface{} {
+	if isSlice {
+		return &[]CanaryRollout{}
+	}
+	return &CanaryRollout{}
+}
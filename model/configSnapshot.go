@@ -0,0 +1,91 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ConfigSnapshot 边车config_dump的快照, ConfigDump 为gzip压缩后的内容
+type ConfigSnapshot struct {
+	Id           int64  `gorm:"primary_key;column:id"`
+	KubeConfigId int64  `gorm:"column:kube_config_id"`
+	Cid          string `gorm:"column:cid"`
+	Namespace    string `gorm:"column:namespace"`
+	Pod          string `gorm:"column:pod"`
+	Description  string `gorm:"column:description"`
+	ConfigDump   []byte `gorm:"column:config_dump"`
+	Size         int64  `gorm:"column:size"`
+	CreateTime   int64  `gorm:"column:create_time"`
+}
+
+var ConfigSnapshotNoExistErr = errors.New("config-snapshot no exist")
+
+// 列表不查询config_dump, 避免读取大字段
+var configSnapshotListField = []string{"id", "kube_config_id", "cid", "namespace", "pod", "description", "size", "create_time"}
+
+func (c *ConfigSnapshot) TableName() string {
+	return ConfigSnapshotTableName
+}
+
+// ListConfigSnapshot 获取快照列表, namespace 和 pod 为空时不过滤, 按创建时间倒序
+func (c *ConfigSnapshot) ListConfigSnapshot(kubeConfigId int64, namespace, pod string) (*[]ConfigSnapshot, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		where := map[string]interface{}{"kube_config_id": kubeConfigId}
+		if namespace != "" {
+			where["namespace"] = namespace
+		}
+		if pod != "" {
+			where["pod"] = pod
+		}
+		return db.Where(where).Order("create_time desc")
+	}
+	snapshots, err := NewDataModel().GetList(NewConfigSnapshotModel(), whereScopes, configSnapshotListField)
+	if err != nil {
+		return nil, err
+	}
+	return snapshots.(*[]ConfigSnapshot), err
+}
+
+// GetConfigSnapshotById 根据id获取快照, 包含config_dump
+func (c *ConfigSnapshot) GetConfigSnapshotById(id int64) (*ConfigSnapshot, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where(map[string]interface{}{"id": id})
+	}
+	data, err := NewDataModel().GetData(NewConfigSnapshotModel(), whereScopes, []string{"*"})
+	if err == nil {
+		snapshot, ok := data.(*ConfigSnapshot)
+		if !ok || snapshot.Id == 0 {
+			return nil, ConfigSnapshotNoExistErr
+		} else {
+			return snapshot, nil
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// CreateConfigSnapshot 新增快照
+func (c *ConfigSnapshot) CreateConfigSnapshot(snapshot *ConfigSnapshot) error {
+	_, err := NewDataModel().Insert(snapshot)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConfigSnapshotModel @业务模型
+type ConfigSnapshotModel struct {
+	ConfigSnapshot
+}
+
+func NewConfigSnapshotModel() *ConfigSnapshotModel {
+	return &ConfigSnapshotModel{ConfigSnapshot{}}
+}
+
+func (c *ConfigSnapshotModel) GetTableStruct(isSlice bool) interface{} {
+	if isSlice {
+		return &[]ConfigSnapshot{}
+	}
+	return &ConfigSnapshot{}
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// fault experiment status
+const (
+	ExperimentStatusRunning = "running"
+	ExperimentStatusExpired = "expired"
+	ExperimentStatusStopped = "stopped"
+	ExperimentStatusFailed  = "failed"
+)
+
+// FaultExperiment 故障注入实验, RouteMatch 为json格式的匹配条件, 到期后由后台任务删除故障
+type FaultExperiment struct {
+	Id                    int64   `gorm:"primary_key;column:id"`
+	KubeConfigId          int64   `gorm:"column:kube_config_id"`
+	Cid                   string  `gorm:"column:cid"`
+	Namespace             string  `gorm:"column:namespace"`
+	Service               string  `gorm:"column:service"`
+	VirtualService        string  `gorm:"column:virtual_service"`
+	CreatedVirtualService bool    `gorm:"column:created_virtual_service"`
+	RouteMatch            string  `gorm:"column:route_match"`
+	Percentage            float64 `gorm:"column:percentage"`
+	Delay                 int64   `gorm:"column:delay"`
+	AbortStatus           int     `gorm:"column:abort_status"`
+	Ttl                   int64   `gorm:"column:ttl"`
+	Status                string  `gorm:"column:status"`
+	Message               string  `gorm:"column:message"`
+	ExpireTime            int64   `gorm:"column:expire_time"`
+	CreateTime            int64   `gorm:"column:create_time"`
+	UpdateTime            int64   `gorm:"column:update_time"`
+}
+
+var FaultExperimentNoExistErr = errors.New("fault-experiment no exist")
+
+func (f *FaultExperiment) TableName() string {
+	return FaultExperimentTableName
+}
+
+// ListFaultExperiment 获取集群的实验列表, namespace 为空时不过滤, 按创建时间倒序
+func (f *FaultExperiment) ListFaultExperiment(kubeConfigId int64, namespace string) (*[]FaultExperiment, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		where := map[string]interface{}{"kube_config_id": kubeConfigId}
+		if namespace != "" {
+			where["namespace"] = namespace
+		}
+		return db.Where(where).Order("create_time desc")
+	}
+	experiments, err := NewDataModel().GetList(NewFaultExperimentModel(), whereScopes, []string{"*"})
+	if err != nil {
+		return nil, err
+	}
+	return experiments.(*[]FaultExperiment), err
+}
+
+// ListExpiredFaultExperiment 获取运行中且已经到期的实验
+func (f *FaultExperiment) ListExpiredFaultExperiment(now int64) (*[]FaultExperiment, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? and expire_time <= ?", ExperimentStatusRunning, now)
+	}
+	experiments, err := NewDataModel().GetList(NewFaultExperimentModel(), whereScopes, []string{"*"})
+	if err != nil {
+		return nil, err
+	}
+	return experiments.(*[]FaultExperiment), err
+}
+
+// CountRunningFaultExperiment 服务上运行中的实验数量, 同一个服务同时只能有一个实验
+func (f *FaultExperiment) CountRunningFaultExperiment(kubeConfigId int64, namespace, service string) (int64, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("kube_config_id = ? and namespace = ? and service = ? and status = ?",
+			kubeConfigId, namespace, service, ExperimentStatusRunning)
+	}
+	return NewDataModel().Count(NewFaultExperimentModel(), whereScopes)
+}
+
+// GetFaultExperimentById 根据id获取实验
+func (f *FaultExperiment) GetFaultExperimentById(id int64) (*FaultExperiment, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where(map[string]interface{}{"id": id})
+	}
+	data, err := NewDataModel().GetData(NewFaultExperimentModel(), whereScopes, []string{"*"})
+	if err == nil {
+		experiment, ok := data.(*FaultExperiment)
+		if !ok || experiment.Id == 0 {
+			return nil, FaultExperimentNoExistErr
+		} else {
+			return experiment, nil
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// CreateFaultExperiment 新增实验
+func (f *FaultExperiment) CreateFaultExperiment(experiment *FaultExperiment) error {
+	_, err := NewDataModel().Insert(experiment)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateFaultExperiment 更新实验状态, 只有当前状态为 status 时才会更新, 返回是否更新成功
+func (f *FaultExperiment) UpdateFaultExperiment(id int64, status string, experiment map[string]interface{}) (bool, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ? and status = ?", id, status)
+	}
+	rowsAffected, err := NewDataModel().UpdateAll(NewFaultExperimentModel(), whereScopes, experiment)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// FaultExperimentModel @业务模型
+type FaultExperimentModel struct {
+	FaultExperiment
+}
+
+func NewFaultExperimentModel() *FaultExperimentModel {
+	return &FaultExperimentModel{FaultExperiment{}}
+}
+
+func (f *FaultExperimentModel) GetTableStruct(isSlice bool) interface{} {
+	if isSlice {
+		return &[]FaultExperiment{}
+	}
+	return &FaultExperiment{}
+}
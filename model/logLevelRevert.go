@@ -0,0 +1,104 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// LogLevelRevert 边车日志级别的自动恢复, Levels 为json格式的修改前的级别, 恢复后删除记录
+type LogLevelRevert struct {
+	Id         int64  `gorm:"primary_key;column:id"`
+	Cid        string `gorm:"column:cid"`
+	Namespace  string `gorm:"column:namespace"`
+	Pod        string `gorm:"column:pod"`
+	Levels     string `gorm:"column:levels"`
+	RevertTime int64  `gorm:"column:revert_time"`
+	CreateTime int64  `gorm:"column:create_time"`
+	UpdateTime int64  `gorm:"column:update_time"`
+}
+
+var LogLevelRevertNoExistErr = errors.New("log-level-revert no exist")
+
+func (l *LogLevelRevert) TableName() string {
+	return LogLevelRevertTableName
+}
+
+// GetLogLevelRevert 获取pod等待中的恢复
+func (l *LogLevelRevert) GetLogLevelRevert(cid, namespace, pod string) (*LogLevelRevert, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where(map[string]interface{}{"cid": cid, "namespace": namespace, "pod": pod})
+	}
+	data, err := NewDataModel().GetData(NewLogLevelRevertModel(), whereScopes, []string{"*"})
+	if err == nil {
+		revert, ok := data.(*LogLevelRevert)
+		if !ok || revert.Id == 0 {
+			return nil, LogLevelRevertNoExistErr
+		} else {
+			return revert, nil
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// ListDueLogLevelRevert 获取已经到期的恢复
+func (l *LogLevelRevert) ListDueLogLevelRevert(now int64) (*[]LogLevelRevert, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("revert_time <= ?", now)
+	}
+	reverts, err := NewDataModel().GetList(NewLogLevelRevertModel(), whereScopes, []string{"*"})
+	if err != nil {
+		return nil, err
+	}
+	return reverts.(*[]LogLevelRevert), err
+}
+
+// CreateLogLevelRevert 新增恢复
+func (l *LogLevelRevert) CreateLogLevelRevert(revert *LogLevelRevert) error {
+	_, err := NewDataModel().Insert(revert)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateLogLevelRevert 更新恢复的级别和时间
+func (l *LogLevelRevert) UpdateLogLevelRevert(id int64, revert map[string]interface{}) error {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ?", id)
+	}
+	_, err := NewDataModel().UpdateAll(NewLogLevelRevertModel(), whereScopes, revert)
+	return err
+}
+
+// DeleteLogLevelRevert 删除恢复, revertTime 不为0时只有恢复时间没有被再次修改才会删除, 返回是否删除成功
+func (l *LogLevelRevert) DeleteLogLevelRevert(id, revertTime int64) (bool, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		if revertTime != 0 {
+			return db.Where("id = ? and revert_time = ?", id, revertTime)
+		}
+		return db.Where("id = ?", id)
+	}
+	rowsAffected, err := NewDataModel().DeleteAll(NewLogLevelRevertModel(), whereScopes)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// LogLevelRevertModel @业务模型
+type LogLevelRevertModel struct {
+	LogLevelRevert
+}
+
+func NewLogLevelRevertModel() *LogLevelRevertModel {
+	return &LogLevelRevertModel{LogLevelRevert{}}
+}
+
+func (l *LogLevelRevertModel) GetTableStruct(isSlice bool) interface{} {
+	if isSlice {
+		return &[]LogLevelRevert{}
+	}
+	return &LogLevelRevert{}
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// OfflineDump 上传的config_dump, 用于不连接集群的离线分析, ConfigDump 为gzip压缩后的内容
+type OfflineDump struct {
+	Id          int64  `gorm:"primary_key;column:id"`
+	FileName    string `gorm:"column:file_name"`
+	Namespace   string `gorm:"column:namespace"`
+	Pod         string `gorm:"column:pod"`
+	Description string `gorm:"column:description"`
+	ConfigDump  []byte `gorm:"column:config_dump"`
+	Size        int64  `gorm:"column:size"`
+	CreateTime  int64  `gorm:"column:create_time"`
+}
+
+var OfflineDumpNoExistErr = errors.New("offline-dump no exist")
+
+var offlineDumpListField = []string{"id", "file_name", "namespace", "pod", "description", "size", "create_time"}
+
+func (o *OfflineDump) TableName() string {
+	return OfflineDumpTableName
+}
+
+// ListOfflineDump 获取上传的config_dump列表, 按创建时间倒序
+func (o *OfflineDump) ListOfflineDump() (*[]OfflineDump, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Order("create_time desc")
+	}
+	dumps, err := NewDataModel().GetList(NewOfflineDumpModel(), whereScopes, offlineDumpListField)
+	if err != nil {
+		return nil, err
+	}
+	return dumps.(*[]OfflineDump), err
+}
+
+// GetOfflineDumpById 根据id获取上传的config_dump
+func (o *OfflineDump) GetOfflineDumpById(id int64) (*OfflineDump, error) {
+	whereScopes := func(db *gorm.DB) *gorm.DB {
+		return db.Where(map[string]interface{}{"id": id})
+	}
+	data, err := NewDataModel().GetData(NewOfflineDumpModel(), whereScopes, []string{"*"})
+	if err == nil {
+		dump, ok := data.(*OfflineDump)
+		if !ok || dump.Id == 0 {
+			return nil, OfflineDumpNoExistErr
+		} else {
+			return dump, nil
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// CreateOfflineDumps 批量新增, bug-report中可能包含多个边车
+func (o *OfflineDump) CreateOfflineDumps(dumps []OfflineDump) error {
+	_, err := NewDataModel().InsertMore(&dumps)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// OfflineDumpModel @业务模型
+type OfflineDumpModel struct {
+	OfflineDump
+}
+
+func NewOfflineDumpModel() *OfflineDumpModel {
+	return &OfflineDumpModel{OfflineDump{}}
+}
+
+func (o *OfflineDumpModel) GetTableStruct(isSlice bool) interface{} {
+	if isSlice {
+		return &[]OfflineDump{}
+	}
+	return &OfflineDump{}
+}
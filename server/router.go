@@ -43,30 +43,214 @@ func NewRouter() *gin.Engine {
 
 	}
 
+	istio := r.Group("/istio")
+	{
+		istio.GET("version", api.ListResourceVersions)
+		istio.GET("analyze", api.Analyze)
+
+		virtualService := istio.Group("/virtualservice")
+		{
+			virtualService.GET("list", api.ListVirtualServices)
+			virtualService.GET("get", api.GetVirtualService)
+			virtualService.POST("create", api.CreateVirtualService)
+			virtualService.POST("update", api.UpdateVirtualService)
+			virtualService.POST("delete", api.DeleteVirtualService)
+		}
+
+		routingRule := istio.Group("/routingrule")
+		{
+			routingRule.POST("preview", api.PreviewRoutingRule)
+			routingRule.POST("apply", api.ApplyRoutingRule)
+		}
+
+		resilience := istio.Group("/resilience")
+		{
+			resilience.GET("get", api.GetResilience)
+			resilience.POST("set", api.SetResilience)
+			resilience.GET("sync", api.CheckResilienceSync)
+		}
+
+		gateway := istio.Group("/gateway")
+		{
+			gateway.GET("list", api.ListGateways)
+			gateway.GET("get", api.GetGateway)
+			gateway.POST("create", api.CreateGateway)
+			gateway.POST("update", api.UpdateGateway)
+			gateway.POST("delete", api.DeleteGateway)
+		}
+
+		destinationRule := istio.Group("/destinationrule")
+		{
+			destinationRule.GET("list", api.ListDestinationRules)
+			destinationRule.GET("get", api.GetDestinationRule)
+			destinationRule.POST("create", api.CreateDestinationRule)
+			destinationRule.POST("update", api.UpdateDestinationRule)
+			destinationRule.POST("delete", api.DeleteDestinationRule)
+		}
+
+		serviceEntry := istio.Group("/serviceentry")
+		{
+			serviceEntry.GET("list", api.ListServiceEntries)
+			serviceEntry.GET("get", api.GetServiceEntry)
+			serviceEntry.POST("create", api.CreateServiceEntry)
+			serviceEntry.POST("update", api.UpdateServiceEntry)
+			serviceEntry.POST("delete", api.DeleteServiceEntry)
+		}
+
+		envoyFilter := istio.Group("/envoyfilter")
+		{
+			envoyFilter.GET("list", api.ListEnvoyFilters)
+			envoyFilter.GET("get", api.GetEnvoyFilter)
+			envoyFilter.POST("create", api.CreateEnvoyFilter)
+			envoyFilter.POST("update", api.UpdateEnvoyFilter)
+			envoyFilter.POST("delete", api.DeleteEnvoyFilter)
+		}
+	}
+
+	gatewayAPI := r.Group("/gatewayapi")
+	{
+		gatewayAPI.GET("attachment", api.GetAttachmentStatus)
+
+		gatewayClass := gatewayAPI.Group("/gatewayclass")
+		{
+			gatewayClass.GET("list", api.ListGatewayClasses)
+			gatewayClass.GET("get", api.GetGatewayClass)
+			gatewayClass.POST("create", api.CreateGatewayClass)
+			gatewayClass.POST("update", api.UpdateGatewayClass)
+			gatewayClass.POST("delete", api.DeleteGatewayClass)
+		}
+
+		gateway := gatewayAPI.Group("/gateway")
+		{
+			gateway.GET("list", api.ListGatewayAPIGateways)
+			gateway.GET("get", api.GetGatewayAPIGateway)
+			gateway.POST("create", api.CreateGatewayAPIGateway)
+			gateway.POST("update", api.UpdateGatewayAPIGateway)
+			gateway.POST("delete", api.DeleteGatewayAPIGateway)
+		}
+
+		httpRoute := gatewayAPI.Group("/httproute")
+		{
+			httpRoute.GET("list", api.ListHTTPRoutes)
+			httpRoute.GET("get", api.GetHTTPRoute)
+			httpRoute.POST("create", api.CreateHTTPRoute)
+			httpRoute.POST("update", api.UpdateHTTPRoute)
+			httpRoute.POST("delete", api.DeleteHTTPRoute)
+		}
+
+		tcpRoute := gatewayAPI.Group("/tcproute")
+		{
+			tcpRoute.GET("list", api.ListTCPRoutes)
+			tcpRoute.GET("get", api.GetTCPRoute)
+			tcpRoute.POST("create", api.CreateTCPRoute)
+			tcpRoute.POST("update", api.UpdateTCPRoute)
+			tcpRoute.POST("delete", api.DeleteTCPRoute)
+		}
+
+		tlsRoute := gatewayAPI.Group("/tlsroute")
+		{
+			tlsRoute.GET("list", api.ListTLSRoutes)
+			tlsRoute.GET("get", api.GetTLSRoute)
+			tlsRoute.POST("create", api.CreateTLSRoute)
+			tlsRoute.POST("update", api.UpdateTLSRoute)
+			tlsRoute.POST("delete", api.DeleteTLSRoute)
+		}
+
+		referenceGrant := gatewayAPI.Group("/referencegrant")
+		{
+			referenceGrant.GET("list", api.ListReferenceGrants)
+			referenceGrant.GET("get", api.GetReferenceGrant)
+			referenceGrant.POST("create", api.CreateReferenceGrant)
+			referenceGrant.POST("update", api.UpdateReferenceGrant)
+			referenceGrant.POST("delete", api.DeleteReferenceGrant)
+		}
+	}
+
+	traffic := r.Group("/traffic")
+	{
+		canary := traffic.Group("/canary")
+		{
+			canary.POST("start", api.StartCanary)
+			canary.GET("list", api.ListCanaries)
+			canary.GET("get", api.GetCanary)
+			canary.POST("pause", api.PauseCanary)
+			canary.POST("resume", api.ResumeCanary)
+			canary.POST("abort", api.AbortCanary)
+		}
+
+		fault := traffic.Group("/fault")
+		{
+			fault.POST("start", api.StartFault)
+			fault.GET("list", api.ListFaults)
+			fault.GET("get", api.GetFault)
+			fault.POST("stop", api.StopFault)
+		}
+
+		mirror := traffic.Group("/mirror")
+		{
+			mirror.GET("get", api.GetMirror)
+			mirror.POST("set", api.SetMirror)
+			mirror.POST("remove", api.RemoveMirror)
+			mirror.GET("compare", api.CompareMirror)
+		}
+	}
+
 	sidecar := r.Group("/sidecar")
 	{
 		sidecar.GET("check", api.Check)
+		sidecar.GET("status", api.ListProxyStatus)
+		sidecar.GET("bootstrap", api.GetBootstrap)
+		sidecar.GET("log", api.GetLogLevel)
+		sidecar.POST("log", api.SetLogLevel)
+		sidecar.GET("stats", api.ListStats)
+		sidecar.GET("origin", api.ListConfigOrigins)
+		sidecar.GET("diff", api.DiffSidecar)
+		sidecar.POST("simulate", api.Simulate)
 
 		eds := sidecar.Group("/eds")
 		{
 			eds.GET("list", api.ListEDS)
+			eds.GET("locality", api.ListEDSLocality)
+			eds.GET("workload", api.ListEndpointWorkloads)
 		}
 
 		cds := sidecar.Group("/cds")
 		{
 			cds.GET("list", api.ListCDS)
+			cds.GET("detail", api.GetCDSDetail)
 		}
 
 		lds := sidecar.Group("/lds")
 		{
 			lds.GET("list", api.ListLDS)
+			lds.GET("detail", api.GetLDSDetail)
 		}
 
 		rds := sidecar.Group("/rds")
 		{
 			rds.GET("list", api.ListRDS)
+			rds.GET("detail", api.GetRDSDetail)
+		}
+
+		sds := sidecar.Group("/sds")
+		{
+			sds.GET("list", api.ListSDS)
+		}
+
+		snapshot := sidecar.Group("/snapshot")
+		{
+			snapshot.POST("create", api.CreateSnapshot)
+			snapshot.GET("list", api.ListSnapshots)
+			snapshot.GET("diff", api.DiffSnapshot)
 		}
 
 	}
+
+	offline := r.Group("/offline")
+	{
+		offline.POST("upload", api.UploadOfflineDump)
+		offline.GET("list", api.ListOfflineDumps)
+		offline.GET("view", api.GetOfflineViews)
+	}
 	return r
 }